@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/irawankilmer/auth-service/database/seeders"
 	"github.com/irawankilmer/auth-service/internal/configs"
+	"github.com/irawankilmer/auth-service/pkg/password"
 	"github.com/irawankilmer/auth-service/pkg/utils"
 	"github.com/joho/godotenv"
 	"log"
@@ -17,7 +18,15 @@ func main() {
 		log.Fatal("koneksi ke database gagal:", err)
 	}
 
-	u := utils.NewUtility(cfg)
+	hasher, err := password.NewHasher(cfg.Hash)
+	if err != nil {
+		log.Fatalf("konfigurasi hash password tidak valid: %v", err)
+	}
+
+	u, err := utils.NewUtility(cfg, hasher)
+	if err != nil {
+		log.Fatalf("inisialisasi utility gagal: %v", err)
+	}
 	if err := seeders.SeedsRun(db, u); err != nil {
 		log.Fatalf("seeding gagal:%v", err)
 	}
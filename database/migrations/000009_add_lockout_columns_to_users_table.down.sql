@@ -0,0 +1,5 @@
+ALTER TABLE users
+  DROP COLUMN locked_until,
+  DROP COLUMN lockout_count,
+  DROP COLUMN last_failed_login_at,
+  DROP COLUMN failed_login_attempts;
\ No newline at end of file
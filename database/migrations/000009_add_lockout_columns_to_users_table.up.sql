@@ -0,0 +1,5 @@
+ALTER TABLE users
+  ADD COLUMN failed_login_attempts INT NOT NULL DEFAULT 0 AFTER google_id,
+  ADD COLUMN last_failed_login_at DATETIME NULL AFTER failed_login_attempts,
+  ADD COLUMN lockout_count INT NOT NULL DEFAULT 0 AFTER last_failed_login_at,
+  ADD COLUMN locked_until DATETIME NULL AFTER lockout_count;
@@ -0,0 +1,11 @@
+CREATE TABLE login_attempts (
+  identifier VARCHAR(255) NOT NULL,
+  ip_address VARCHAR(45) NOT NULL,
+  failed_attempts INT NOT NULL DEFAULT 0,
+  lockout_count INT NOT NULL DEFAULT 0,
+  locked_until DATETIME NULL,
+  last_failed_at DATETIME NULL,
+
+  PRIMARY KEY (identifier, ip_address),
+  INDEX idx_locked_until (locked_until)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS user_mfa;
\ No newline at end of file
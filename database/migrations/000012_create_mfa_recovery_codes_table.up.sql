@@ -0,0 +1,14 @@
+CREATE TABLE mfa_recovery_codes (
+  id VARCHAR(26) NOT NULL PRIMARY KEY,
+  user_id VARCHAR(26) NOT NULL,
+  code_hash VARCHAR(64) NOT NULL,
+  used_at DATETIME NULL,
+  used_ip_address VARCHAR(45) NULL,
+  used_user_agent TEXT NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+  UNIQUE INDEX idx_code_hash (code_hash),
+  INDEX idx_user_id (user_id),
+
+  FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,17 @@
+CREATE TABLE webauthn_credentials (
+  id VARCHAR(26) NOT NULL PRIMARY KEY,
+  user_id VARCHAR(26) NOT NULL,
+  credential_id VARBINARY(1023) NOT NULL,
+  name VARCHAR(100) NOT NULL,
+  sign_count INT UNSIGNED NOT NULL DEFAULT 0,
+  clone_warning BOOLEAN NOT NULL DEFAULT FALSE,
+  credential_data JSON NOT NULL,
+  last_used_at DATETIME NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+  UNIQUE INDEX idx_credential_id (credential_id),
+  INDEX idx_user_id (user_id),
+
+  FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,12 @@
+CREATE TABLE webauthn_sessions (
+  id VARCHAR(26) NOT NULL PRIMARY KEY,
+  user_id VARCHAR(26) NULL,
+  ceremony ENUM('registration', 'login', 'mfa') NOT NULL,
+  session_data JSON NOT NULL,
+  expires_at DATETIME NOT NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+  INDEX idx_expires_at (expires_at),
+
+  FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
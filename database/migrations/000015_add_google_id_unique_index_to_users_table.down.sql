@@ -0,0 +1 @@
+ALTER TABLE users DROP INDEX idx_google_id;
\ No newline at end of file
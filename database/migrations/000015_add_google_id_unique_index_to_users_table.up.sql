@@ -0,0 +1 @@
+ALTER TABLE users ADD UNIQUE INDEX idx_google_id (google_id);
\ No newline at end of file
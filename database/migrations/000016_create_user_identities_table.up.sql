@@ -0,0 +1,12 @@
+CREATE TABLE user_identities (
+  user_id VARCHAR(26) NOT NULL,
+  provider VARCHAR(50) NOT NULL,
+  subject VARCHAR(255) NOT NULL,
+  email VARCHAR(255) NULL,
+  linked_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+  PRIMARY KEY (provider, subject),
+  UNIQUE INDEX idx_user_provider (user_id, provider),
+
+  FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
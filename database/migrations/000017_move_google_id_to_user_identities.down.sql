@@ -0,0 +1,3 @@
+UPDATE users u
+JOIN user_identities i ON i.user_id = u.id AND i.provider = 'google'
+SET u.google_id = i.subject;
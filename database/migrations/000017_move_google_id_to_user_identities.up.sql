@@ -0,0 +1,2 @@
+INSERT INTO user_identities(user_id, provider, subject, email, linked_at)
+SELECT id, 'google', google_id, email, updated_at FROM users WHERE google_id IS NOT NULL;
@@ -0,0 +1,3 @@
+ALTER TABLE users
+  ADD COLUMN google_id VARCHAR(255) NULL AFTER created_by_admin,
+  ADD UNIQUE INDEX idx_google_id (google_id);
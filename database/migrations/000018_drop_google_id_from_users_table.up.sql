@@ -0,0 +1,3 @@
+ALTER TABLE users
+  DROP INDEX idx_google_id,
+  DROP COLUMN google_id;
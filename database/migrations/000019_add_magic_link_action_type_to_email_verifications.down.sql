@@ -0,0 +1,4 @@
+-- token magic_link dihapus dulu agar enum bisa dikembalikan tanpa nilai yang tidak valid
+DELETE FROM email_verifications WHERE action_type = 'magic_link';
+ALTER TABLE email_verifications
+  MODIFY action_type ENUM('register', 'email_change', 'username_change', 'password_change') NOT NULL;
@@ -0,0 +1,2 @@
+ALTER TABLE email_verifications
+  MODIFY action_type ENUM('register', 'email_change', 'username_change', 'password_change', 'magic_link') NOT NULL;
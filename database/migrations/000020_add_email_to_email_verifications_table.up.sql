@@ -0,0 +1 @@
+ALTER TABLE email_verifications ADD COLUMN email VARCHAR(255) NULL AFTER token;
@@ -0,0 +1,20 @@
+CREATE TABLE user_devices (
+  id VARCHAR(26) PRIMARY KEY,
+  user_id VARCHAR(26) NOT NULL,
+  device_id VARCHAR(64) NOT NULL,
+  name VARCHAR(100) NULL,
+  browser VARCHAR(50) NULL,
+  browser_version VARCHAR(50) NULL,
+  os VARCHAR(50) NULL,
+  os_version VARCHAR(50) NULL,
+  device_type VARCHAR(20) NOT NULL,
+  device_model VARCHAR(100) NULL,
+  last_ip VARCHAR(45) NULL,
+  trusted_until DATETIME NULL,
+  last_seen_at DATETIME NOT NULL,
+  created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+
+  UNIQUE INDEX idx_user_device (user_id, device_id),
+
+  FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
\ No newline at end of file
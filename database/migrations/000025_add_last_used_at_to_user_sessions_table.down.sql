@@ -0,0 +1 @@
+ALTER TABLE user_sessions DROP COLUMN last_used_at;
\ No newline at end of file
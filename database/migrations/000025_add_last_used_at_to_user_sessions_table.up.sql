@@ -0,0 +1 @@
+ALTER TABLE user_sessions ADD COLUMN last_used_at DATETIME NULL AFTER expires_at;
\ No newline at end of file
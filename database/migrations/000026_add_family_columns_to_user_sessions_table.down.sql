@@ -0,0 +1,5 @@
+ALTER TABLE user_sessions
+  DROP INDEX idx_family_id,
+  DROP COLUMN family_id,
+  DROP COLUMN parent_id,
+  DROP COLUMN rotated_at;
\ No newline at end of file
@@ -0,0 +1,5 @@
+ALTER TABLE user_sessions
+  ADD COLUMN family_id VARCHAR(26) NULL AFTER user_id,
+  ADD COLUMN parent_id VARCHAR(26) NULL AFTER family_id,
+  ADD COLUMN rotated_at DATETIME NULL AFTER revoked,
+  ADD INDEX idx_family_id (family_id);
\ No newline at end of file
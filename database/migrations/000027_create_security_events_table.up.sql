@@ -0,0 +1,14 @@
+CREATE TABLE security_events (
+  id VARCHAR(26) PRIMARY KEY,
+  user_id VARCHAR(26) NOT NULL,
+  event_type VARCHAR(50) NOT NULL,
+  ip_address VARCHAR(45) NULL,
+  user_agent TEXT NULL,
+  metadata JSON NULL,
+  created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+
+  INDEX idx_user_created (user_id, created_at),
+  INDEX idx_event_type (event_type),
+
+  FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
\ No newline at end of file
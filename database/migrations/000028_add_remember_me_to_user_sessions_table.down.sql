@@ -0,0 +1 @@
+ALTER TABLE user_sessions DROP COLUMN remember_me;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE user_sessions ADD COLUMN remember_me BOOLEAN NOT NULL DEFAULT FALSE AFTER device_id;
\ No newline at end of file
@@ -33,7 +33,7 @@ func User(db *sql.DB, u utils.Utility) error {
 		}
 
 		// Create user
-		hashPass, err := u.HashGenerate("superadmin")
+		hashPass, err := u.HashGenerate(ctx, "superadmin")
 		if err != nil {
 			return fmt.Errorf("generate hash password gagal: %w", err)
 		}
@@ -48,7 +48,7 @@ func User(db *sql.DB, u utils.Utility) error {
 
 		// create profiles
 		_, err = tx.ExecContext(ctx, `INSERT INTO profiles(id, user_id, full_name, address, gender, image) VALUES(?, ?, ?, ?, ?, ?)`,
-			u.ULIDGenerate(), userID, "Super Admin Pertama", "Samarang awi", 1, "default.jpg")
+			u.ULIDGenerate(), userID, "Super Admin Pertama", "Samarang awi", "laki-laki", "default.jpg")
 		if err != nil {
 			return fmt.Errorf("create profiles gagal: %w", err)
 		}
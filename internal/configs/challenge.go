@@ -0,0 +1,18 @@
+package configs
+
+import "time"
+
+// ChallengeConfig challenge diminta dari IP yang gagal login/register sebanyak Threshold kali dalam Window.
+// Driver "pow" (proof-of-work bawaan), "hcaptcha" atau "turnstile"
+type ChallengeConfig struct {
+	Enabled       bool
+	Driver        string
+	Secret        string
+	Difficulty    int
+	TTL           time.Duration
+	Threshold     int
+	Window        time.Duration
+	SiteKey       string
+	CaptchaSecret string
+	VerifyURL     string
+}
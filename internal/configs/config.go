@@ -2,18 +2,37 @@ package configs
 
 import (
 	"os"
+	"runtime"
 	"strconv"
 	"strings"
 	"time"
 )
 
 type AppConfig struct {
-	DB     DBConfig
-	Mode   GinModeConfig
-	Server ServerPortConfig
-	Cors   CORSConfig
-	JWT    JWTConfig
-	Mail   EmailConfig
+	DB          DBConfig
+	Mode        GinModeConfig
+	Server      ServerPortConfig
+	Cors        CORSConfig
+	Cookie      CookieConfig
+	JWT         JWTConfig
+	Mail        EmailConfig
+	Lockout     LockoutConfig
+	MFA         MFAConfig
+	WebAuthn    WebAuthnConfig
+	Identity    IdentityConfig
+	MagicLink   MagicLinkConfig
+	Reset       PasswordResetConfig
+	EmailChange EmailChangeConfig
+	Username    UsernameConfig
+	Storage     StorageConfig
+	Avatar      AvatarConfig
+	Password    PasswordPolicyConfig
+	Hash        PasswordHashConfig
+	RateLimit   RateLimitConfig
+	Challenge   ChallengeConfig
+	LoginNotify LoginNotifyConfig
+	Device      DeviceConfig
+	Session     SessionConfig
 }
 
 func LoadConfig() *AppConfig {
@@ -38,9 +57,12 @@ func LoadConfig() *AppConfig {
 			AllowHeaders:     strings.Split(os.Getenv("CORS_ALLOW_HEADERS"), ","),
 			AllowCredentials: os.Getenv("CORS_ALLOW_CREDENTIALS") == "true",
 		},
+		Cookie: loadCookieConfig(),
 		JWT: JWTConfig{
-			Secret:         getSecretOrDefault("JWT_SECRET", "default-secret"),
-			AccessTokenTTL: 15 * time.Minute,
+			Secret:           getSecretOrDefault("JWT_SECRET", "default-secret"),
+			AccessTokenTTL:   getDurationOrDefault("JWT_ACCESS_TTL", 15*time.Minute),
+			VersionCacheTTL:  getDurationOrDefault("JWT_VERSION_CACHE_TTL", 30*time.Second),
+			VersionCacheSize: getIntOrDefault("JWT_VERSION_CACHE_SIZE", 10000),
 		},
 		Mail: EmailConfig{
 			MailHost:        os.Getenv("MAIL_HOST"),
@@ -49,6 +71,129 @@ func LoadConfig() *AppConfig {
 			MailPassword:    os.Getenv("MAIL_PASSWORD"),
 			MailFromAddress: os.Getenv("MAIL_FROM_ADDRESS"),
 			FrontVerifyUrl:  os.Getenv("FRONTEND_VERIFY_URL"),
+			QueueWorkers:    getIntOrDefault("MAIL_QUEUE_WORKERS", 4),
+			QueueSize:       getIntOrDefault("MAIL_QUEUE_SIZE", 100),
+		},
+		Lockout: LockoutConfig{
+			MaxAttempts:   getIntOrDefault("LOCKOUT_MAX_ATTEMPTS", 5),
+			IPMaxAttempts: getIntOrDefault("LOCKOUT_IP_MAX_ATTEMPTS", 10),
+			BaseDuration:  getDurationOrDefault("LOCKOUT_BASE_DURATION", time.Minute),
+			MaxDuration:   getDurationOrDefault("LOCKOUT_MAX_DURATION", 24*time.Hour),
+			AttemptWindow: getDurationOrDefault("LOCKOUT_ATTEMPT_WINDOW", 15*time.Minute),
+		},
+		MFA: MFAConfig{
+			Issuer:        getSecretOrDefault("MFA_ISSUER", "Auth Service"),
+			EncryptionKey: getPurposeSecret("MFA_ENCRYPTION_KEY", "mfa-encryption"),
+			ChallengeTTL:  getDurationOrDefault("MFA_CHALLENGE_TTL", 5*time.Minute),
+		},
+		WebAuthn: WebAuthnConfig{
+			RPID:          getSecretOrDefault("WEBAUTHN_RP_ID", "localhost"),
+			RPDisplayName: getSecretOrDefault("WEBAUTHN_RP_DISPLAY_NAME", "Auth Service"),
+			RPOrigins:     strings.Split(getSecretOrDefault("WEBAUTHN_RP_ORIGINS", "http://localhost:3000"), ","),
+			SessionTTL:    getDurationOrDefault("WEBAUTHN_SESSION_TTL", 5*time.Minute),
+		},
+		Identity: IdentityConfig{
+			StateTTL:    getDurationOrDefault("IDENTITY_STATE_TTL", 10*time.Minute),
+			DefaultRole: getSecretOrDefault("IDENTITY_DEFAULT_ROLE", "tamu"),
+			Providers:   loadIdentityProviders(),
+		},
+		MagicLink: MagicLinkConfig{
+			TTL:             getDurationOrDefault("MAGIC_LINK_TTL", 15*time.Minute),
+			MaxRequests:     getIntOrDefault("MAGIC_LINK_MAX_REQUESTS", 3),
+			Window:          getDurationOrDefault("MAGIC_LINK_WINDOW", time.Hour),
+			AllowUnverified: getBoolOrDefault("MAGIC_LINK_ALLOW_UNVERIFIED", false),
+		},
+		Reset: PasswordResetConfig{
+			TTL:         getDurationOrDefault("PASSWORD_RESET_TTL", 30*time.Minute),
+			MaxRequests: getIntOrDefault("PASSWORD_RESET_MAX_REQUESTS", 3),
+			Window:      getDurationOrDefault("PASSWORD_RESET_WINDOW", time.Hour),
+		},
+		EmailChange: EmailChangeConfig{
+			TTL:         getDurationOrDefault("EMAIL_CHANGE_TTL", time.Hour),
+			RevertTTL:   getDurationOrDefault("EMAIL_CHANGE_REVERT_TTL", 72*time.Hour),
+			MaxRequests: getIntOrDefault("EMAIL_CHANGE_MAX_REQUESTS", 3),
+			Window:      getDurationOrDefault("EMAIL_CHANGE_WINDOW", time.Hour),
+		},
+		Username: UsernameConfig{
+			MinLength:           getIntOrDefault("USERNAME_MIN_LENGTH", 3),
+			MaxLength:           getIntOrDefault("USERNAME_MAX_LENGTH", 30),
+			ChangeInterval:      time.Duration(getIntOrDefault("USERNAME_CHANGE_INTERVAL_DAYS", 30)) * 24 * time.Hour,
+			AdminChangeInterval: time.Duration(getIntOrDefault("USERNAME_ADMIN_CHANGE_INTERVAL_DAYS", 0)) * 24 * time.Hour,
+		},
+		Storage: StorageConfig{
+			Driver:         getSecretOrDefault("STORAGE_DRIVER", "local"),
+			PublicURL:      os.Getenv("STORAGE_PUBLIC_URL"),
+			LocalDir:       getSecretOrDefault("STORAGE_LOCAL_DIR", "storage/uploads"),
+			LocalRoute:     getSecretOrDefault("STORAGE_LOCAL_ROUTE", "/uploads"),
+			S3Endpoint:     os.Getenv("S3_ENDPOINT"),
+			S3Region:       getSecretOrDefault("S3_REGION", "us-east-1"),
+			S3Bucket:       os.Getenv("S3_BUCKET"),
+			S3AccessKey:    os.Getenv("S3_ACCESS_KEY"),
+			S3SecretKey:    os.Getenv("S3_SECRET_KEY"),
+			S3UsePathStyle: getBoolOrDefault("S3_USE_PATH_STYLE", true),
+		},
+		Avatar: AvatarConfig{
+			MaxBytes:  int64(getIntOrDefault("AVATAR_MAX_BYTES", 5<<20)),
+			MaxPixels: getIntOrDefault("AVATAR_MAX_PIXELS", 40_000_000),
+			Sizes:     getSizesOrDefault("AVATAR_SIZES", []int{64, 128, 256, 512}),
+			Quality:   getIntOrDefault("AVATAR_JPEG_QUALITY", 85),
+		},
+		Password: PasswordPolicyConfig{
+			MinLength:     getIntOrDefault("PASSWORD_MIN_LENGTH", 8),
+			RequireUpper:  getBoolOrDefault("PASSWORD_REQUIRE_UPPER", false),
+			RequireLower:  getBoolOrDefault("PASSWORD_REQUIRE_LOWER", true),
+			RequireDigit:  getBoolOrDefault("PASSWORD_REQUIRE_DIGIT", true),
+			RequireSymbol: getBoolOrDefault("PASSWORD_REQUIRE_SYMBOL", false),
+			BreachedCheck: getBoolOrDefault("PASSWORD_BREACHED_CHECK", true),
+			BreachedFile:  os.Getenv("PASSWORD_BREACHED_FILE"),
+		},
+		Hash: PasswordHashConfig{
+			Algorithm:         getSecretOrDefault("PASSWORD_HASH_ALGORITHM", "argon2id"),
+			BcryptCost:        getIntOrDefault("PASSWORD_BCRYPT_COST", 10),
+			Argon2Memory:      getIntOrDefault("PASSWORD_ARGON2_MEMORY", 64*1024),
+			Argon2Iterations:  getIntOrDefault("PASSWORD_ARGON2_ITERATIONS", 3),
+			Argon2Parallelism: getIntOrDefault("PASSWORD_ARGON2_PARALLELISM", 2),
+			Workers:           getIntOrDefault("PASSWORD_HASH_WORKERS", runtime.NumCPU()),
+			QueueSize:         getIntOrDefault("PASSWORD_HASH_QUEUE_SIZE", 64),
+			QueueTimeout:      getDurationOrDefault("PASSWORD_HASH_QUEUE_TIMEOUT", 2*time.Second),
+		},
+		RateLimit: RateLimitConfig{
+			Enabled:       getBoolOrDefault("RATE_LIMIT_ENABLED", true),
+			Store:         getSecretOrDefault("RATE_LIMIT_STORE", "memory"),
+			RedisAddr:     getSecretOrDefault("REDIS_ADDR", "localhost:6379"),
+			RedisPassword: os.Getenv("REDIS_PASSWORD"),
+			RedisDB:       getIntOrDefault("REDIS_DB", 0),
+			RedisPrefix:   getSecretOrDefault("RATE_LIMIT_REDIS_PREFIX", "ratelimit:"),
+			Routes:        loadRateLimitRoutes(),
+		},
+		Challenge: ChallengeConfig{
+			Enabled:       getBoolOrDefault("CHALLENGE_ENABLED", true),
+			Driver:        getSecretOrDefault("CHALLENGE_DRIVER", "pow"),
+			Secret:        getPurposeSecret("CHALLENGE_SECRET", "challenge"),
+			Difficulty:    getIntOrDefault("CHALLENGE_POW_DIFFICULTY", 20),
+			TTL:           getDurationOrDefault("CHALLENGE_TTL", 5*time.Minute),
+			Threshold:     getIntOrDefault("CHALLENGE_THRESHOLD", 5),
+			Window:        getDurationOrDefault("CHALLENGE_WINDOW", 15*time.Minute),
+			SiteKey:       os.Getenv("CAPTCHA_SITE_KEY"),
+			CaptchaSecret: os.Getenv("CAPTCHA_SECRET"),
+			VerifyURL:     os.Getenv("CAPTCHA_VERIFY_URL"),
+		},
+		LoginNotify: LoginNotifyConfig{
+			Enabled:       getBoolOrDefault("LOGIN_NOTIFY_ENABLED", true),
+			HistoryWindow: time.Duration(getIntOrDefault("LOGIN_NOTIFY_HISTORY_DAYS", 90)) * 24 * time.Hour,
+			HistoryLimit:  getIntOrDefault("LOGIN_NOTIFY_HISTORY_LIMIT", 50),
+			RevokeLinkTTL: getDurationOrDefault("LOGIN_NOTIFY_REVOKE_TTL", 7*24*time.Hour),
+		},
+		Device: DeviceConfig{
+			Secret:    getPurposeSecret("DEVICE_SECRET", "device"),
+			CookieTTL: time.Duration(getIntOrDefault("DEVICE_COOKIE_DAYS", 365)) * 24 * time.Hour,
+			TrustTTL:  time.Duration(getIntOrDefault("DEVICE_TRUST_DAYS", 30)) * 24 * time.Hour,
+		},
+		Session: SessionConfig{
+			ReuseGrace: getDurationOrDefault("SESSION_REUSE_GRACE", 30*time.Second),
+			Default:    loadSessionPolicy("SESSION_POLICY", "24h/168h"),
+			RememberMe: loadSessionPolicy("SESSION_POLICY_REMEMBER_ME", "720h/2160h"),
+			Roles:      loadSessionRolePolicies(),
 		},
 	}
 }
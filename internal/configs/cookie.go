@@ -0,0 +1,47 @@
+package configs
+
+import (
+	"log"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// CookieConfig atribut Secure dan SameSite untuk cookie yang dibuat aplikasi
+// (access_token, refresh_token, device_id, verify_email dan state login eksternal)
+type CookieConfig struct {
+	Secure   bool
+	SameSite http.SameSite
+}
+
+func loadCookieConfig() CookieConfig {
+	cfg := CookieConfig{
+		Secure:   getBoolOrDefault("COOKIE_SECURE", true),
+		SameSite: getSameSiteOrDefault("COOKIE_SAMESITE", http.SameSiteLaxMode),
+	}
+
+	// browser menolak cookie SameSite=None tanpa Secure
+	if cfg.SameSite == http.SameSiteNoneMode && !cfg.Secure {
+		log.Fatalf("[ERROR] COOKIE_SAMESITE=none membutuhkan COOKIE_SECURE=true")
+	}
+
+	return cfg
+}
+
+// getSameSiteOrDefault nilai yang tidak dikenal menghentikan aplikasi saat start
+func getSameSiteOrDefault(key string, fallback http.SameSite) http.SameSite {
+	value := os.Getenv(key)
+	switch strings.ToLower(value) {
+	case "":
+		return fallback
+	case "lax":
+		return http.SameSiteLaxMode
+	case "strict":
+		return http.SameSiteStrictMode
+	case "none":
+		return http.SameSiteNoneMode
+	}
+
+	log.Fatalf("[ERROR] %s=%q tidak dikenal, gunakan lax, strict atau none", key, value)
+	return fallback
+}
@@ -0,0 +1,11 @@
+package configs
+
+import "time"
+
+// DeviceConfig Secret menandatangani device ID di cookie/header, CookieTTL umur cookie device_id
+// dan TrustTTL lama perangkat tepercaya boleh melewati MFA
+type DeviceConfig struct {
+	Secret    string
+	CookieTTL time.Duration
+	TrustTTL  time.Duration
+}
@@ -7,4 +7,6 @@ type EmailConfig struct {
 	MailPassword    string
 	MailFromAddress string
 	FrontVerifyUrl  string
+	QueueWorkers    int
+	QueueSize       int
 }
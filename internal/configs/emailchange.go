@@ -0,0 +1,10 @@
+package configs
+
+import "time"
+
+type EmailChangeConfig struct {
+	TTL         time.Duration
+	RevertTTL   time.Duration
+	MaxRequests int
+	Window      time.Duration
+}
@@ -0,0 +1,82 @@
+package configs
+
+import (
+	"os"
+	"strings"
+	"time"
+)
+
+type IdentityConfig struct {
+	StateTTL    time.Duration
+	DefaultRole string
+	Providers   []IdentityProviderConfig
+}
+
+type IdentityProviderConfig struct {
+	Name         string
+	Type         string // oidc atau oauth2
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	IssuerURL    string // kosong berarti issuer tidak dicek (misal Microsoft multi-tenant)
+	AuthURL      string
+	TokenURL     string
+	JWKSURL      string
+	UserInfoURL  string
+	EmailsURL    string
+	SubjectField string
+}
+
+// loadIdentityProviders hanya mengaktifkan provider yang client ID-nya diisi
+func loadIdentityProviders() []IdentityProviderConfig {
+	microsoftTenant := getSecretOrDefault("MICROSOFT_TENANT", "organizations")
+	all := []IdentityProviderConfig{
+		{
+			Name:         "google",
+			Type:         "oidc",
+			ClientID:     os.Getenv("GOOGLE_CLIENT_ID"),
+			ClientSecret: os.Getenv("GOOGLE_CLIENT_SECRET"),
+			RedirectURL:  getSecretOrDefault("GOOGLE_REDIRECT_URL", "http://localhost:8080/api/auth/oauth/google/callback"),
+			Scopes:       []string{"openid", "email", "profile"},
+			IssuerURL:    getSecretOrDefault("GOOGLE_ISSUER_URL", "https://accounts.google.com"),
+			AuthURL:      getSecretOrDefault("GOOGLE_AUTH_URL", "https://accounts.google.com/o/oauth2/v2/auth"),
+			TokenURL:     getSecretOrDefault("GOOGLE_TOKEN_URL", "https://oauth2.googleapis.com/token"),
+			JWKSURL:      getSecretOrDefault("GOOGLE_JWKS_URL", "https://www.googleapis.com/oauth2/v3/certs"),
+		},
+		{
+			Name:         "microsoft",
+			Type:         "oidc",
+			ClientID:     os.Getenv("MICROSOFT_CLIENT_ID"),
+			ClientSecret: os.Getenv("MICROSOFT_CLIENT_SECRET"),
+			RedirectURL:  getSecretOrDefault("MICROSOFT_REDIRECT_URL", "http://localhost:8080/api/auth/oauth/microsoft/callback"),
+			Scopes:       []string{"openid", "email", "profile"},
+			IssuerURL:    os.Getenv("MICROSOFT_ISSUER_URL"),
+			AuthURL:      getSecretOrDefault("MICROSOFT_AUTH_URL", "https://login.microsoftonline.com/"+microsoftTenant+"/oauth2/v2.0/authorize"),
+			TokenURL:     getSecretOrDefault("MICROSOFT_TOKEN_URL", "https://login.microsoftonline.com/"+microsoftTenant+"/oauth2/v2.0/token"),
+			JWKSURL:      getSecretOrDefault("MICROSOFT_JWKS_URL", "https://login.microsoftonline.com/"+microsoftTenant+"/discovery/v2.0/keys"),
+		},
+		{
+			Name:         "github",
+			Type:         "oauth2",
+			ClientID:     os.Getenv("GITHUB_CLIENT_ID"),
+			ClientSecret: os.Getenv("GITHUB_CLIENT_SECRET"),
+			RedirectURL:  getSecretOrDefault("GITHUB_REDIRECT_URL", "http://localhost:8080/api/auth/oauth/github/callback"),
+			Scopes:       strings.Split(getSecretOrDefault("GITHUB_SCOPES", "read:user,user:email"), ","),
+			AuthURL:      getSecretOrDefault("GITHUB_AUTH_URL", "https://github.com/login/oauth/authorize"),
+			TokenURL:     getSecretOrDefault("GITHUB_TOKEN_URL", "https://github.com/login/oauth/access_token"),
+			UserInfoURL:  getSecretOrDefault("GITHUB_USERINFO_URL", "https://api.github.com/user"),
+			EmailsURL:    getSecretOrDefault("GITHUB_EMAILS_URL", "https://api.github.com/user/emails"),
+			SubjectField: "id",
+		},
+	}
+
+	var providers []IdentityProviderConfig
+	for _, p := range all {
+		if p.ClientID != "" {
+			providers = append(providers, p)
+		}
+	}
+
+	return providers
+}
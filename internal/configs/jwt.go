@@ -5,9 +5,13 @@ import (
 	"time"
 )
 
+// JWTConfig token_version di access token dicocokkan ke database oleh AuthMiddleware,
+// hasilnya di-cache selama VersionCacheTTL untuk maksimal VersionCacheSize user
 type JWTConfig struct {
-	Secret         string
-	AccessTokenTTL time.Duration
+	Secret           string
+	AccessTokenTTL   time.Duration
+	VersionCacheTTL  time.Duration
+	VersionCacheSize int
 }
 
 func getSecretOrDefault(key, fallback string) string {
@@ -0,0 +1,33 @@
+package configs
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+type LockoutConfig struct {
+	MaxAttempts   int
+	IPMaxAttempts int
+	BaseDuration  time.Duration
+	MaxDuration   time.Duration
+	AttemptWindow time.Duration
+}
+
+func getIntOrDefault(key string, fallback int) int {
+	val, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+
+	return val
+}
+
+func getDurationOrDefault(key string, fallback time.Duration) time.Duration {
+	val, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+
+	return val
+}
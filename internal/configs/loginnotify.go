@@ -0,0 +1,12 @@
+package configs
+
+import "time"
+
+// LoginNotifyConfig login dibandingkan dengan session user dalam HistoryWindow terakhir (maksimal HistoryLimit session),
+// link revoke di email berlaku selama RevokeLinkTTL
+type LoginNotifyConfig struct {
+	Enabled       bool
+	HistoryWindow time.Duration
+	HistoryLimit  int
+	RevokeLinkTTL time.Duration
+}
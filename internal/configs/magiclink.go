@@ -0,0 +1,24 @@
+package configs
+
+import (
+	"os"
+	"time"
+)
+
+type MagicLinkConfig struct {
+	TTL             time.Duration
+	MaxRequests     int
+	Window          time.Duration
+	AllowUnverified bool
+}
+
+func getBoolOrDefault(key string, fallback bool) bool {
+	switch os.Getenv(key) {
+	case "true":
+		return true
+	case "false":
+		return false
+	default:
+		return fallback
+	}
+}
@@ -0,0 +1,9 @@
+package configs
+
+import "time"
+
+type MFAConfig struct {
+	Issuer        string
+	EncryptionKey string
+	ChallengeTTL  time.Duration
+}
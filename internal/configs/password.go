@@ -0,0 +1,28 @@
+package configs
+
+import "time"
+
+// PasswordPolicyConfig aturan password, BreachedFile kosong memakai daftar password bocor bawaan
+type PasswordPolicyConfig struct {
+	MinLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	BreachedCheck bool
+	BreachedFile  string
+}
+
+// PasswordHashConfig algoritma hash password baru ("argon2id" atau "bcrypt"), Argon2Memory dalam KiB.
+// Hash lama dengan algoritma atau parameter lebih lemah dibuat ulang saat login berhasil.
+// Workers membatasi hash yang berjalan bersamaan, QueueSize dan QueueTimeout membatasi antrean di belakangnya
+type PasswordHashConfig struct {
+	Algorithm         string
+	BcryptCost        int
+	Argon2Memory      int
+	Argon2Iterations  int
+	Argon2Parallelism int
+	Workers           int
+	QueueSize         int
+	QueueTimeout      time.Duration
+}
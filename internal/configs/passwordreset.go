@@ -0,0 +1,9 @@
+package configs
+
+import "time"
+
+type PasswordResetConfig struct {
+	TTL         time.Duration
+	MaxRequests int
+	Window      time.Duration
+}
@@ -0,0 +1,93 @@
+package configs
+
+import (
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimitConfig Store "memory" untuk satu instance, "redis" jika aplikasi berjalan di beberapa instance.
+// Routes berisi limit per nama route, route tanpa limit tidak dibatasi
+type RateLimitConfig struct {
+	Enabled       bool
+	Store         string
+	RedisAddr     string
+	RedisPassword string
+	RedisDB       int
+	RedisPrefix   string
+	Routes        map[string]RateLimitRule
+}
+
+type RateLimitRule struct {
+	Algorithm string
+	Limit     int
+	Window    time.Duration
+}
+
+// rateLimitRoutes nama route dan limit bawaan, diatur lewat RATE_LIMIT_<ROUTE>=<limit>/<window> (misal 10/1m)
+// dan RATE_LIMIT_<ROUTE>_ALGORITHM. Nilai "off" menonaktifkan limit route tersebut
+var rateLimitRoutes = map[string]string{
+	"login":                      "20/1m",
+	"login_identifier":           "5/1m",
+	"register":                   "5/1h",
+	"verify_resend":              "3/10m",
+	"refresh":                    "30/1m",
+	"magic_link":                 "5/10m",
+	"magic_link_identifier":      "3/10m",
+	"forgot_password":            "5/10m",
+	"forgot_password_identifier": "3/10m",
+	"mfa":                        "10/10m",
+	"session_revoke_link":        "10/10m",
+}
+
+func loadRateLimitRoutes() map[string]RateLimitRule {
+	algorithm := getAlgorithmOrDefault("RATE_LIMIT_ALGORITHM", "token_bucket")
+
+	routes := make(map[string]RateLimitRule, len(rateLimitRoutes))
+	for name, fallback := range rateLimitRoutes {
+		env := "RATE_LIMIT_" + strings.ToUpper(name)
+		spec := getSecretOrDefault(env, fallback)
+		if spec == "off" {
+			continue
+		}
+
+		rule, ok := parseRateLimit(spec)
+		if !ok {
+			rule, _ = parseRateLimit(fallback)
+		}
+		rule.Algorithm = getAlgorithmOrDefault(env+"_ALGORITHM", algorithm)
+		routes[name] = rule
+	}
+
+	return routes
+}
+
+// getAlgorithmOrDefault algoritma yang tidak dikenal menghentikan aplikasi saat start,
+// bukan diam-diam memakai algoritma lain
+func getAlgorithmOrDefault(key, fallback string) string {
+	algorithm := getSecretOrDefault(key, fallback)
+	if algorithm != "token_bucket" && algorithm != "sliding_window" {
+		log.Fatalf("[ERROR] %s=%q tidak dikenal, gunakan token_bucket atau sliding_window", key, algorithm)
+	}
+
+	return algorithm
+}
+
+func parseRateLimit(spec string) (RateLimitRule, bool) {
+	count, window, ok := strings.Cut(spec, "/")
+	if !ok {
+		return RateLimitRule{}, false
+	}
+
+	limit, err := strconv.Atoi(strings.TrimSpace(count))
+	if err != nil || limit <= 0 {
+		return RateLimitRule{}, false
+	}
+	duration, err := time.ParseDuration(strings.TrimSpace(window))
+	if err != nil || duration < time.Second {
+		return RateLimitRule{}, false
+	}
+
+	return RateLimitRule{Limit: limit, Window: duration}, true
+}
@@ -0,0 +1,33 @@
+package configs
+
+import (
+	"crypto/hkdf"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"os"
+)
+
+// getPurposeSecret secret khusus satu fungsi agar satu kunci tidak sekaligus menandatangani JWT dan mengenkripsi data lain.
+// Nilai kosong hanya diizinkan di mode debug, secret lalu diturunkan dari JWT_SECRET dengan HKDF berlabel purpose.
+// Di luar mode debug nilai kosong atau sama dengan JWT_SECRET menghentikan aplikasi saat start
+func getPurposeSecret(key, purpose string) string {
+	jwtSecret := getSecretOrDefault("JWT_SECRET", "default-secret")
+	if val := os.Getenv(key); val != "" {
+		if val == jwtSecret {
+			log.Fatalf("[ERROR] %s tidak boleh sama dengan JWT_SECRET", key)
+		}
+		return val
+	}
+
+	if getModeOrDefault("GIN_MODE", "debug") != "debug" {
+		log.Fatalf("[ERROR] %s wajib diisi di luar mode debug", key)
+	}
+
+	derived, err := hkdf.Key(sha256.New, []byte(jwtSecret), nil, "auth-service/"+purpose, 32)
+	if err != nil {
+		log.Fatalf("[ERROR] turunkan %s gagal: %v", key, err)
+	}
+
+	return hex.EncodeToString(derived)
+}
@@ -0,0 +1,70 @@
+package configs
+
+import (
+	"strings"
+	"time"
+)
+
+// SessionConfig ReuseGrace jeda setelah rotasi refresh token di mana token lama yang dipakai lagi hanya ditolak,
+// tidak dianggap pencurian token (dua refresh bersamaan dari client yang sama).
+// Default dipakai login biasa, RememberMe jika user memilih "ingat saya", Roles menggantikan keduanya untuk role tertentu
+type SessionConfig struct {
+	ReuseGrace time.Duration
+	Default    SessionPolicy
+	RememberMe SessionPolicy
+	Roles      map[string]SessionPolicy
+}
+
+// SessionPolicy IdleTimeout batas session tidak di-refresh, AbsoluteLifetime batas umur session sejak login
+// meskipun terus di-refresh
+type SessionPolicy struct {
+	IdleTimeout      time.Duration
+	AbsoluteLifetime time.Duration
+}
+
+// loadSessionPolicy membaca SESSION_POLICY* dengan format <idle>/<absolute> (misal 24h/168h)
+func loadSessionPolicy(key, fallback string) SessionPolicy {
+	policy, ok := parseSessionPolicy(getSecretOrDefault(key, fallback))
+	if !ok {
+		policy, _ = parseSessionPolicy(fallback)
+	}
+
+	return policy
+}
+
+// loadSessionRolePolicies membaca SESSION_POLICY_ROLES dengan format <role>=<idle>/<absolute> dipisah titik koma,
+// misal "super admin=1h/8h;admin=2h/12h". Role dengan format tidak valid diabaikan
+func loadSessionRolePolicies() map[string]SessionPolicy {
+	roles := map[string]SessionPolicy{}
+	for _, entry := range strings.Split(getSecretOrDefault("SESSION_POLICY_ROLES", ""), ";") {
+		role, spec, ok := strings.Cut(entry, "=")
+		role = strings.TrimSpace(role)
+		if !ok || role == "" {
+			continue
+		}
+
+		if policy, ok := parseSessionPolicy(spec); ok {
+			roles[role] = policy
+		}
+	}
+
+	return roles
+}
+
+func parseSessionPolicy(spec string) (SessionPolicy, bool) {
+	idle, absolute, ok := strings.Cut(spec, "/")
+	if !ok {
+		return SessionPolicy{}, false
+	}
+
+	idleTimeout, err := time.ParseDuration(strings.TrimSpace(idle))
+	if err != nil || idleTimeout < time.Minute {
+		return SessionPolicy{}, false
+	}
+	lifetime, err := time.ParseDuration(strings.TrimSpace(absolute))
+	if err != nil || lifetime < idleTimeout {
+		return SessionPolicy{}, false
+	}
+
+	return SessionPolicy{IdleTimeout: idleTimeout, AbsoluteLifetime: lifetime}, true
+}
@@ -0,0 +1,46 @@
+package configs
+
+import (
+	"strconv"
+	"strings"
+)
+
+// StorageConfig driver "local" menyimpan file di LocalDir dan disajikan aplikasi di LocalRoute,
+// driver "s3" untuk layanan S3-compatible. PublicURL adalah prefix URL file yang dikembalikan ke client
+type StorageConfig struct {
+	Driver         string
+	PublicURL      string
+	LocalDir       string
+	LocalRoute     string
+	S3Endpoint     string
+	S3Region       string
+	S3Bucket       string
+	S3AccessKey    string
+	S3SecretKey    string
+	S3UsePathStyle bool
+}
+
+type AvatarConfig struct {
+	MaxBytes  int64
+	MaxPixels int
+	Sizes     []int
+	Quality   int
+}
+
+func getSizesOrDefault(key string, fallback []int) []int {
+	raw := getSecretOrDefault(key, "")
+	if raw == "" {
+		return fallback
+	}
+
+	var sizes []int
+	for _, part := range strings.Split(raw, ",") {
+		size, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || size <= 0 {
+			return fallback
+		}
+		sizes = append(sizes, size)
+	}
+
+	return sizes
+}
@@ -0,0 +1,10 @@
+package configs
+
+import "time"
+
+type UsernameConfig struct {
+	MinLength           int
+	MaxLength           int
+	ChangeInterval      time.Duration
+	AdminChangeInterval time.Duration
+}
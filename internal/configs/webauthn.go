@@ -0,0 +1,10 @@
+package configs
+
+import "time"
+
+type WebAuthnConfig struct {
+	RPID          string
+	RPDisplayName string
+	RPOrigins     []string
+	SessionTTL    time.Duration
+}
@@ -3,6 +3,7 @@ package request
 type LoginRequest struct {
 	Identifier string `json:"identifier" binding:"required"`
 	Password   string `json:"password" binding:"required"`
+	RememberMe bool   `json:"remember_me"`
 }
 
 func (l *LoginRequest) Sanitize() map[string]any {
@@ -15,7 +16,7 @@ type RegisterRequest struct {
 	FullName        string   `json:"full_name" binding:"required"`
 	Username        string   `json:"username" binding:"required,excludesall= "`
 	Email           string   `json:"email" binding:"required,email"`
-	Password        string   `json:"password" binding:"required,min=6"`
+	Password        string   `json:"password" binding:"required"`
 	ConfirmPassword string   `json:"confirm_password" binding:"required"`
 	Roles           []string `json:"roles" binding:"required"`
 }
@@ -27,3 +28,69 @@ func (r *RegisterRequest) Sanitize() map[string]any {
 		"email":     r.Email,
 	}
 }
+
+type MagicLinkRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+func (m *MagicLinkRequest) Sanitize() map[string]any {
+	return map[string]any{
+		"email": m.Email,
+	}
+}
+
+type MagicLinkLoginRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+func (m *MagicLinkLoginRequest) Sanitize() map[string]any {
+	return map[string]any{
+		"token": m.Token,
+	}
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+func (f *ForgotPasswordRequest) Sanitize() map[string]any {
+	return map[string]any{
+		"email": f.Email,
+	}
+}
+
+type ResetPasswordRequest struct {
+	Token           string `json:"token" binding:"required"`
+	Password        string `json:"password" binding:"required"`
+	ConfirmPassword string `json:"confirm_password" binding:"required"`
+}
+
+func (r *ResetPasswordRequest) Sanitize() map[string]any {
+	return map[string]any{
+		"token": r.Token,
+	}
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	Password        string `json:"password" binding:"required"`
+	ConfirmPassword string `json:"confirm_password" binding:"required"`
+	Revoke          string `json:"revoke" binding:"required,oneof=others all"`
+}
+
+func (c *ChangePasswordRequest) Sanitize() map[string]any {
+	return map[string]any{
+		"revoke": c.Revoke,
+	}
+}
+
+type EmailChangeRequest struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password"`
+}
+
+func (e *EmailChangeRequest) Sanitize() map[string]any {
+	return map[string]any{
+		"email": e.Email,
+	}
+}
@@ -0,0 +1,12 @@
+package request
+
+// DeviceRenameRequest: nama kosong menghapus nama perangkat
+type DeviceRenameRequest struct {
+	Name string `json:"name" binding:"max=100"`
+}
+
+func (d *DeviceRenameRequest) Sanitize() map[string]any {
+	return map[string]any{
+		"name": d.Name,
+	}
+}
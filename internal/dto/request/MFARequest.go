@@ -0,0 +1,51 @@
+package request
+
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+func (m *MFACodeRequest) Sanitize() map[string]any {
+	return map[string]any{}
+}
+
+type MFADisableRequest struct {
+	Code     string `json:"code" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+func (m *MFADisableRequest) Sanitize() map[string]any {
+	return map[string]any{}
+}
+
+type MFARecoveryCodesRequest struct {
+	Code     string `json:"code" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+func (m *MFARecoveryCodesRequest) Sanitize() map[string]any {
+	return map[string]any{}
+}
+
+// LoginMFARequest TrustDevice melewati MFA di perangkat ini untuk login berikutnya selama DEVICE_TRUST_DAYS
+type LoginMFARequest struct {
+	MFAToken    string `json:"mfa_token" binding:"required"`
+	Code        string `json:"code" binding:"required"`
+	TrustDevice bool   `json:"trust_device"`
+}
+
+func (l *LoginMFARequest) Sanitize() map[string]any {
+	return map[string]any{
+		"mfa_token": l.MFAToken,
+	}
+}
+
+type LoginRecoveryRequest struct {
+	MFAToken     string `json:"mfa_token" binding:"required"`
+	RecoveryCode string `json:"recovery_code" binding:"required"`
+}
+
+func (l *LoginRecoveryRequest) Sanitize() map[string]any {
+	return map[string]any{
+		"mfa_token": l.MFAToken,
+	}
+}
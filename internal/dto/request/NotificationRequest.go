@@ -0,0 +1,11 @@
+package request
+
+// NotificationPreferencesRequest: jenis yang tidak dikirim tidak diubah
+type NotificationPreferencesRequest struct {
+	NewDevice  *bool `json:"new_device"`
+	NewNetwork *bool `json:"new_network"`
+}
+
+type SessionRevokeLinkRequest struct {
+	Token string `json:"token" binding:"required"`
+}
@@ -0,0 +1,61 @@
+package request
+
+import "encoding/json"
+
+type PasskeyRegisterFinishRequest struct {
+	SessionID  string          `json:"session_id" binding:"required"`
+	Name       string          `json:"name" binding:"required,max=100"`
+	Credential json.RawMessage `json:"credential" binding:"required" swaggertype:"object"`
+}
+
+func (p *PasskeyRegisterFinishRequest) Sanitize() map[string]any {
+	return map[string]any{
+		"session_id": p.SessionID,
+		"name":       p.Name,
+	}
+}
+
+type PasskeyRenameRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+func (p *PasskeyRenameRequest) Sanitize() map[string]any {
+	return map[string]any{
+		"name": p.Name,
+	}
+}
+
+type PasskeyLoginFinishRequest struct {
+	SessionID  string          `json:"session_id" binding:"required"`
+	Credential json.RawMessage `json:"credential" binding:"required" swaggertype:"object"`
+}
+
+func (p *PasskeyLoginFinishRequest) Sanitize() map[string]any {
+	return map[string]any{
+		"session_id": p.SessionID,
+	}
+}
+
+type LoginPasskeyBeginRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+}
+
+func (l *LoginPasskeyBeginRequest) Sanitize() map[string]any {
+	return map[string]any{
+		"mfa_token": l.MFAToken,
+	}
+}
+
+type LoginPasskeyFinishRequest struct {
+	MFAToken    string          `json:"mfa_token" binding:"required"`
+	SessionID   string          `json:"session_id" binding:"required"`
+	Credential  json.RawMessage `json:"credential" binding:"required" swaggertype:"object"`
+	TrustDevice bool            `json:"trust_device"`
+}
+
+func (l *LoginPasskeyFinishRequest) Sanitize() map[string]any {
+	return map[string]any{
+		"mfa_token":  l.MFAToken,
+		"session_id": l.SessionID,
+	}
+}
@@ -6,3 +6,19 @@ type ProfileCreateRequest struct {
 	Gender   *string `json:"gender"`
 	Image    *string `json:"image"`
 }
+
+// ProfileUpdateRequest: field yang tidak dikirim tidak diubah, string kosong mengosongkan address.
+// Image diubah lewat upload avatar
+type ProfileUpdateRequest struct {
+	FullName *string `json:"full_name" binding:"omitnil,min=1,max=125"`
+	Address  *string `json:"address" binding:"omitnil,max=1000"`
+	Gender   *string `json:"gender" binding:"omitnil,oneof=laki-laki perempuan"`
+}
+
+func (p *ProfileUpdateRequest) Sanitize() map[string]any {
+	return map[string]any{
+		"full_name": p.FullName,
+		"address":   p.Address,
+		"gender":    p.Gender,
+	}
+}
@@ -3,7 +3,7 @@ package request
 type VerifyRegisterByAdminRequest struct {
 	Token           string `json:"token" binding:"required"`
 	Username        string `json:"username" binding:"required,excludesall= "`
-	Password        string `json:"password" binding:"required,min=6"`
+	Password        string `json:"password" binding:"required"`
 	PasswordConfirm string `json:"password_confirm" binding:"required"`
 }
 
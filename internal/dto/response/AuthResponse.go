@@ -1,6 +1,9 @@
 package response
 
+// LoginResponse ExpiresIn dan RefreshExpiresIn dalam detik, dipakai juga sebagai umur cookie
 type LoginResponse struct {
-	AccessToken  string `json:"access_token"`
-	RefreshToken string `json:"refresh_token"`
+	AccessToken      string `json:"access_token"`
+	RefreshToken     string `json:"refresh_token"`
+	ExpiresIn        int    `json:"expires_in"`
+	RefreshExpiresIn int    `json:"refresh_expires_in"`
 }
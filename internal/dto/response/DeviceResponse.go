@@ -0,0 +1,21 @@
+package response
+
+import "time"
+
+type DeviceResponse struct {
+	ID             string     `json:"id"`
+	Name           *string    `json:"name"`
+	Description    string     `json:"description"`
+	Browser        string     `json:"browser"`
+	BrowserVersion string     `json:"browser_version"`
+	OS             string     `json:"os"`
+	OSVersion      string     `json:"os_version"`
+	DeviceType     string     `json:"device_type"`
+	DeviceModel    string     `json:"device_model"`
+	LastIP         string     `json:"last_ip"`
+	Trusted        bool       `json:"trusted"`
+	TrustedUntil   *time.Time `json:"trusted_until"`
+	Current        bool       `json:"current"`
+	LastSeenAt     time.Time  `json:"last_seen_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
@@ -0,0 +1,13 @@
+package response
+
+import "time"
+
+type IdentityResponse struct {
+	Provider string    `json:"provider"`
+	Email    *string   `json:"email"`
+	LinkedAt time.Time `json:"linked_at"`
+}
+
+type IdentityLinkResponse struct {
+	AuthURL string `json:"auth_url"`
+}
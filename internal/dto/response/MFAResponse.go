@@ -0,0 +1,17 @@
+package response
+
+type MFAEnrollResponse struct {
+	Secret     string `json:"secret"`
+	OtpauthURL string `json:"otpauth_url"`
+}
+
+type MFAChallengeResponse struct {
+	MFARequired bool     `json:"mfa_required"`
+	MFAToken    string   `json:"mfa_token"`
+	ExpiresIn   int      `json:"expires_in"`
+	Methods     []string `json:"methods"`
+}
+
+type MFARecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
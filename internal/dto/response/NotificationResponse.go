@@ -0,0 +1,6 @@
+package response
+
+type NotificationPreferencesResponse struct {
+	NewDevice  bool `json:"new_device"`
+	NewNetwork bool `json:"new_network"`
+}
@@ -0,0 +1,17 @@
+package response
+
+import "time"
+
+type PasskeyBeginResponse struct {
+	SessionID string `json:"session_id"`
+	Options   any    `json:"options"`
+}
+
+type PasskeyResponse struct {
+	ID           string     `json:"id"`
+	Name         string     `json:"name"`
+	SignCount    uint32     `json:"sign_count"`
+	CloneWarning bool       `json:"clone_warning"`
+	LastUsedAt   *time.Time `json:"last_used_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
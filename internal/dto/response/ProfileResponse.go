@@ -1,5 +1,7 @@
 package response
 
+import "time"
+
 type ProfileResponse struct {
 	ID       string `json:"id"`
 	FullName string `json:"full_name"`
@@ -11,4 +13,12 @@ type ProfileDetailResponse struct {
 	Address  *string `json:"address"`
 	Gender   *string `json:"gender"`
 	Image    *string `json:"image"`
+	// ImageURLs URL avatar per ukuran (sisi persegi dalam piksel), "original" untuk image lama yang bukan hasil upload
+	ImageURLs map[string]string `json:"image_urls"`
+}
+
+type ProfileUpdateResponse struct {
+	Profile       ProfileDetailResponse `json:"profile"`
+	ChangedFields []string              `json:"changed_fields"`
+	UpdatedAt     time.Time             `json:"updated_at"`
 }
@@ -0,0 +1,29 @@
+package response
+
+import "time"
+
+// SessionResponse DeviceID adalah ID perangkat di /api/auth/devices, bukan device ID di cookie
+type SessionResponse struct {
+	ID         string    `json:"id"`
+	DeviceID   *string   `json:"device_id"`
+	DeviceName *string   `json:"device_name"`
+	Device     string    `json:"device"`
+	Browser    string    `json:"browser"`
+	OS         string    `json:"os"`
+	DeviceType string    `json:"device_type"`
+	IPAddress  string    `json:"ip_address"`
+	UserAgent  string    `json:"user_agent"`
+	Current    bool      `json:"current"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// SessionAdminResponse session untuk admin, status berisi active, expired atau revoked
+type SessionAdminResponse struct {
+	SessionResponse
+	UserID   string  `json:"user_id"`
+	Username *string `json:"username"`
+	Email    string  `json:"email"`
+	Status   string  `json:"status"`
+}
@@ -9,12 +9,12 @@ type UserResponse struct {
 }
 
 type UserDetailResponse struct {
-	ID             string  `json:"id"`
-	Username       *string `json:"username"`
-	Email          string  `json:"email"`
-	GoogleID       *string `json:"google_id"`
-	EmailVerified  bool    `json:"email_verified"`
-	CreatedByAdmin bool    `json:"created_by_admin"`
+	ID             string   `json:"id"`
+	Username       *string  `json:"username"`
+	Email          string   `json:"email"`
+	EmailVerified  bool     `json:"email_verified"`
+	CreatedByAdmin bool     `json:"created_by_admin"`
+	Providers      []string `json:"providers"`
 	Profile        ProfileDetailResponse
 	Roles          []RoleResponse `json:"roles"`
 }
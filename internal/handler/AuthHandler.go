@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/gogaruda/apperror"
 	"github.com/gogaruda/valigo"
@@ -51,6 +52,204 @@ func (h *AuthHandler) Me(c *gin.Context) {
 	res.OK(user, "query ok", nil)
 }
 
+// ChangePassword godoc
+// @Summary Ganti password
+// @Description Mengganti password user login. revoke "others" mengakhiri session di perangkat lain, "all" mengakhiri semua session termasuk saat ini
+// @Tags Auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body request.ChangePasswordRequest true "Password saat ini, password baru dan pilihan revoke"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Router /api/auth/me/password [patch]
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	res := response.NewResponder(c)
+	var req request.ChangePasswordRequest
+
+	// cek user_id dari context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		res.Unauthorized("user_id tidak ada di context")
+		return
+	}
+
+	// validasi
+	if !h.validates.ValigoJSON(c, &req) {
+		return
+	}
+
+	// validasi kecocokan password
+	errMap := make(map[string]string)
+	if req.Password != req.ConfirmPassword {
+		errMap["confirm_password"] = "konfirmasi password tidak cocok"
+	}
+	if !h.validates.ValigoBusiness(c, &req, errMap) {
+		return
+	}
+
+	// ganti password, refresh token dipakai untuk mengenali session saat ini
+	refreshToken, _ := c.Cookie("refresh_token")
+	token, err := h.authService.ChangePassword(c.Request.Context(), userID.(string), req, refreshToken, c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		if errMap := passwordPolicyErrors(err); errMap != nil {
+			h.validates.ValigoBusiness(c, &req, errMap)
+			return
+		}
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	// semua session dicabut
+	if token == nil {
+		clearTokenCookies(c, h.cfg.Cookie)
+		res.OK(nil, "password berhasil diganti, silakan login kembali", nil)
+		return
+	}
+
+	setCookie(c, h.cfg.Cookie, "access_token", token.AccessToken, token.ExpiresIn, "/")
+	res.OK(token, "password berhasil diganti", nil)
+}
+
+// UsernameChange godoc
+// @Summary Ganti username
+// @Description Mengganti username user login, username lama masuk riwayat dan tidak bisa dipakai lagi
+// @Tags Auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body request.UserUpdateUsernameRequest true "Username baru"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
+// @Failure 429 {object} response.APIResponse
+// @Router /api/auth/me/username [patch]
+func (h *AuthHandler) UsernameChange(c *gin.Context) {
+	res := response.NewResponder(c)
+	var req request.UserUpdateUsernameRequest
+
+	// cek user_id dari context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		res.Unauthorized("user_id tidak ada di context")
+		return
+	}
+
+	// validasi
+	if !h.validates.ValigoJSON(c, &req) {
+		return
+	}
+
+	// ganti username
+	usernameUpdate, err := h.userService.UsernameUpdate(c.Request.Context(), userID.(string), req.Username, false)
+	if err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+	if !usernameUpdate {
+		res.OK(nil, "tidak ada perubahan username", nil)
+		return
+	}
+
+	res.OK(nil, "username berhasil diganti", nil)
+}
+
+// EmailChange godoc
+// @Summary Ganti email
+// @Description Meminta ganti email user login. Link konfirmasi dikirim ke email baru dan link pembatalan ke email lama, email lama tetap dipakai login sampai dikonfirmasi
+// @Tags Auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body request.EmailChangeRequest true "Email baru dan password saat ini"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
+// @Router /api/auth/me/email [post]
+func (h *AuthHandler) EmailChange(c *gin.Context) {
+	res := response.NewResponder(c)
+	var req request.EmailChangeRequest
+
+	// cek user_id dari context
+	userID, exists := c.Get("user_id")
+	if !exists {
+		res.Unauthorized("user_id tidak ada di context")
+		return
+	}
+
+	// validasi
+	if !h.validates.ValigoJSON(c, &req) {
+		return
+	}
+
+	// kirim konfirmasi
+	if err := h.authService.EmailChange(c.Request.Context(), userID.(string), req, c.ClientIP()); err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(nil, "link konfirmasi sudah dikirim ke email baru", nil)
+}
+
+// EmailChangeConfirm godoc
+// @Summary Konfirmasi ganti email
+// @Description Mengganti email user dengan token dari email baru
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body request.VerifyRequest true "Token dari email baru"
+// @Success 200 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
+// @Router /api/auth/email-change/confirm [post]
+func (h *AuthHandler) EmailChangeConfirm(c *gin.Context) {
+	res := response.NewResponder(c)
+	var req request.VerifyRequest
+
+	// validasi
+	if !h.validates.ValigoJSON(c, &req) {
+		return
+	}
+
+	// ganti email
+	if err := h.authService.EmailChangeConfirm(c.Request.Context(), req); err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(nil, "email berhasil diganti", nil)
+}
+
+// EmailChangeRevert godoc
+// @Summary Batalkan ganti email
+// @Description Membatalkan atau mengembalikan perubahan email dengan token dari email lama, lalu mencabut semua session user
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body request.VerifyRequest true "Token dari email lama"
+// @Success 200 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Router /api/auth/email-change/revert [post]
+func (h *AuthHandler) EmailChangeRevert(c *gin.Context) {
+	res := response.NewResponder(c)
+	var req request.VerifyRequest
+
+	// validasi
+	if !h.validates.ValigoJSON(c, &req) {
+		return
+	}
+
+	// kembalikan email
+	if err := h.authService.EmailChangeRevert(c.Request.Context(), req); err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(nil, "perubahan email dibatalkan, silakan login kembali dan ganti password", nil)
+}
+
 // Login godoc
 // @Summary Login user
 // @Description Login user dan generate token JWT
@@ -58,8 +257,11 @@ func (h *AuthHandler) Me(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param request body request.LoginRequest true "Login payload"
+// @Param X-Challenge-Token header string false "Token challenge dari /api/auth/challenge"
+// @Param X-Challenge-Response header string false "Jawaban challenge"
 // @Success 200 {object} response.APIResponse
 // @Failure 400 {object} response.APIResponse
+// @Failure 428 {object} response.APIResponse
 // @Router /api/auth/login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
 	res := response.NewResponder(c)
@@ -71,14 +273,335 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	}
 
 	// login
-	token, err := h.authService.Login(c.Request.Context(), req, c.Request.UserAgent(), c.ClientIP())
+	token, challenge, err := h.authService.Login(c.Request.Context(), req, c.GetString("device_id"), c.Request.UserAgent(), c.ClientIP())
 	if err != nil {
 		apperror.HandleHTTPError(c, err)
 		return
 	}
 
-	c.SetCookie("access_token", token.AccessToken, 900, "/", "", false, true)
-	c.SetCookie("refresh_token", token.RefreshToken, 7*24*3600, "/", "", false, true)
+	// MFA aktif, token baru diberikan setelah verifikasi kode
+	if challenge != nil {
+		res.OK(challenge, "verifikasi MFA diperlukan", nil)
+		return
+	}
+
+	setTokenCookies(c, h.cfg.Cookie, token)
+	res.OK(token, "login berhasil", nil)
+}
+
+// MagicLink godoc
+// @Summary Minta link login via email
+// @Description Mengirim link login sekali pakai ke email. Respon selalu sama baik email terdaftar atau tidak
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body request.MagicLinkRequest true "Email tujuan"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 429 {object} response.APIResponse
+// @Router /api/auth/magic-link [post]
+func (h *AuthHandler) MagicLink(c *gin.Context) {
+	res := response.NewResponder(c)
+	var req request.MagicLinkRequest
+
+	// validasi
+	if !h.validates.ValigoJSON(c, &req) {
+		return
+	}
+
+	// kirim link
+	if err := h.authService.MagicLink(c.Request.Context(), req); err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(nil, "jika email terdaftar, link login sudah dikirim", nil)
+}
+
+// LoginMagicLink godoc
+// @Summary Login dengan magic link
+// @Description Menukar token magic link sekali pakai dengan access token dan refresh token, atau challenge MFA jika aktif
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body request.MagicLinkLoginRequest true "Token dari email"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Router /api/auth/magic-link/login [post]
+func (h *AuthHandler) LoginMagicLink(c *gin.Context) {
+	res := response.NewResponder(c)
+	var req request.MagicLinkLoginRequest
+
+	// validasi
+	if !h.validates.ValigoJSON(c, &req) {
+		return
+	}
+
+	// login
+	token, challenge, err := h.authService.LoginMagicLink(c.Request.Context(), req, c.GetString("device_id"), c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	// MFA aktif, token baru diberikan setelah verifikasi kode
+	if challenge != nil {
+		res.OK(challenge, "verifikasi MFA diperlukan", nil)
+		return
+	}
+
+	setTokenCookies(c, h.cfg.Cookie, token)
+	res.OK(token, "login berhasil", nil)
+}
+
+// ForgotPassword godoc
+// @Summary Lupa password
+// @Description Mengirim link reset password ke email. Respon selalu sama baik email terdaftar atau tidak
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body request.ForgotPasswordRequest true "Email akun"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 429 {object} response.APIResponse
+// @Router /api/auth/forgot-password [post]
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	res := response.NewResponder(c)
+	var req request.ForgotPasswordRequest
+
+	// validasi
+	if !h.validates.ValigoJSON(c, &req) {
+		return
+	}
+
+	// kirim link reset
+	if err := h.authService.ForgotPassword(c.Request.Context(), req); err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(nil, "jika email terdaftar, link reset password sudah dikirim", nil)
+}
+
+// ResetPassword godoc
+// @Summary Reset password
+// @Description Mengganti password dengan token dari email lalu mencabut semua session user
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body request.ResetPasswordRequest true "Token dan password baru"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Router /api/auth/reset-password [post]
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	res := response.NewResponder(c)
+	var req request.ResetPasswordRequest
+
+	// validasi
+	if !h.validates.ValigoJSON(c, &req) {
+		return
+	}
+
+	// validasi kecocokan password
+	errMap := make(map[string]string)
+	if req.Password != req.ConfirmPassword {
+		errMap["confirm_password"] = "konfirmasi password tidak cocok"
+	}
+	if !h.validates.ValigoBusiness(c, &req, errMap) {
+		return
+	}
+
+	// reset password
+	if err := h.authService.ResetPassword(c.Request.Context(), req, c.Request.UserAgent(), c.ClientIP()); err != nil {
+		if errMap := passwordPolicyErrors(err); errMap != nil {
+			h.validates.ValigoBusiness(c, &req, errMap)
+			return
+		}
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(nil, "password berhasil direset, silakan login kembali", nil)
+}
+
+// LoginMFA godoc
+// @Summary Login tahap kedua dengan kode MFA
+// @Description Menukar token challenge MFA dan kode TOTP dengan access token dan refresh token
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body request.LoginMFARequest true "Challenge token dan kode MFA"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Router /api/auth/login/mfa [post]
+func (h *AuthHandler) LoginMFA(c *gin.Context) {
+	res := response.NewResponder(c)
+	var req request.LoginMFARequest
+
+	// validasi
+	if !h.validates.ValigoJSON(c, &req) {
+		return
+	}
+
+	// verifikasi kode MFA
+	token, err := h.authService.LoginMFA(c.Request.Context(), req, c.GetString("device_id"), c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	setTokenCookies(c, h.cfg.Cookie, token)
+	res.OK(token, "login berhasil", nil)
+}
+
+// LoginRecovery godoc
+// @Summary Login tahap kedua dengan recovery code
+// @Description Menukar token challenge MFA dan recovery code sekali pakai dengan access token dan refresh token
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body request.LoginRecoveryRequest true "Challenge token dan recovery code"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Router /api/auth/login/recovery [post]
+func (h *AuthHandler) LoginRecovery(c *gin.Context) {
+	res := response.NewResponder(c)
+	var req request.LoginRecoveryRequest
+
+	// validasi
+	if !h.validates.ValigoJSON(c, &req) {
+		return
+	}
+
+	// verifikasi recovery code
+	token, err := h.authService.LoginRecovery(c.Request.Context(), req, c.GetString("device_id"), c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	setTokenCookies(c, h.cfg.Cookie, token)
+	res.OK(token, "login berhasil", nil)
+}
+
+// LoginPasskeyBegin godoc
+// @Summary Mulai login dengan passkey
+// @Description Membuat opsi login WebAuthn tanpa username untuk navigator.credentials.get()
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.APIResponse
+// @Failure 429 {object} response.APIResponse
+// @Router /api/auth/passkeys/login/begin [post]
+func (h *AuthHandler) LoginPasskeyBegin(c *gin.Context) {
+	res := response.NewResponder(c)
+
+	// mulai login passkey
+	options, err := h.authService.LoginPasskeyBegin(c.Request.Context())
+	if err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(options, "lanjutkan login passkey di perangkat", nil)
+}
+
+// LoginPasskey godoc
+// @Summary Login dengan passkey
+// @Description Memverifikasi assertion passkey lalu membuat access token dan refresh token
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body request.PasskeyLoginFinishRequest true "Session dan credential dari authenticator"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Failure 429 {object} response.APIResponse
+// @Router /api/auth/passkeys/login/finish [post]
+func (h *AuthHandler) LoginPasskey(c *gin.Context) {
+	res := response.NewResponder(c)
+	var req request.PasskeyLoginFinishRequest
+
+	// validasi
+	if !h.validates.ValigoJSON(c, &req) {
+		return
+	}
+
+	// verifikasi passkey
+	token, err := h.authService.LoginPasskey(c.Request.Context(), req, c.GetString("device_id"), c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	setTokenCookies(c, h.cfg.Cookie, token)
+	res.OK(token, "login berhasil", nil)
+}
+
+// LoginMFAPasskeyBegin godoc
+// @Summary Mulai login tahap kedua dengan passkey
+// @Description Membuat opsi verifikasi WebAuthn untuk user pada token challenge MFA
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body request.LoginPasskeyBeginRequest true "Challenge token MFA"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Failure 429 {object} response.APIResponse
+// @Router /api/auth/login/passkey/begin [post]
+func (h *AuthHandler) LoginMFAPasskeyBegin(c *gin.Context) {
+	res := response.NewResponder(c)
+	var req request.LoginPasskeyBeginRequest
+
+	// validasi
+	if !h.validates.ValigoJSON(c, &req) {
+		return
+	}
+
+	// mulai verifikasi passkey
+	options, err := h.authService.LoginMFAPasskeyBegin(c.Request.Context(), req)
+	if err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(options, "lanjutkan verifikasi passkey di perangkat", nil)
+}
+
+// LoginMFAPasskey godoc
+// @Summary Login tahap kedua dengan passkey
+// @Description Menukar token challenge MFA dan assertion passkey dengan access token dan refresh token
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body request.LoginPasskeyFinishRequest true "Challenge token, session dan credential dari authenticator"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Failure 429 {object} response.APIResponse
+// @Router /api/auth/login/passkey/finish [post]
+func (h *AuthHandler) LoginMFAPasskey(c *gin.Context) {
+	res := response.NewResponder(c)
+	var req request.LoginPasskeyFinishRequest
+
+	// validasi
+	if !h.validates.ValigoJSON(c, &req) {
+		return
+	}
+
+	// verifikasi passkey
+	token, err := h.authService.LoginMFAPasskey(c.Request.Context(), req, c.GetString("device_id"), c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	setTokenCookies(c, h.cfg.Cookie, token)
 	res.OK(token, "login berhasil", nil)
 }
 
@@ -110,8 +633,7 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 	}
 
 	// hapus cookie
-	c.SetCookie("access_token", "", -1, "/", "", true, true)
-	c.SetCookie("refresh_token", "", -1, "/", "", true, true)
+	clearTokenCookies(c, h.cfg.Cookie)
 
 	res.OK(nil, "Logout berhasil", nil)
 }
@@ -143,8 +665,7 @@ func (h *AuthHandler) LogoutAll(c *gin.Context) {
 	}
 
 	// hapus cookie
-	c.SetCookie("access_token", "", -1, "/", "", true, true)
-	c.SetCookie("refresh_token", "", -1, "/", "", true, true)
+	clearTokenCookies(c, h.cfg.Cookie)
 
 	res.OK(nil, "Logout dari semua device berhasil", nil)
 }
@@ -156,8 +677,11 @@ func (h *AuthHandler) LogoutAll(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param request body request.RegisterRequest true "Data registrasi user baru"
+// @Param X-Challenge-Token header string false "Token challenge dari /api/auth/challenge"
+// @Param X-Challenge-Response header string false "Jawaban challenge"
 // @Success 200 {object} response.APIResponse
 // @Failure 400 {object} response.APIResponse
+// @Failure 428 {object} response.APIResponse
 // @Router /api/auth/register [post]
 func (h *AuthHandler) Register(c *gin.Context) {
 	res := response.NewResponder(c)
@@ -179,10 +703,24 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	// registrasi
 	token, err := h.authService.Register(c.Request.Context(), req)
 	if err != nil {
+		if errMap := passwordPolicyErrors(err); errMap != nil {
+			h.validates.ValigoBusiness(c, &req, errMap)
+			return
+		}
 		apperror.HandleHTTPError(c, err)
 		return
 	}
 
-	c.SetCookie("verify_email", token, 1800, "/", "", false, true)
+	setCookie(c, h.cfg.Cookie, "verify_email", token, 1800, "/")
 	res.OK(token, "registrasi berhasil", nil)
 }
+
+// passwordPolicyErrors mengembalikan error field password jika err berasal dari password policy
+func passwordPolicyErrors(err error) map[string]string {
+	var appErr *apperror.InitError
+	if errors.As(err, &appErr) && appErr.Code == "[PASSWORD_POLICY]" {
+		return map[string]string{"password": appErr.Message}
+	}
+
+	return nil
+}
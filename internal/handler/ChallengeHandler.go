@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/irawankilmer/auth-service/pkg/challenge"
+	"github.com/irawankilmer/auth-service/pkg/response"
+)
+
+type ChallengeHandler struct {
+	challenger challenge.Challenger
+}
+
+func NewChallengeHandler(ch challenge.Challenger) *ChallengeHandler {
+	return &ChallengeHandler{challenger: ch}
+}
+
+// Issue godoc
+// @Summary Ambil challenge
+// @Description Membuat challenge untuk login/register setelah terlalu banyak kegagalan. Jawaban dikirim lewat header X-Challenge-Token dan X-Challenge-Response.
+// @Description Untuk pow cari X-Challenge-Response sehingga SHA-256(token + ":" + response) diawali difficulty bit nol, untuk captcha kirim token widget sebagai X-Challenge-Response
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} response.APIResponse
+// @Router /api/auth/challenge [get]
+func (h *ChallengeHandler) Issue(c *gin.Context) {
+	res := response.NewResponder(c)
+
+	ch, err := h.challenger.Issue(c.Request.Context(), c.ClientIP())
+	if err != nil {
+		res.ServerError("gagal membuat challenge")
+		return
+	}
+
+	res.OK(ch, "challenge dibuat", nil)
+}
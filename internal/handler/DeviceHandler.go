@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gogaruda/apperror"
+	"github.com/gogaruda/valigo"
+	"github.com/irawankilmer/auth-service/internal/dto/request"
+	"github.com/irawankilmer/auth-service/internal/service"
+	"github.com/irawankilmer/auth-service/pkg/response"
+)
+
+type DeviceHandler struct {
+	deviceService service.DeviceService
+	validates     *valigo.Valigo
+}
+
+func NewDeviceHandler(ds service.DeviceService, v *valigo.Valigo) *DeviceHandler {
+	return &DeviceHandler{deviceService: ds, validates: v}
+}
+
+// List godoc
+// @Summary Daftar perangkat
+// @Description Menampilkan perangkat yang pernah dipakai login oleh user login, perangkat saat ini ditandai current
+// @Tags Devices
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Router /api/auth/devices [get]
+func (h *DeviceHandler) List(c *gin.Context) {
+	res := response.NewResponder(c)
+
+	// ambil user_id dari middleware JWT
+	userID, exists := c.Get("user_id")
+	if !exists {
+		res.Unauthorized("user_id tidak ditemukan di context")
+		return
+	}
+
+	devices, err := h.deviceService.List(c.Request.Context(), userID.(string), c.GetString("device_id"))
+	if err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(devices, "query ok", nil)
+}
+
+// Rename godoc
+// @Summary Ubah nama perangkat
+// @Description Memberi nama perangkat milik user login, nama kosong menghapus nama
+// @Tags Devices
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "ID perangkat"
+// @Param request body request.DeviceRenameRequest true "Nama baru"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Router /api/auth/devices/{id} [patch]
+func (h *DeviceHandler) Rename(c *gin.Context) {
+	res := response.NewResponder(c)
+	var req request.DeviceRenameRequest
+
+	// ambil user_id dari middleware JWT
+	userID, exists := c.Get("user_id")
+	if !exists {
+		res.Unauthorized("user_id tidak ditemukan di context")
+		return
+	}
+
+	// validasi
+	if !h.validates.ValigoJSON(c, &req) {
+		return
+	}
+
+	// ubah nama
+	if err := h.deviceService.Rename(c.Request.Context(), userID.(string), c.Param("id"), req); err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(nil, "nama perangkat berhasil diubah", nil)
+}
+
+// Untrust godoc
+// @Summary Cabut perangkat tepercaya
+// @Description Perangkat kembali diminta MFA saat login berikutnya
+// @Tags Devices
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "ID perangkat"
+// @Success 200 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Router /api/auth/devices/{id}/trust [delete]
+func (h *DeviceHandler) Untrust(c *gin.Context) {
+	res := response.NewResponder(c)
+
+	// ambil user_id dari middleware JWT
+	userID, exists := c.Get("user_id")
+	if !exists {
+		res.Unauthorized("user_id tidak ditemukan di context")
+		return
+	}
+
+	if err := h.deviceService.Untrust(c.Request.Context(), userID.(string), c.Param("id")); err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(nil, "perangkat tidak lagi tepercaya", nil)
+}
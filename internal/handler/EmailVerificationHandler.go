@@ -4,6 +4,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/gogaruda/apperror"
 	"github.com/gogaruda/valigo"
+	"github.com/irawankilmer/auth-service/internal/configs"
 	"github.com/irawankilmer/auth-service/internal/dto/request"
 	"github.com/irawankilmer/auth-service/internal/service"
 	"github.com/irawankilmer/auth-service/pkg/response"
@@ -13,10 +14,11 @@ import (
 type EmailVerificationHandler struct {
 	evService service.EmailVerificationService
 	validates *valigo.Valigo
+	cfg       *configs.AppConfig
 }
 
-func NewEmailVerificationHandler(ev service.EmailVerificationService, v *valigo.Valigo) *EmailVerificationHandler {
-	return &EmailVerificationHandler{evService: ev, validates: v}
+func NewEmailVerificationHandler(ev service.EmailVerificationService, v *valigo.Valigo, cfg *configs.AppConfig) *EmailVerificationHandler {
+	return &EmailVerificationHandler{evService: ev, validates: v, cfg: cfg}
 }
 
 // VerifyEmail godoc
@@ -80,7 +82,7 @@ func (h *EmailVerificationHandler) VerifyRegisterResend(c *gin.Context) {
 		return
 	}
 
-	c.SetCookie("verify_email", newToken, 1800, "/", "", false, true)
+	setCookie(c, h.cfg.Cookie, "verify_email", newToken, 1800, "/")
 	res.OK(newToken, "verifikasi email sudah dirikim ulang", nil)
 }
 
@@ -123,6 +125,10 @@ func (h *EmailVerificationHandler) VerifyRegisterByAdmin(c *gin.Context) {
 
 	// update data registrasi
 	if err := h.evService.UpdateRegisterByAdmin(ctx, &req, ev); err != nil {
+		if errMap := passwordPolicyErrors(err); errMap != nil {
+			h.validates.ValigoBusiness(c, &req, errMap)
+			return
+		}
 		apperror.HandleHTTPError(c, err)
 		return
 	}
@@ -165,6 +171,6 @@ func (h *EmailVerificationHandler) VerifyRegisterByAdminResend(c *gin.Context) {
 		return
 	}
 
-	c.SetCookie("verify_email", newToken, 1800, "/", "", false, true)
+	setCookie(c, h.cfg.Cookie, "verify_email", newToken, 1800, "/")
 	res.OK(newToken, "verifikasi email sudah dirikim ulang", nil)
 }
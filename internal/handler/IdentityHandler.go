@@ -0,0 +1,208 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gogaruda/apperror"
+	"github.com/irawankilmer/auth-service/internal/configs"
+	"github.com/irawankilmer/auth-service/internal/service"
+	"github.com/irawankilmer/auth-service/pkg/response"
+	"net/http"
+)
+
+// cookie state login provider eksternal, path dibatasi ke endpoint callback
+const (
+	identityStateCookie = "oauth_state"
+	identityStatePath   = "/api/auth/oauth"
+)
+
+type IdentityHandler struct {
+	identityService service.IdentityService
+	authService     service.AuthService
+	cfg             *configs.AppConfig
+}
+
+func NewIdentityHandler(is service.IdentityService, as service.AuthService, cfg *configs.AppConfig) *IdentityHandler {
+	return &IdentityHandler{identityService: is, authService: as, cfg: cfg}
+}
+
+// setStateCookie callback provider adalah navigasi dari situs lain, cookie SameSite=Strict tidak akan terkirim
+// sehingga strict diturunkan ke lax khusus cookie state
+func (h *IdentityHandler) setStateCookie(c *gin.Context, state string, maxAge int) {
+	cfg := h.cfg.Cookie
+	if cfg.SameSite == http.SameSiteStrictMode {
+		cfg.SameSite = http.SameSiteLaxMode
+	}
+
+	setCookie(c, cfg, identityStateCookie, state, maxAge, identityStatePath)
+}
+
+// Redirect godoc
+// @Summary Login dengan provider eksternal
+// @Description Redirect ke halaman login provider (google, microsoft, github) dengan state, nonce dan PKCE
+// @Tags Identity
+// @Produce json
+// @Param provider path string true "Nama provider"
+// @Success 302
+// @Failure 404 {object} response.APIResponse
+// @Router /api/auth/oauth/{provider} [get]
+func (h *IdentityHandler) Redirect(c *gin.Context) {
+	// buat URL otorisasi provider
+	authURL, state, err := h.identityService.AuthURL(c.Param("provider"))
+	if err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	// state, nonce dan PKCE verifier disimpan terenkripsi di cookie
+	h.setStateCookie(c, state, int(h.cfg.Identity.StateTTL.Seconds()))
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// Callback godoc
+// @Summary Callback provider eksternal
+// @Description Memverifikasi callback provider lalu login (token atau challenge MFA) atau menghubungkan akun
+// @Tags Identity
+// @Produce json
+// @Param provider path string true "Nama provider"
+// @Param state query string true "State dari provider"
+// @Param code query string true "Authorization code dari provider"
+// @Success 200 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
+// @Router /api/auth/oauth/{provider}/callback [get]
+func (h *IdentityHandler) Callback(c *gin.Context) {
+	res := response.NewResponder(c)
+	provider := c.Param("provider")
+
+	// cookie state hanya dipakai sekali
+	state, err := c.Cookie(identityStateCookie)
+	h.setStateCookie(c, "", -1)
+	if err != nil || state == "" {
+		res.Unauthorized("state login tidak ditemukan, silakan ulangi")
+		return
+	}
+
+	// user membatalkan login di halaman provider
+	if c.Query("error") != "" {
+		res.Unauthorized("login " + provider + " dibatalkan")
+		return
+	}
+
+	// verifikasi callback
+	user, linked, err := h.identityService.Callback(c.Request.Context(), provider, state, c.Query("state"), c.Query("code"))
+	if err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+	if linked {
+		res.OK(nil, "akun "+provider+" berhasil dihubungkan", nil)
+		return
+	}
+
+	// login
+	token, challenge, err := h.authService.LoginIdentity(c.Request.Context(), user, provider, c.GetString("device_id"), c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	// MFA aktif, token baru diberikan setelah verifikasi kode
+	if challenge != nil {
+		res.OK(challenge, "verifikasi MFA diperlukan", nil)
+		return
+	}
+
+	setTokenCookies(c, h.cfg.Cookie, token)
+	res.OK(token, "login berhasil", nil)
+}
+
+// List godoc
+// @Summary Daftar akun eksternal
+// @Description Menampilkan akun provider eksternal yang terhubung ke user login
+// @Tags Identity
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Router /api/auth/identities [get]
+func (h *IdentityHandler) List(c *gin.Context) {
+	res := response.NewResponder(c)
+
+	// ambil user_id dari middleware JWT
+	userID, exists := c.Get("user_id")
+	if !exists {
+		res.Unauthorized("user_id tidak ditemukan di context")
+		return
+	}
+
+	// ambil akun terhubung
+	identities, err := h.identityService.List(c.Request.Context(), userID.(string))
+	if err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(identities, "query ok", nil)
+}
+
+// Link godoc
+// @Summary Hubungkan akun eksternal
+// @Description Membuat URL otorisasi provider untuk menghubungkan akun ke user login, callback memakai endpoint yang sama dengan login
+// @Tags Identity
+// @Security BearerAuth
+// @Produce json
+// @Param provider path string true "Nama provider"
+// @Success 200 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Router /api/auth/identities/{provider} [post]
+func (h *IdentityHandler) Link(c *gin.Context) {
+	res := response.NewResponder(c)
+
+	// ambil user_id dari middleware JWT
+	userID, exists := c.Get("user_id")
+	if !exists {
+		res.Unauthorized("user_id tidak ditemukan di context")
+		return
+	}
+
+	// buat URL otorisasi provider
+	link, state, err := h.identityService.LinkURL(c.Param("provider"), userID.(string))
+	if err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	h.setStateCookie(c, state, int(h.cfg.Identity.StateTTL.Seconds()))
+	res.OK(link, "lanjutkan ke halaman login provider", nil)
+}
+
+// Unlink godoc
+// @Summary Putuskan akun eksternal
+// @Description Memutus akun provider dari user login, ditolak jika akun tersebut metode login terakhir
+// @Tags Identity
+// @Security BearerAuth
+// @Produce json
+// @Param provider path string true "Nama provider"
+// @Success 200 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
+// @Router /api/auth/identities/{provider} [delete]
+func (h *IdentityHandler) Unlink(c *gin.Context) {
+	res := response.NewResponder(c)
+
+	// ambil user_id dari middleware JWT
+	userID, exists := c.Get("user_id")
+	if !exists {
+		res.Unauthorized("user_id tidak ditemukan di context")
+		return
+	}
+
+	// putuskan akun
+	if err := h.identityService.Unlink(c.Request.Context(), userID.(string), c.Param("provider")); err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(nil, "akun "+c.Param("provider")+" berhasil diputus", nil)
+}
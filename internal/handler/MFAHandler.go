@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gogaruda/apperror"
+	"github.com/gogaruda/valigo"
+	"github.com/irawankilmer/auth-service/internal/dto/request"
+	"github.com/irawankilmer/auth-service/internal/service"
+	"github.com/irawankilmer/auth-service/pkg/response"
+)
+
+type MFAHandler struct {
+	mfaService service.MFAService
+	validates  *valigo.Valigo
+}
+
+func NewMFAHandler(ms service.MFAService, v *valigo.Valigo) *MFAHandler {
+	return &MFAHandler{mfaService: ms, validates: v}
+}
+
+// Enroll godoc
+// @Summary Daftarkan MFA (TOTP)
+// @Description Membuat secret TOTP baru dan otpauth URL untuk aplikasi authenticator
+// @Tags MFA
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
+// @Failure 429 {object} response.APIResponse
+// @Router /api/auth/mfa/enroll [post]
+func (h *MFAHandler) Enroll(c *gin.Context) {
+	res := response.NewResponder(c)
+
+	// ambil user_id dari middleware JWT
+	userID, exists := c.Get("user_id")
+	if !exists {
+		res.Unauthorized("user_id tidak ditemukan di context")
+		return
+	}
+
+	// daftarkan MFA
+	enroll, err := h.mfaService.Enroll(c.Request.Context(), userID.(string))
+	if err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(enroll, "scan QR code lalu konfirmasi dengan kode dari aplikasi authenticator", nil)
+}
+
+// Confirm godoc
+// @Summary Konfirmasi pendaftaran MFA
+// @Description Mengaktifkan MFA setelah kode TOTP pertama diverifikasi dan mengembalikan recovery code
+// @Tags MFA
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body request.MFACodeRequest true "Kode TOTP"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Failure 423 {object} response.APIResponse
+// @Failure 429 {object} response.APIResponse
+// @Router /api/auth/mfa/confirm [post]
+func (h *MFAHandler) Confirm(c *gin.Context) {
+	res := response.NewResponder(c)
+	var req request.MFACodeRequest
+
+	// ambil user_id dari middleware JWT
+	userID, exists := c.Get("user_id")
+	if !exists {
+		res.Unauthorized("user_id tidak ditemukan di context")
+		return
+	}
+
+	// validasi
+	if !h.validates.ValigoJSON(c, &req) {
+		return
+	}
+
+	// konfirmasi MFA
+	codes, err := h.mfaService.Confirm(c.Request.Context(), userID.(string), req.Code, c.ClientIP())
+	if err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(codes, "MFA berhasil diaktifkan, simpan recovery code ini karena hanya ditampilkan sekali", nil)
+}
+
+// Disable godoc
+// @Summary Nonaktifkan MFA
+// @Description Menonaktifkan MFA dengan password dan kode TOTP yang valid
+// @Tags MFA
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body request.MFADisableRequest true "Password dan kode TOTP"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Failure 423 {object} response.APIResponse
+// @Failure 429 {object} response.APIResponse
+// @Router /api/auth/mfa/disable [post]
+func (h *MFAHandler) Disable(c *gin.Context) {
+	res := response.NewResponder(c)
+	var req request.MFADisableRequest
+
+	// ambil user_id dari middleware JWT
+	userID, exists := c.Get("user_id")
+	if !exists {
+		res.Unauthorized("user_id tidak ditemukan di context")
+		return
+	}
+
+	// validasi
+	if !h.validates.ValigoJSON(c, &req) {
+		return
+	}
+
+	// nonaktifkan MFA
+	if err := h.mfaService.Disable(c.Request.Context(), userID.(string), req.Code, req.Password, c.ClientIP()); err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(nil, "MFA berhasil dinonaktifkan", nil)
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary Buat ulang recovery code MFA
+// @Description Membuat recovery code baru dengan password dan kode TOTP yang valid, recovery code lama tidak berlaku lagi
+// @Tags MFA
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body request.MFARecoveryCodesRequest true "Password dan kode TOTP"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Failure 423 {object} response.APIResponse
+// @Failure 429 {object} response.APIResponse
+// @Router /api/auth/mfa/recovery-codes [post]
+func (h *MFAHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	res := response.NewResponder(c)
+	var req request.MFARecoveryCodesRequest
+
+	// ambil user_id dari middleware JWT
+	userID, exists := c.Get("user_id")
+	if !exists {
+		res.Unauthorized("user_id tidak ditemukan di context")
+		return
+	}
+
+	// validasi
+	if !h.validates.ValigoJSON(c, &req) {
+		return
+	}
+
+	// buat ulang recovery code
+	codes, err := h.mfaService.RegenerateRecoveryCodes(c.Request.Context(), userID.(string), req.Code, req.Password, c.ClientIP())
+	if err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(codes, "recovery code berhasil dibuat ulang, simpan karena hanya ditampilkan sekali", nil)
+}
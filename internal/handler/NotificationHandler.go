@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gogaruda/apperror"
+	"github.com/gogaruda/valigo"
+	"github.com/irawankilmer/auth-service/internal/dto/request"
+	"github.com/irawankilmer/auth-service/internal/service"
+	"github.com/irawankilmer/auth-service/pkg/response"
+)
+
+type NotificationHandler struct {
+	notifyService service.LoginNotificationService
+	validates     *valigo.Valigo
+}
+
+func NewNotificationHandler(ln service.LoginNotificationService, v *valigo.Valigo) *NotificationHandler {
+	return &NotificationHandler{notifyService: ln, validates: v}
+}
+
+// Preferences godoc
+// @Summary Preferensi notifikasi
+// @Description Menampilkan notifikasi keamanan yang aktif untuk user login (login dari perangkat baru dan jaringan baru)
+// @Tags Notifications
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Router /api/auth/me/notifications [get]
+func (h *NotificationHandler) Preferences(c *gin.Context) {
+	res := response.NewResponder(c)
+
+	// ambil user_id dari middleware JWT
+	userID, exists := c.Get("user_id")
+	if !exists {
+		res.Unauthorized("user_id tidak ditemukan di context")
+		return
+	}
+
+	prefs, err := h.notifyService.Preferences(c.Request.Context(), userID.(string))
+	if err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(prefs, "query ok", nil)
+}
+
+// UpdatePreferences godoc
+// @Summary Perbarui preferensi notifikasi
+// @Description Mengaktifkan atau mematikan notifikasi per jenis. Jenis yang tidak dikirim tidak diubah
+// @Tags Notifications
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body request.NotificationPreferencesRequest true "Preferensi notifikasi"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Router /api/auth/me/notifications [patch]
+func (h *NotificationHandler) UpdatePreferences(c *gin.Context) {
+	res := response.NewResponder(c)
+	var req request.NotificationPreferencesRequest
+
+	// ambil user_id dari middleware JWT
+	userID, exists := c.Get("user_id")
+	if !exists {
+		res.Unauthorized("user_id tidak ditemukan di context")
+		return
+	}
+
+	// validasi
+	if !h.validates.ValigoJSON(c, &req) {
+		return
+	}
+
+	prefs, err := h.notifyService.UpdatePreferences(c.Request.Context(), userID.(string), req)
+	if err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(prefs, "preferensi notifikasi berhasil di update", nil)
+}
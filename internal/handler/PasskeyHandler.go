@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gogaruda/apperror"
+	"github.com/gogaruda/valigo"
+	"github.com/irawankilmer/auth-service/internal/dto/request"
+	"github.com/irawankilmer/auth-service/internal/service"
+	"github.com/irawankilmer/auth-service/pkg/response"
+)
+
+type PasskeyHandler struct {
+	waService service.WebAuthnService
+	validates *valigo.Valigo
+}
+
+func NewPasskeyHandler(ws service.WebAuthnService, v *valigo.Valigo) *PasskeyHandler {
+	return &PasskeyHandler{waService: ws, validates: v}
+}
+
+// RegisterBegin godoc
+// @Summary Mulai registrasi passkey
+// @Description Membuat opsi registrasi WebAuthn untuk navigator.credentials.create()
+// @Tags Passkey
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Router /api/auth/passkeys/register/begin [post]
+func (h *PasskeyHandler) RegisterBegin(c *gin.Context) {
+	res := response.NewResponder(c)
+
+	// ambil user_id dari middleware JWT
+	userID, exists := c.Get("user_id")
+	if !exists {
+		res.Unauthorized("user_id tidak ditemukan di context")
+		return
+	}
+
+	// mulai registrasi
+	options, err := h.waService.BeginRegistration(c.Request.Context(), userID.(string))
+	if err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(options, "lanjutkan registrasi passkey di perangkat", nil)
+}
+
+// RegisterFinish godoc
+// @Summary Selesaikan registrasi passkey
+// @Description Memverifikasi respon authenticator lalu menyimpan passkey
+// @Tags Passkey
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body request.PasskeyRegisterFinishRequest true "Session, nama dan credential dari authenticator"
+// @Success 201 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Router /api/auth/passkeys/register/finish [post]
+func (h *PasskeyHandler) RegisterFinish(c *gin.Context) {
+	res := response.NewResponder(c)
+	var req request.PasskeyRegisterFinishRequest
+
+	// ambil user_id dari middleware JWT
+	userID, exists := c.Get("user_id")
+	if !exists {
+		res.Unauthorized("user_id tidak ditemukan di context")
+		return
+	}
+
+	// validasi
+	if !h.validates.ValigoJSON(c, &req) {
+		return
+	}
+
+	// simpan passkey
+	passkey, err := h.waService.FinishRegistration(c.Request.Context(), userID.(string), req)
+	if err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.Created(passkey, "passkey berhasil didaftarkan")
+}
+
+// List godoc
+// @Summary Daftar passkey
+// @Description Menampilkan semua passkey milik user login
+// @Tags Passkey
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Router /api/auth/passkeys [get]
+func (h *PasskeyHandler) List(c *gin.Context) {
+	res := response.NewResponder(c)
+
+	// ambil user_id dari middleware JWT
+	userID, exists := c.Get("user_id")
+	if !exists {
+		res.Unauthorized("user_id tidak ditemukan di context")
+		return
+	}
+
+	// ambil passkey
+	passkeys, err := h.waService.List(c.Request.Context(), userID.(string))
+	if err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(passkeys, "query ok", nil)
+}
+
+// Rename godoc
+// @Summary Ubah nama passkey
+// @Description Mengubah nama passkey milik user login
+// @Tags Passkey
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "ID passkey"
+// @Param request body request.PasskeyRenameRequest true "Nama baru"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Router /api/auth/passkeys/{id} [patch]
+func (h *PasskeyHandler) Rename(c *gin.Context) {
+	res := response.NewResponder(c)
+	var req request.PasskeyRenameRequest
+
+	// ambil user_id dari middleware JWT
+	userID, exists := c.Get("user_id")
+	if !exists {
+		res.Unauthorized("user_id tidak ditemukan di context")
+		return
+	}
+
+	// validasi
+	if !h.validates.ValigoJSON(c, &req) {
+		return
+	}
+
+	// ubah nama
+	if err := h.waService.Rename(c.Request.Context(), userID.(string), c.Param("id"), req.Name); err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(nil, "nama passkey berhasil diubah", nil)
+}
+
+// Delete godoc
+// @Summary Hapus passkey
+// @Description Menghapus passkey milik user login
+// @Tags Passkey
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "ID passkey"
+// @Success 200 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Router /api/auth/passkeys/{id} [delete]
+func (h *PasskeyHandler) Delete(c *gin.Context) {
+	res := response.NewResponder(c)
+
+	// ambil user_id dari middleware JWT
+	userID, exists := c.Get("user_id")
+	if !exists {
+		res.Unauthorized("user_id tidak ditemukan di context")
+		return
+	}
+
+	// hapus passkey
+	if err := h.waService.Delete(c.Request.Context(), userID.(string), c.Param("id")); err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(nil, "passkey berhasil dihapus", nil)
+}
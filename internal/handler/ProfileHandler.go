@@ -0,0 +1,235 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/gogaruda/apperror"
+	"github.com/gogaruda/valigo"
+	"github.com/irawankilmer/auth-service/internal/configs"
+	"github.com/irawankilmer/auth-service/internal/dto/request"
+	"github.com/irawankilmer/auth-service/internal/service"
+	"github.com/irawankilmer/auth-service/pkg/response"
+	"io"
+	"net/http"
+)
+
+type ProfileHandler struct {
+	profileService service.ProfileService
+	userService    service.UserService
+	validates      *valigo.Valigo
+	cfg            *configs.AppConfig
+}
+
+func NewProfileHandler(ps service.ProfileService, us service.UserService, v *valigo.Valigo, cfg *configs.AppConfig) *ProfileHandler {
+	return &ProfileHandler{profileService: ps, userService: us, validates: v, cfg: cfg}
+}
+
+// Me godoc
+// @Summary Profil user login
+// @Description Menampilkan profil user login
+// @Tags Profile
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Router /api/auth/me/profile [get]
+func (h *ProfileHandler) Me(c *gin.Context) {
+	res := response.NewResponder(c)
+
+	// ambil user_id dari middleware JWT
+	userID, exists := c.Get("user_id")
+	if !exists {
+		res.Unauthorized("user_id tidak ditemukan di context")
+		return
+	}
+
+	// ambil profil
+	profile, err := h.profileService.Get(c.Request.Context(), userID.(string))
+	if err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(profile, "query ok", nil)
+}
+
+// UpdateMe godoc
+// @Summary Perbarui profil user login
+// @Description Memperbarui sebagian profil user login. Field yang tidak dikirim tidak diubah, string kosong mengosongkan address
+// @Tags Profile
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body request.ProfileUpdateRequest true "Field profil yang diubah"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Router /api/auth/me/profile [patch]
+func (h *ProfileHandler) UpdateMe(c *gin.Context) {
+	res := response.NewResponder(c)
+	var req request.ProfileUpdateRequest
+
+	// ambil user_id dari middleware JWT
+	userID, exists := c.Get("user_id")
+	if !exists {
+		res.Unauthorized("user_id tidak ditemukan di context")
+		return
+	}
+
+	// validasi
+	if !h.validates.ValigoJSON(c, &req) {
+		return
+	}
+
+	// update profil
+	profile, err := h.profileService.Update(c.Request.Context(), userID.(string), req)
+	if err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+	if len(profile.ChangedFields) == 0 {
+		res.OK(profile, "tidak ada perubahan profil", nil)
+		return
+	}
+
+	res.OK(profile, "profil berhasil di update", nil)
+}
+
+// Update godoc
+// @Summary Perbarui profil user
+// @Description Memperbarui sebagian profil user berdasarkan ID. Field yang tidak dikirim tidak diubah, string kosong mengosongkan address
+// @Tags Users
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "ID user"
+// @Param request body request.ProfileUpdateRequest true "Field profil yang diubah"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Router /api/users/{id}/profile [patch]
+func (h *ProfileHandler) Update(c *gin.Context) {
+	res := response.NewResponder(c)
+	var req request.ProfileUpdateRequest
+	ctx := c.Request.Context()
+
+	// cek user
+	user, err := h.userService.FindByID(ctx, c.Param("id"))
+	if err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	// validasi
+	if !h.validates.ValigoJSON(c, &req) {
+		return
+	}
+
+	// update profil
+	profile, err := h.profileService.Update(ctx, user.ID, req)
+	if err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+	if len(profile.ChangedFields) == 0 {
+		res.OK(profile, "tidak ada perubahan profil", nil)
+		return
+	}
+
+	res.OK(profile, "profil berhasil di update", nil)
+}
+
+// UploadAvatar godoc
+// @Summary Upload avatar user login
+// @Description Upload gambar JPEG, PNG atau GIF sebagai avatar. Gambar dipotong persegi, diperkecil ke beberapa ukuran dan metadata EXIF dibuang
+// @Tags Profile
+// @Security BearerAuth
+// @Accept multipart/form-data
+// @Produce json
+// @Param avatar formData file true "File gambar"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 413 {object} response.APIResponse
+// @Failure 415 {object} response.APIResponse
+// @Router /api/auth/me/avatar [post]
+func (h *ProfileHandler) UploadAvatar(c *gin.Context) {
+	res := response.NewResponder(c)
+
+	// ambil user_id dari middleware JWT
+	userID, exists := c.Get("user_id")
+	if !exists {
+		res.Unauthorized("user_id tidak ditemukan di context")
+		return
+	}
+
+	// batasi ukuran body, sisa ruang untuk boundary dan header multipart
+	tooLarge := apperror.New("[AVATAR_TOO_LARGE]", "ukuran file avatar terlalu besar", nil, http.StatusRequestEntityTooLarge)
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.cfg.Avatar.MaxBytes+64<<10)
+	file, err := c.FormFile("avatar")
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			apperror.HandleHTTPError(c, tooLarge)
+			return
+		}
+
+		apperror.HandleHTTPError(c, apperror.New(apperror.CodeBadRequest, "file avatar wajib diisi", err))
+		return
+	}
+	if file.Size > h.cfg.Avatar.MaxBytes {
+		apperror.HandleHTTPError(c, tooLarge)
+		return
+	}
+
+	// baca file
+	src, err := file.Open()
+	if err != nil {
+		apperror.HandleHTTPError(c, apperror.New(apperror.CodeBadRequest, "file avatar tidak dapat dibaca", err))
+		return
+	}
+	defer src.Close()
+	data, err := io.ReadAll(src)
+	if err != nil {
+		apperror.HandleHTTPError(c, apperror.New(apperror.CodeBadRequest, "file avatar tidak dapat dibaca", err))
+		return
+	}
+
+	// simpan avatar
+	profile, err := h.profileService.UploadAvatar(c.Request.Context(), userID.(string), data)
+	if err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(profile, "avatar berhasil di update", nil)
+}
+
+// DeleteAvatar godoc
+// @Summary Hapus avatar user login
+// @Description Mengosongkan image profil dan menghapus file avatar dari storage
+// @Tags Profile
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Router /api/auth/me/avatar [delete]
+func (h *ProfileHandler) DeleteAvatar(c *gin.Context) {
+	res := response.NewResponder(c)
+
+	// ambil user_id dari middleware JWT
+	userID, exists := c.Get("user_id")
+	if !exists {
+		res.Unauthorized("user_id tidak ditemukan di context")
+		return
+	}
+
+	// hapus avatar
+	if err := h.profileService.DeleteAvatar(c.Request.Context(), userID.(string)); err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(nil, "avatar berhasil dihapus", nil)
+}
@@ -7,7 +7,6 @@ import (
 	"github.com/irawankilmer/auth-service/internal/dto/request"
 	"github.com/irawankilmer/auth-service/internal/service"
 	"github.com/irawankilmer/auth-service/pkg/response"
-	"strconv"
 )
 
 type UserHandler struct {
@@ -27,15 +26,13 @@ func NewUserHandler(us service.UserService, v *valigo.Valigo) *UserHandler {
 // @Accept json
 // @Produce json
 // @Param page query int false "Halaman saat ini"
-// @Param limit query int false "Jumlah item per halaman"
+// @Param limit query int false "Jumlah item per halaman (maksimal 100)"
 // @Success 200 {object} response.APIResponse
 // @Failure 401 {object} response.APIResponse
 // @Router /api/users [get]
 func (h *UserHandler) GetAll(c *gin.Context) {
 	res := response.NewResponder(c)
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
-	offset := (page - 1) * limit
+	page, limit, offset := pagination(c)
 
 	users, total, err := h.userService.GetAll(c.Request.Context(), limit, offset)
 	if err != nil {
@@ -101,6 +98,52 @@ func (h *UserHandler) FindByID(c *gin.Context) {
 	res.OK(user, "query ok", nil)
 }
 
+// UsernameUpdate godoc
+// @Summary Perbarui username user
+// @Description Memperbarui username user berdasarkan ID, username lama masuk riwayat dan tidak bisa dipakai lagi
+// @Tags Users
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "ID user"
+// @Param request body request.UserUpdateUsernameRequest true "Username baru"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
+// @Failure 429 {object} response.APIResponse
+// @Router /api/users/{id}/username [patch]
+func (h *UserHandler) UsernameUpdate(c *gin.Context) {
+	res := response.NewResponder(c)
+	var req request.UserUpdateUsernameRequest
+	ctx := c.Request.Context()
+
+	// cek user
+	user, err := h.userService.FindByID(ctx, c.Param("id"))
+	if err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	// validasi
+	if !h.validate.ValigoJSON(c, &req) {
+		return
+	}
+
+	// update username
+	usernameUpdate, err := h.userService.UsernameUpdate(ctx, user.ID, req.Username, true)
+	if err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+	if !usernameUpdate {
+		res.OK(nil, "tidak ada perubahan username", nil)
+		return
+	}
+
+	res.OK(nil, "username berhasil di update", nil)
+}
+
 // EmailUpdate godoc
 // @Summary Perbarui email user
 // @Description Memperbarui email user berdasarkan ID
@@ -216,3 +259,30 @@ func (h *UserHandler) Delete(c *gin.Context) {
 
 	res.OK(nil, "user berhasil dihapus", nil)
 }
+
+// Unlock godoc
+// @Summary Buka kunci akun user
+// @Description Membuka kunci akun yang terkunci karena terlalu banyak percobaan login gagal
+// @Tags Users
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "ID user"
+// @Success 200 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Router /api/users/{id}/unlock [post]
+func (h *UserHandler) Unlock(c *gin.Context) {
+	res := response.NewResponder(c)
+	user, err := h.userService.FindByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	if err := h.userService.Unlock(c.Request.Context(), user); err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(nil, "kunci akun berhasil dibuka", nil)
+}
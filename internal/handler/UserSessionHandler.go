@@ -3,21 +3,27 @@ package handler
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/gogaruda/apperror"
+	"github.com/gogaruda/valigo"
+	"github.com/irawankilmer/auth-service/internal/configs"
+	"github.com/irawankilmer/auth-service/internal/dto/request"
 	"github.com/irawankilmer/auth-service/internal/service"
 	"github.com/irawankilmer/auth-service/pkg/response"
 )
 
 type UserSessionHandler struct {
-	usService service.UserSessionService
+	usService   service.UserSessionService
+	authService service.AuthService
+	validates   *valigo.Valigo
+	cfg         *configs.AppConfig
 }
 
-func NewUserSessionHandler(usS service.UserSessionService) *UserSessionHandler {
-	return &UserSessionHandler{usService: usS}
+func NewUserSessionHandler(usS service.UserSessionService, authS service.AuthService, v *valigo.Valigo, cfg *configs.AppConfig) *UserSessionHandler {
+	return &UserSessionHandler{usService: usS, authService: authS, validates: v, cfg: cfg}
 }
 
 // RefreshToken godoc
 // @Summary Refresh access token
-// @Description Menghasilkan access token dan refresh token baru menggunakan cookie refresh_token
+// @Description Menghasilkan access token dan refresh token baru menggunakan cookie refresh_token. Refresh token lama yang dipakai lagi setelah dirotasi mengakhiri semua session dari login tersebut
 // @Tags User Sessions
 // @Accept json
 // @Produce json
@@ -27,7 +33,7 @@ func NewUserSessionHandler(usS service.UserSessionService) *UserSessionHandler {
 func (h *UserSessionHandler) RefreshToken(c *gin.Context) {
 	res := response.NewResponder(c)
 	var deviceID, ipAddress, userAgent string
-	deviceID = "coba dulu"
+	deviceID = c.GetString("device_id")
 	ipAddress = c.ClientIP()
 	userAgent = c.Request.UserAgent()
 
@@ -45,7 +51,223 @@ func (h *UserSessionHandler) RefreshToken(c *gin.Context) {
 		return
 	}
 
-	c.SetCookie("access_token", token.AccessToken, 900, "/", "localhost", true, true)           // 15 menit
-	c.SetCookie("refresh_token", token.RefreshToken, 60*60*24*30, "/", "localhost", true, true) // 30 hari
+	setTokenCookies(c, h.cfg.Cookie, token)
 	res.OK(token, "refresh token berhasil", nil)
 }
+
+// RevokeByLink godoc
+// @Summary Akhiri session dari link email
+// @Description Mengakhiri session dari link "bukan saya" di email login baru beserta access token user, tidak perlu login. Link hanya berlaku sekali
+// @Tags User Sessions
+// @Accept json
+// @Produce json
+// @Param request body request.SessionRevokeLinkRequest true "Token dari link email"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 429 {object} response.APIResponse
+// @Router /api/auth/sessions/revoke-link [post]
+func (h *UserSessionHandler) RevokeByLink(c *gin.Context) {
+	res := response.NewResponder(c)
+	var req request.SessionRevokeLinkRequest
+
+	// validasi
+	if !h.validates.ValigoJSON(c, &req) {
+		return
+	}
+
+	if err := h.usService.RevokeByLink(c.Request.Context(), req); err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(nil, "session berhasil diakhiri, segera ganti password jika login tersebut bukan Anda", nil)
+}
+
+// List godoc
+// @Summary Daftar session aktif
+// @Description Menampilkan session aktif user login beserta perangkat, IP dan waktu terakhir dipakai. Session saat ini ditandai current
+// @Tags User Sessions
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Router /api/auth/sessions [get]
+func (h *UserSessionHandler) List(c *gin.Context) {
+	res := response.NewResponder(c)
+
+	// ambil user_id dari middleware JWT
+	userID, exists := c.Get("user_id")
+	if !exists {
+		res.Unauthorized("user_id tidak ditemukan di context")
+		return
+	}
+
+	// cookie refresh token tidak wajib, client mobile dikenali dari device ID
+	refreshToken, _ := c.Cookie("refresh_token")
+	sessions, err := h.usService.List(c.Request.Context(), userID.(string), refreshToken, c.GetString("device_id"))
+	if err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(sessions, "query ok", nil)
+}
+
+// Revoke godoc
+// @Summary Akhiri session
+// @Description Mengakhiri satu session milik user login, access token session tersebut tetap berlaku sampai kadaluarsa
+// @Tags User Sessions
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "ID session"
+// @Success 200 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Router /api/auth/sessions/{id} [delete]
+func (h *UserSessionHandler) Revoke(c *gin.Context) {
+	res := response.NewResponder(c)
+
+	// ambil user_id dari middleware JWT
+	userID, exists := c.Get("user_id")
+	if !exists {
+		res.Unauthorized("user_id tidak ditemukan di context")
+		return
+	}
+
+	if err := h.usService.Revoke(c.Request.Context(), userID.(string), c.Param("id")); err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(nil, "session berhasil diakhiri", nil)
+}
+
+// UserSessions godoc
+// @Summary Daftar session aktif user
+// @Description Menampilkan session aktif user berdasarkan ID untuk admin
+// @Tags User Sessions
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "ID user"
+// @Success 200 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Router /api/users/{id}/sessions [get]
+func (h *UserSessionHandler) UserSessions(c *gin.Context) {
+	res := response.NewResponder(c)
+	ctx := c.Request.Context()
+
+	sessions, err := h.usService.ListByUser(ctx, c.Param("id"))
+	if err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(sessions, "query ok", nil)
+}
+
+// UserSessionRevoke godoc
+// @Summary Akhiri session user
+// @Description Mengakhiri satu session aktif user oleh admin, access token session tersebut tetap berlaku sampai kadaluarsa. Session super admin hanya bisa diakhiri super admin
+// @Tags User Sessions
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "ID user"
+// @Param sid path string true "ID session"
+// @Success 200 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Router /api/users/{id}/sessions/{sid} [delete]
+func (h *UserSessionHandler) UserSessionRevoke(c *gin.Context) {
+	res := response.NewResponder(c)
+	ctx := c.Request.Context()
+
+	// cek user dan role
+	if err := h.usService.ManageTarget(ctx, actorRoles(c), c.Param("id")); err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	if err := h.usService.Revoke(ctx, c.Param("id"), c.Param("sid")); err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(nil, "session user berhasil diakhiri", nil)
+}
+
+// ForceLogout godoc
+// @Summary Paksa logout user
+// @Description Mengganti token version dan mengakhiri semua session user sehingga access token dan refresh token user langsung tidak berlaku. Super admin hanya bisa dipaksa logout oleh super admin
+// @Tags User Sessions
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "ID user"
+// @Success 200 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Router /api/users/{id}/force-logout [post]
+func (h *UserSessionHandler) ForceLogout(c *gin.Context) {
+	res := response.NewResponder(c)
+	ctx := c.Request.Context()
+
+	// cek user dan role
+	if err := h.usService.ManageTarget(ctx, actorRoles(c), c.Param("id")); err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	if err := h.authService.LogoutAllDevices(ctx, c.Param("id")); err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	res.OK(nil, "user berhasil dipaksa logout dari semua perangkat", nil)
+}
+
+// Search godoc
+// @Summary Cari session
+// @Description Mencari session semua user untuk investigasi insiden dengan filter user, IP dan status
+// @Tags User Sessions
+// @Security BearerAuth
+// @Produce json
+// @Param user_id query string false "ID user"
+// @Param ip query string false "Alamat IP"
+// @Param status query string false "Status session" Enums(active, expired, revoked)
+// @Param page query int false "Halaman saat ini"
+// @Param limit query int false "Jumlah item per halaman (maksimal 100)"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Router /api/sessions [get]
+func (h *UserSessionHandler) Search(c *gin.Context) {
+	res := response.NewResponder(c)
+	page, limit, offset := pagination(c)
+
+	sessions, total, err := h.usService.Search(c.Request.Context(), c.Query("user_id"), c.Query("ip"), c.Query("status"), limit, offset)
+	if err != nil {
+		apperror.HandleHTTPError(c, err)
+		return
+	}
+
+	meta := response.MetaData{
+		Total: total,
+		Page:  page,
+		Limit: limit,
+	}
+
+	res.OK(sessions, "query ok", &meta)
+}
+
+// actorRoles roles admin yang login dari AuthMiddleware
+func actorRoles(c *gin.Context) []string {
+	roles, _ := c.Get("roles")
+	names, _ := roles.([]string)
+	return names
+}
@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/irawankilmer/auth-service/internal/configs"
+	dto "github.com/irawankilmer/auth-service/internal/dto/response"
+)
+
+// setCookie cookie httpOnly dengan Secure dan SameSite dari konfigurasi, maxAge -1 menghapus cookie
+func setCookie(c *gin.Context, cfg configs.CookieConfig, name, value string, maxAge int, path string) {
+	c.SetSameSite(cfg.SameSite)
+	c.SetCookie(name, value, maxAge, path, "", cfg.Secure, true)
+}
+
+// setTokenCookies umur cookie mengikuti umur access token dan refresh token dari kebijakan session
+func setTokenCookies(c *gin.Context, cfg configs.CookieConfig, token *dto.LoginResponse) {
+	setCookie(c, cfg, "access_token", token.AccessToken, token.ExpiresIn, "/")
+	setCookie(c, cfg, "refresh_token", token.RefreshToken, token.RefreshExpiresIn, "/")
+}
+
+func clearTokenCookies(c *gin.Context, cfg configs.CookieConfig) {
+	setCookie(c, cfg, "access_token", "", -1, "/")
+	setCookie(c, cfg, "refresh_token", "", -1, "/")
+}
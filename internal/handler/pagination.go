@@ -0,0 +1,28 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"strconv"
+)
+
+// batas jumlah item per halaman untuk semua handler yang memakai paginasi
+const (
+	defaultPageLimit = 10
+	maxPageLimit     = 100
+)
+
+// pagination membaca query page dan limit, page minimal 1 dan limit dibatasi 1 sampai maxPageLimit
+func pagination(c *gin.Context) (page, limit, offset int) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit, err = strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultPageLimit)))
+	if err != nil || limit < 1 {
+		limit = defaultPageLimit
+	}
+	limit = min(limit, maxPageLimit)
+
+	return page, limit, (page - 1) * limit
+}
@@ -1,6 +1,10 @@
 package middleware
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
 	"strings"
 	"time"
 
@@ -9,7 +13,18 @@ import (
 	"github.com/irawankilmer/auth-service/pkg/response"
 )
 
-func (m *middleware) AuthMiddleware() gin.HandlerFunc {
+// TokenVersionCheck cara AuthMiddleware mencocokkan token_version access token dengan database
+type TokenVersionCheck int
+
+const (
+	// TokenVersionCached memakai cache token_version, token yang dicabut di instance lain
+	// masih diterima paling lama selama JWT_VERSION_CACHE_TTL
+	TokenVersionCached TokenVersionCheck = iota
+	// TokenVersionStrict selalu query database, untuk route sensitif
+	TokenVersionStrict
+)
+
+func (m *middleware) AuthMiddleware(check TokenVersionCheck) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		res := response.NewResponder(c)
 
@@ -76,6 +91,22 @@ func (m *middleware) AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// cek token version, token dari sebelum logout semua perangkat, ganti password atau ganti role ditolak
+		currentVersion, err := m.tokenVersion(c.Request.Context(), userID, check)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				res.Unauthorized("user tidak ditemukan")
+				return
+			}
+			log.Printf("[ERROR] cek token version user %s gagal: %v", userID, err)
+			res.ServerError("cek token gagal")
+			return
+		}
+		if currentVersion != tokenVersion {
+			res.Unauthorized("token sudah dicabut, silakan login kembali")
+			return
+		}
+
 		// Simpan data token ke context
 		c.Set("user_id", userID)
 		c.Set("token_version", tokenVersion)
@@ -85,3 +116,22 @@ func (m *middleware) AuthMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// tokenVersion mengambil token_version user dari cache atau database, hasil query disimpan ke cache
+// kecuali token version dicabut selama query berjalan
+func (m *middleware) tokenVersion(ctx context.Context, userID string, check TokenVersionCheck) (string, error) {
+	if check == TokenVersionCached {
+		if version, ok := m.tokenCache.Get(userID); ok {
+			return version, nil
+		}
+	}
+
+	generation := m.tokenCache.Generation()
+	user, err := m.userRepo.FindUserByTokenVersion(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	m.tokenCache.Set(userID, user.TokenVersion, generation)
+
+	return user.TokenVersion, nil
+}
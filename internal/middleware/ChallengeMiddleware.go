@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/irawankilmer/auth-service/pkg/challenge"
+	"github.com/irawankilmer/auth-service/pkg/ratelimit"
+	"github.com/irawankilmer/auth-service/pkg/response"
+	"log"
+	"net/http"
+	"time"
+)
+
+// header jawaban challenge dari client
+const (
+	ChallengeTokenHeader    = "X-Challenge-Token"
+	ChallengeResponseHeader = "X-Challenge-Response"
+)
+
+// ChallengeMiddleware meminta challenge dari IP yang sudah gagal login/register sebanyak threshold dalam window.
+// Request tanpa jawaban valid ditolak 428 beserta challenge baru, respon 4xx dari handler dihitung sebagai kegagalan
+func (m *middleware) ChallengeMiddleware() gin.HandlerFunc {
+	cfg := m.cfg.Challenge
+	if !cfg.Enabled {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+	failures := ratelimit.Limit{Algorithm: ratelimit.SlidingWindow, Limit: cfg.Threshold, Window: cfg.Window}
+
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		ip := c.ClientIP()
+		key := "challenge:" + ip
+
+		// cek jumlah kegagalan IP
+		status, err := m.limiter.Peek(ctx, key, failures, time.Now())
+		if err != nil {
+			log.Printf("[WARN] cek kegagalan challenge gagal: %v", err)
+		} else if !status.Allowed && !m.solveChallenge(c, ip) {
+			return
+		}
+
+		c.Next()
+
+		// hitung kegagalan, 429 tidak dihitung karena sudah ditangani rate limit
+		if code := c.Writer.Status(); code >= http.StatusBadRequest && code < http.StatusInternalServerError && code != http.StatusTooManyRequests {
+			if _, err := m.limiter.Allow(ctx, key, failures, time.Now()); err != nil {
+				log.Printf("[WARN] catat kegagalan challenge gagal: %v", err)
+			}
+		}
+	}
+}
+
+// solveChallenge memverifikasi jawaban challenge, jika gagal respon 428 sudah dikirim
+func (m *middleware) solveChallenge(c *gin.Context, ip string) bool {
+	res := response.NewResponder(c)
+	ctx := c.Request.Context()
+	token := c.GetHeader(ChallengeTokenHeader)
+	answer := c.GetHeader(ChallengeResponseHeader)
+
+	message := "selesaikan challenge terlebih dahulu"
+	if answer != "" {
+		err := m.challenger.Verify(ctx, token, answer, ip)
+		if err == nil {
+			// jawaban hanya boleh dipakai sekali selama masa berlaku challenge
+			sum := sha256.Sum256([]byte(token + ":" + answer))
+			used := ratelimit.Limit{Algorithm: ratelimit.TokenBucket, Limit: 1, Window: m.cfg.Challenge.TTL}
+			result, err := m.limiter.Allow(ctx, "challenge_used:"+hex.EncodeToString(sum[:]), used, time.Now())
+			if err != nil || result.Allowed {
+				return true
+			}
+			message = "jawaban challenge sudah dipakai"
+		} else if errors.Is(err, challenge.ErrExpired) {
+			message = "challenge sudah kedaluwarsa"
+		} else if errors.Is(err, challenge.ErrInvalid) {
+			message = "jawaban challenge tidak valid"
+		} else {
+			log.Printf("[ERROR] verifikasi challenge gagal: %v", err)
+			res.ServerError("verifikasi challenge gagal")
+			return false
+		}
+	}
+
+	next, err := m.challenger.Issue(ctx, ip)
+	if err != nil {
+		res.ServerError("gagal membuat challenge")
+		return false
+	}
+
+	res.PreconditionRequired(next, message)
+	return false
+}
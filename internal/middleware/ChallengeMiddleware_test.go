@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/irawankilmer/auth-service/internal/configs"
+	"github.com/irawankilmer/auth-service/pkg/challenge"
+	"github.com/irawankilmer/auth-service/pkg/ratelimit"
+	"github.com/irawankilmer/auth-service/pkg/tokencache"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// acceptVerifier provider captcha palsu yang menerima jawaban apa pun selain "salah"
+type acceptVerifier struct{}
+
+func (acceptVerifier) Verify(_ context.Context, response, _ string) (bool, error) {
+	return response != "salah", nil
+}
+
+func TestChallengeMiddlewareReplay(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := &configs.AppConfig{Challenge: configs.ChallengeConfig{
+		Enabled: true, Driver: challenge.TypeTurnstile, TTL: time.Minute, Threshold: 1, Window: time.Minute,
+	}}
+	ch := challenge.NewCaptcha(challenge.TypeTurnstile, "site-key", acceptVerifier{})
+	m := NewMiddleware(cfg, nil, ratelimit.NewMemoryStore(), ch, tokencache.New(0, 0))
+
+	// handler selalu gagal agar IP mencapai threshold sejak request pertama
+	r := gin.New()
+	r.POST("/login", m.ChallengeMiddleware(), func(c *gin.Context) {
+		c.Status(http.StatusUnauthorized)
+	})
+
+	steps := []struct {
+		name        string
+		answer      string
+		wantStatus  int
+		wantMessage string
+	}{
+		{name: "kegagalan pertama tanpa challenge", wantStatus: http.StatusUnauthorized},
+		{name: "tanpa jawaban", wantStatus: http.StatusPreconditionRequired, wantMessage: "selesaikan challenge terlebih dahulu"},
+		{name: "jawaban ditolak provider", answer: "salah", wantStatus: http.StatusPreconditionRequired, wantMessage: "jawaban challenge tidak valid"},
+		{name: "jawaban valid", answer: "jawaban-1", wantStatus: http.StatusUnauthorized},
+		{name: "jawaban yang sama dipakai ulang", answer: "jawaban-1", wantStatus: http.StatusPreconditionRequired, wantMessage: "jawaban challenge sudah dipakai"},
+		{name: "jawaban baru", answer: "jawaban-2", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, step := range steps {
+		req := httptest.NewRequest(http.MethodPost, "/login", nil)
+		if step.answer != "" {
+			req.Header.Set(ChallengeResponseHeader, step.answer)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != step.wantStatus {
+			t.Fatalf("%s: status = %d, ingin %d", step.name, w.Code, step.wantStatus)
+		}
+		if step.wantMessage == "" {
+			continue
+		}
+
+		var body struct {
+			Message string              `json:"message"`
+			Data    challenge.Challenge `json:"data"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		if body.Message != step.wantMessage || body.Data.Type != challenge.TypeTurnstile {
+			t.Errorf("%s: respon = %+v, ingin pesan %q dengan challenge baru", step.name, body, step.wantMessage)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/irawankilmer/auth-service/pkg/device"
+	"github.com/irawankilmer/auth-service/pkg/response"
+)
+
+// device ID browser disimpan di cookie, aplikasi mobile menyimpan nilai header X-Device-ID dari respon
+// lalu mengirimnya kembali di setiap request
+const (
+	DeviceIDHeader = "X-Device-ID"
+	deviceIDCookie = "device_id"
+)
+
+// DeviceMiddleware mengisi context "device_id" dari header X-Device-ID atau cookie device_id yang ditandatangani.
+// Nilai yang tidak valid atau tidak ada diganti device ID baru, cookie diperpanjang di setiap request
+func (m *middleware) DeviceMiddleware() gin.HandlerFunc {
+	cfg := m.cfg.Device
+
+	return func(c *gin.Context) {
+		token := c.GetHeader(DeviceIDHeader)
+		if token == "" {
+			token, _ = c.Cookie(deviceIDCookie)
+		}
+
+		id, ok := device.Verify(cfg.Secret, token)
+		if !ok {
+			newID, err := device.NewID()
+			if err != nil {
+				response.NewResponder(c).ServerError("gagal membuat device ID")
+				return
+			}
+			id, token = newID, device.Sign(cfg.Secret, newID)
+		}
+
+		c.SetSameSite(m.cfg.Cookie.SameSite)
+		c.SetCookie(deviceIDCookie, token, int(cfg.CookieTTL.Seconds()), "/", "", m.cfg.Cookie.Secure, true)
+		c.Header(DeviceIDHeader, token)
+		c.Set("device_id", id)
+		c.Next()
+	}
+}
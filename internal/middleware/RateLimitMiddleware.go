@@ -0,0 +1,119 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/irawankilmer/auth-service/pkg/ratelimit"
+	"github.com/irawankilmer/auth-service/pkg/response"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimitKey menentukan siapa yang dibatasi, string kosong berarti request dihitung per IP
+type RateLimitKey func(c *gin.Context) string
+
+func KeyByIP(c *gin.Context) string {
+	return c.ClientIP()
+}
+
+// KeyByUserID hanya bekerja setelah AuthMiddleware
+func KeyByUserID(c *gin.Context) string {
+	userID, _ := c.Get("user_id")
+	id, _ := userID.(string)
+	return id
+}
+
+// batas ukuran body yang dibaca KeyByJSONField, body login dan email jauh lebih kecil dari ini
+const maxKeyBodyBytes = 64 << 10
+
+// KeyByJSONField membaca field pertama yang terisi dari body JSON (misal identifier atau email),
+// body dikembalikan utuh agar tetap bisa dibaca handler. Body di-decode ke struct dengan tag json yang sama
+// seperti request handler, sehingga nama field beda huruf besar/kecil atau field ganda dibaca sama persis
+func KeyByJSONField(fields ...string) RateLimitKey {
+	structFields := make([]reflect.StructField, len(fields))
+	for i, field := range fields {
+		structFields[i] = reflect.StructField{
+			Name: fmt.Sprintf("Field%d", i),
+			Type: reflect.TypeOf(""),
+			Tag:  reflect.StructTag(fmt.Sprintf(`json:"%s"`, field)),
+		}
+	}
+	payloadType := reflect.StructOf(structFields)
+
+	return func(c *gin.Context) string {
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxKeyBodyBytes))
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		if err != nil {
+			return ""
+		}
+
+		// error tipe field lain diabaikan, field string yang valid tetap terisi
+		payload := reflect.New(payloadType)
+		_ = json.Unmarshal(body, payload.Interface())
+		for i := range fields {
+			if value := strings.TrimSpace(payload.Elem().Field(i).String()); value != "" {
+				return strings.ToLower(value)
+			}
+		}
+
+		return ""
+	}
+}
+
+// RateLimitMiddleware membatasi request berdasarkan limit route di konfigurasi, route tanpa limit tidak dibatasi.
+// Jika store tidak bisa dihubungi request tetap diteruskan agar gangguan store tidak mematikan login
+func (m *middleware) RateLimitMiddleware(route string, key RateLimitKey) gin.HandlerFunc {
+	rule, ok := m.cfg.RateLimit.Routes[route]
+	if !m.cfg.RateLimit.Enabled || !ok {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+	limit := ratelimit.Limit{Algorithm: rule.Algorithm, Limit: rule.Limit, Window: rule.Window}
+
+	return func(c *gin.Context) {
+		res := response.NewResponder(c)
+
+		// request tanpa key tetap dibatasi, dihitung per IP
+		id := key(c)
+		if id == "" {
+			id = "ip:" + c.ClientIP()
+		}
+
+		result, err := m.limiter.Allow(c.Request.Context(), route+":"+id, limit, time.Now())
+		if err != nil {
+			log.Printf("[WARN] rate limit %s gagal: %v", route, err)
+			c.Next()
+			return
+		}
+
+		// header limit yang paling ketat jika satu route memakai beberapa limit
+		if current, err := strconv.Atoi(c.Writer.Header().Get("RateLimit-Remaining")); err != nil || result.Remaining <= current {
+			c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+			c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			c.Header("RateLimit-Reset", strconv.Itoa(seconds(result.Reset)))
+			c.Header("RateLimit-Policy", fmt.Sprintf("%d;w=%d", rule.Limit, seconds(rule.Window)))
+		}
+
+		if !result.Allowed {
+			retryAfter := max(seconds(result.RetryAfter), 1)
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			res.TooManyRequests(fmt.Sprintf("terlalu banyak permintaan, coba lagi dalam %d detik", retryAfter))
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/irawankilmer/auth-service/internal/configs"
+	"github.com/irawankilmer/auth-service/pkg/ratelimit"
+	"github.com/irawankilmer/auth-service/pkg/tokencache"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestKeyByJSONField(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name   string
+		fields []string
+		body   string
+		want   string
+	}{
+		{name: "nama field sama", fields: []string{"identifier"}, body: `{"identifier":"Alice"}`, want: "alice"},
+		{name: "huruf besar semua", fields: []string{"identifier"}, body: `{"IDENTIFIER":"victim"}`, want: "victim"},
+		{name: "huruf campuran", fields: []string{"email"}, body: `{"Email":" Victim@Example.com "}`, want: "victim@example.com"},
+		{name: "field ganda memakai yang terakhir", fields: []string{"identifier"}, body: `{"identifier":"a","IDENTIFIER":"victim"}`, want: "victim"},
+		{name: "field pertama kosong", fields: []string{"username", "email"}, body: `{"username":" ","email":"bob@example.com"}`, want: "bob@example.com"},
+		{name: "field tidak ada", fields: []string{"identifier"}, body: `{"password":"x"}`},
+		{name: "bukan string", fields: []string{"identifier"}, body: `{"identifier":123}`},
+		{name: "field lain salah tipe", fields: []string{"identifier"}, body: `{"password":1,"identifier":"alice"}`, want: "alice"},
+		{name: "bukan JSON", fields: []string{"identifier"}, body: `identifier=alice`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(tt.body))
+
+			if got := KeyByJSONField(tt.fields...)(c); got != tt.want {
+				t.Errorf("key = %q, ingin %q", got, tt.want)
+			}
+
+			// body tetap bisa dibaca handler
+			body, _ := io.ReadAll(c.Request.Body)
+			if string(body) != tt.body {
+				t.Errorf("body = %q, ingin %q", body, tt.body)
+			}
+		})
+	}
+}
+
+// TestRateLimitMiddlewareMissingKey request tanpa key dihitung per IP, bukan dilewatkan
+func TestRateLimitMiddlewareMissingKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := &configs.AppConfig{RateLimit: configs.RateLimitConfig{
+		Enabled: true,
+		Routes: map[string]configs.RateLimitRule{
+			"login_identifier": {Algorithm: ratelimit.TokenBucket, Limit: 2, Window: time.Minute},
+		},
+	}}
+	m := NewMiddleware(cfg, nil, ratelimit.NewMemoryStore(), nil, tokencache.New(0, 0))
+
+	r := gin.New()
+	r.POST("/login", m.RateLimitMiddleware("login_identifier", KeyByJSONField("identifier")), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	for i, want := range []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{"password":"x"}`)))
+		if w.Code != want {
+			t.Fatalf("request %d: status = %d, ingin %d", i+1, w.Code, want)
+		}
+	}
+}
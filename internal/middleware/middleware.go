@@ -4,20 +4,31 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/irawankilmer/auth-service/internal/configs"
 	"github.com/irawankilmer/auth-service/internal/repository"
+	"github.com/irawankilmer/auth-service/pkg/challenge"
+	"github.com/irawankilmer/auth-service/pkg/ratelimit"
+	"github.com/irawankilmer/auth-service/pkg/tokencache"
 )
 
 type Middleware interface {
 	CORSMiddleware() gin.HandlerFunc
-	AuthMiddleware() gin.HandlerFunc
+	AuthMiddleware(check TokenVersionCheck) gin.HandlerFunc
 	RoleMiddleware(matchType RoleMatchType, requiredRoles ...string) gin.HandlerFunc
 	EmailVerifyMiddleware() gin.HandlerFunc
+	RateLimitMiddleware(route string, key RateLimitKey) gin.HandlerFunc
+	ChallengeMiddleware() gin.HandlerFunc
+	DeviceMiddleware() gin.HandlerFunc
 }
 
 type middleware struct {
-	cfg      *configs.AppConfig
-	userRepo repository.UserRepository
+	cfg        *configs.AppConfig
+	userRepo   repository.UserRepository
+	limiter    ratelimit.Store
+	challenger challenge.Challenger
+	tokenCache tokencache.Cache
 }
 
-func NewMiddleware(config *configs.AppConfig, u repository.UserRepository) Middleware {
-	return &middleware{cfg: config, userRepo: u}
+func NewMiddleware(config *configs.AppConfig, u repository.UserRepository, limiter ratelimit.Store, ch challenge.Challenger,
+	tc tokencache.Cache,
+) Middleware {
+	return &middleware{cfg: config, userRepo: u, limiter: limiter, challenger: ch, tokenCache: tc}
 }
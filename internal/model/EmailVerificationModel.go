@@ -6,6 +6,7 @@ type EmailVerificationModel struct {
 	ID         string
 	UserID     string
 	Token      string
+	Email      *string
 	ExpiresAt  time.Time
 	IsUsed     bool
 	ActionType string
@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+type LoginAttemptModel struct {
+	Identifier     string
+	IPAddress      string
+	FailedAttempts int
+	LockoutCount   int
+	LockedUntil    *time.Time
+	LastFailedAt   *time.Time
+}
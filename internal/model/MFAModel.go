@@ -0,0 +1,11 @@
+package model
+
+import "time"
+
+type MFAModel struct {
+	UserID          string
+	SecretEncrypted string
+	Enabled         bool
+	LastUsedStep    int64
+	ConfirmedAt     *time.Time
+}
@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+type MFARecoveryCodeModel struct {
+	ID            string
+	UserID        string
+	CodeHash      string
+	UsedAt        *time.Time
+	UsedIPAddress *string
+	UsedUserAgent *string
+}
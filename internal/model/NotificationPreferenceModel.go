@@ -0,0 +1,7 @@
+package model
+
+// jenis notifikasi yang bisa dimatikan user, jenis yang belum pernah diatur dianggap aktif
+const (
+	NotifyNewDevice  = "new_device"
+	NotifyNewNetwork = "new_network"
+)
@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// jenis security event
+const (
+	SecurityEventRefreshTokenReuse = "refresh_token_reuse"
+)
+
+type SecurityEventModel struct {
+	ID        string
+	UserID    string
+	EventType string
+	IPAddress string
+	UserAgent string
+	Metadata  map[string]string
+	CreatedAt time.Time
+}
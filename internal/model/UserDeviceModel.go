@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+type UserDeviceModel struct {
+	ID             string
+	UserID         string
+	DeviceID       string
+	Name           *string
+	Browser        string
+	BrowserVersion string
+	OS             string
+	OSVersion      string
+	DeviceType     string
+	DeviceModel    string
+	LastIP         string
+	TrustedUntil   *time.Time
+	LastSeenAt     time.Time
+	CreatedAt      time.Time
+}
@@ -0,0 +1,11 @@
+package model
+
+import "time"
+
+type UserIdentityModel struct {
+	UserID   string
+	Provider string
+	Subject  string
+	Email    *string
+	LinkedAt time.Time
+}
@@ -1,14 +1,23 @@
 package model
 
+import "time"
+
 type UserModel struct {
-	ID             string
-	Username       *string
-	Email          string
-	Password       *string
-	TokenVersion   string
-	EmailVerified  bool
-	CreatedByAdmin bool
-	GoogleID       *string
-	Profile        ProfileModel
-	Roles          []RoleModel
+	ID                  string
+	Username            *string
+	UsernameChangedAt   *time.Time
+	Email               string
+	Password            *string
+	TokenVersion        string
+	EmailVerified       bool
+	CreatedByAdmin      bool
+	FailedLoginAttempts int
+	LockoutCount        int
+	LockedUntil         *time.Time
+	MFAEnabled          bool
+	PasskeyCount        int
+	IdentityCount       int
+	Profile             ProfileModel
+	Roles               []RoleModel
+	Identities          []UserIdentityModel
 }
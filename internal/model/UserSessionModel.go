@@ -2,13 +2,30 @@ package model
 
 import "time"
 
+// status session untuk filter pencarian admin
+const (
+	SessionStatusActive  = "active"
+	SessionStatusExpired = "expired"
+	SessionStatusRevoked = "revoked"
+)
+
 type UserSession struct {
 	ID               string
 	UserID           string
+	FamilyID         string // ID session login awal, sama untuk semua session hasil rotasi refresh token
+	ParentID         *string
 	RefreshTokenHash string
 	DeviceID         string
+	RememberMe       bool
 	IPAddress        string
 	UserAgent        string
 	Revoked          bool
+	RotatedAt        *time.Time // waktu refresh token diganti lewat refresh, nil jika revoked karena logout
 	ExpiresAt        time.Time
+	LastUsedAt       time.Time
+	CreatedAt        time.Time
+	// Device perangkat session (hanya ID dan nama), nil jika session tidak punya device ID
+	Device *UserDeviceModel
+	// User pemilik session (hanya username dan email), diisi pada pencarian session oleh admin
+	User *UserModel
 }
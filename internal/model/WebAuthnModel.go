@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+type WebAuthnCredentialModel struct {
+	ID             string
+	UserID         string
+	CredentialID   []byte
+	Name           string
+	SignCount      uint32
+	CloneWarning   bool
+	CredentialData []byte
+	LastUsedAt     *time.Time
+	CreatedAt      time.Time
+}
+
+type WebAuthnSessionModel struct {
+	ID          string
+	UserID      *string
+	Ceremony    string
+	SessionData []byte
+	ExpiresAt   time.Time
+}
@@ -8,11 +8,21 @@ import (
 	"github.com/irawankilmer/auth-service/internal/dto/response"
 	"github.com/irawankilmer/auth-service/internal/model"
 	"net/http"
+	"time"
 )
 
 type AuthRepository interface {
 	IdentifierCheck(ctx context.Context, identifier string) (*model.UserModel, error)
+	FindByID(ctx context.Context, userID string) (*model.UserModel, error)
+	FindByEmail(ctx context.Context, email string) (*model.UserModel, error)
+	FindByIdentity(ctx context.Context, provider, subject string) (*model.UserModel, error)
 	UpdateTokenVersion(ctx context.Context, userID, newTokenVersion string) error
+	IncrementLoginFailure(ctx context.Context, userID string, window time.Duration) (*model.UserModel, error)
+	LockAccount(ctx context.Context, userID string, lockoutCount int, lockedUntil time.Time) error
+	ResetLoginFailure(ctx context.Context, userID string) error
+	ResetPassword(ctx context.Context, userID, password, newTokenVersion string) error
+	UpdatePassword(ctx context.Context, userID, password, newTokenVersion string) error
+	UpdatePasswordHash(ctx context.Context, userID, oldHash, newHash string) error
 	Me(ctx context.Context, userID string) (*response.UserDetailResponse, error)
 }
 
@@ -25,24 +35,57 @@ func NewAuthRepository(db *sql.DB) AuthRepository {
 }
 
 func (r *authRepository) IdentifierCheck(ctx context.Context, identifier string) (*model.UserModel, error) {
+	return r.findUser(ctx, `u.username = ? OR u.email = ?`, []any{identifier, identifier},
+		apperror.New("[IDENTIFIER_NOT_FOUND]", "username atau email salah", sql.ErrNoRows, http.StatusUnauthorized))
+}
+
+func (r *authRepository) FindByID(ctx context.Context, userID string) (*model.UserModel, error) {
+	return r.findUser(ctx, `u.id = ?`, []any{userID},
+		apperror.New(apperror.CodeUserNotFound, "user tidak ditemukan", sql.ErrNoRows))
+}
+
+func (r *authRepository) FindByEmail(ctx context.Context, email string) (*model.UserModel, error) {
+	return r.findUser(ctx, `u.email = ?`, []any{email},
+		apperror.New(apperror.CodeUserNotFound, "user tidak ditemukan", sql.ErrNoRows))
+}
+
+func (r *authRepository) FindByIdentity(ctx context.Context, provider, subject string) (*model.UserModel, error) {
+	return r.findUser(ctx, `u.id = (SELECT ui.user_id FROM user_identities ui WHERE ui.provider = ? AND ui.subject = ?)`,
+		[]any{provider, subject}, apperror.New(apperror.CodeUserNotFound, "user tidak ditemukan", sql.ErrNoRows))
+}
+
+// findUser mengambil data user untuk kebutuhan autentikasi (password, status kunci, MFA, passkey, identity dan roles)
+func (r *authRepository) findUser(ctx context.Context, where string, args []any, notFound error) (*model.UserModel, error) {
 	var user model.UserModel
 	var roles []model.RoleModel
 	err := dbtx.WithTxContext(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
-		const (
-			queryUsers = `SELECT id, password, email_verified, token_version FROM users WHERE username = ? OR email = ? LIMIT 1`
-			queryROles = `SELECT r.id, r.name FROM roles r JOIN user_roles ur ON ur.role_id = r.id WHERE ur.user_id = ?`
-		)
+		queryUsers := `
+				SELECT
+					u.id, u.username, u.email, u.password, u.email_verified, u.token_version,
+					u.failed_login_attempts, u.lockout_count, u.locked_until,
+					COALESCE(m.enabled, false),
+					(SELECT COUNT(*) FROM webauthn_credentials wc WHERE wc.user_id = u.id),
+					(SELECT COUNT(*) FROM user_identities ui WHERE ui.user_id = u.id)
+				FROM users u
+				LEFT JOIN user_mfa m ON m.user_id = u.id
+				WHERE ` + where + ` LIMIT 1`
+		const queryROles = `SELECT r.id, r.name FROM roles r JOIN user_roles ur ON ur.role_id = r.id WHERE ur.user_id = ?`
 
 		// query user
-		err := tx.QueryRowContext(ctx, queryUsers, identifier, identifier).
-			Scan(&user.ID, &user.Password, &user.EmailVerified, &user.TokenVersion)
+		var lockedUntil sql.NullTime
+		err := tx.QueryRowContext(ctx, queryUsers, args...).
+			Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.EmailVerified, &user.TokenVersion,
+				&user.FailedLoginAttempts, &user.LockoutCount, &lockedUntil, &user.MFAEnabled, &user.PasskeyCount, &user.IdentityCount)
 		if err != nil {
 			if err == sql.ErrNoRows {
-				return apperror.New("[IDENTIFIER_NOT_FOUND]", "username atau email salah", err, http.StatusUnauthorized)
+				return notFound
 			}
 
 			return apperror.New(apperror.CodeDBError, "query check identifier gagal", err)
 		}
+		if lockedUntil.Valid {
+			user.LockedUntil = &lockedUntil.Time
+		}
 
 		// query roles
 		rows, err := tx.QueryContext(ctx, queryROles, user.ID)
@@ -84,6 +127,103 @@ func (r *authRepository) UpdateTokenVersion(ctx context.Context, userID, newToke
 	return nil
 }
 
+func (r *authRepository) IncrementLoginFailure(ctx context.Context, userID string, window time.Duration) (*model.UserModel, error) {
+	const (
+		queryUpdate = `
+			UPDATE users
+			SET failed_login_attempts = IF(last_failed_login_at IS NULL OR last_failed_login_at < ?, 1, failed_login_attempts + 1),
+				last_failed_login_at = ?
+			WHERE id = ?`
+		querySelect = `SELECT failed_login_attempts, lockout_count FROM users WHERE id = ?`
+	)
+
+	now := time.Now()
+	user := model.UserModel{ID: userID}
+	err := dbtx.WithTxContext(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		// tambah jumlah percobaan gagal, reset jika percobaan terakhir sudah di luar window
+		if _, err := tx.ExecContext(ctx, queryUpdate, now.Add(-window), now, userID); err != nil {
+			return apperror.New(apperror.CodeDBError, "update percobaan login gagal", err)
+		}
+
+		// ambil jumlah terbaru
+		if err := tx.QueryRowContext(ctx, querySelect, userID).Scan(&user.FailedLoginAttempts, &user.LockoutCount); err != nil {
+			return apperror.New(apperror.CodeDBError, "query percobaan login gagal", err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (r *authRepository) LockAccount(ctx context.Context, userID string, lockoutCount int, lockedUntil time.Time) error {
+	const query = `UPDATE users SET failed_login_attempts = 0, lockout_count = ?, locked_until = ? WHERE id = ?`
+	if _, err := r.db.ExecContext(ctx, query, lockoutCount, lockedUntil, userID); err != nil {
+		return apperror.New(apperror.CodeDBError, "kunci akun gagal", err)
+	}
+
+	return nil
+}
+
+// ResetPassword mengganti password, token_version dan membuka kunci akun sekaligus mencabut semua session user
+func (r *authRepository) ResetPassword(ctx context.Context, userID, password, newTokenVersion string) error {
+	const (
+		queryUser = `
+			UPDATE users
+			SET password = ?, token_version = ?,
+				failed_login_attempts = 0, last_failed_login_at = NULL, lockout_count = 0, locked_until = NULL
+			WHERE id = ?`
+		querySessions = `UPDATE user_sessions SET revoked = true WHERE user_id = ?`
+	)
+
+	return dbtx.WithTxContext(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, queryUser, password, newTokenVersion, userID); err != nil {
+			return apperror.New(apperror.CodeDBError, "update password gagal", err)
+		}
+		if _, err := tx.ExecContext(ctx, querySessions, userID); err != nil {
+			return apperror.New(apperror.CodeDBError, "revoke semua sesi gagal", err)
+		}
+
+		return nil
+	})
+}
+
+func (r *authRepository) UpdatePassword(ctx context.Context, userID, password, newTokenVersion string) error {
+	const query = `UPDATE users SET password = ?, token_version = ? WHERE id = ?`
+	if _, err := r.db.ExecContext(ctx, query, password, newTokenVersion, userID); err != nil {
+		return apperror.New(apperror.CodeDBError, "update password gagal", err)
+	}
+
+	return nil
+}
+
+// UpdatePasswordHash mengganti hash password yang sama (rehash), token version dan session tidak berubah.
+// Kondisi hash lama mencegah menimpa password yang baru saja diganti di request lain
+func (r *authRepository) UpdatePasswordHash(ctx context.Context, userID, oldHash, newHash string) error {
+	const query = `UPDATE users SET password = ? WHERE id = ? AND password = ?`
+	if _, err := r.db.ExecContext(ctx, query, newHash, userID, oldHash); err != nil {
+		return apperror.New(apperror.CodeDBError, "update hash password gagal", err)
+	}
+
+	return nil
+}
+
+func (r *authRepository) ResetLoginFailure(ctx context.Context, userID string) error {
+	const query = `
+		UPDATE users
+		SET failed_login_attempts = 0, last_failed_login_at = NULL, lockout_count = 0, locked_until = NULL
+		WHERE id = ?`
+	if _, err := r.db.ExecContext(ctx, query, userID); err != nil {
+		return apperror.New(apperror.CodeDBError, "reset percobaan login gagal", err)
+	}
+
+	return nil
+}
+
 func (r *authRepository) Me(ctx context.Context, userID string) (*response.UserDetailResponse, error) {
 	const (
 		query = `
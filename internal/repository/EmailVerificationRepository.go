@@ -6,12 +6,16 @@ import (
 	"github.com/gogaruda/apperror"
 	"github.com/irawankilmer/auth-service/internal/model"
 	"net/http"
+	"time"
 )
 
 type EmailVerificationRepository interface {
 	Create(ctx context.Context, ev *model.EmailVerificationModel) error
 	FindByToken(ctx context.Context, token string) (*model.EmailVerificationModel, error)
 	MarkAsUsed(ctx context.Context, evID string) error
+	Claim(ctx context.Context, evID string) error
+	CountRecent(ctx context.Context, userID, actionType string, window time.Duration) (int, error)
+	MarkAllAsUsed(ctx context.Context, userID, actionType string) error
 	UpdateRegisterByAdmin(ctx context.Context, username, password, userID string) error
 }
 
@@ -24,8 +28,8 @@ func NewEmailVerificationRepository(db *sql.DB) EmailVerificationRepository {
 }
 
 func (r *emailVerificationRepository) Create(ctx context.Context, ev *model.EmailVerificationModel) error {
-	const query = `INSERT INTO email_verifications(id, user_id, token, expires_at) VALUES(?, ?, ?, ?)`
-	if _, err := r.db.ExecContext(ctx, query, ev.ID, ev.UserID, ev.Token, ev.ExpiresAt); err != nil {
+	const query = `INSERT INTO email_verifications(id, user_id, token, email, expires_at, action_type) VALUES(?, ?, ?, ?, ?, ?)`
+	if _, err := r.db.ExecContext(ctx, query, ev.ID, ev.UserID, ev.Token, ev.Email, ev.ExpiresAt, ev.ActionType); err != nil {
 		return apperror.New(apperror.CodeDBError, "query insert email_verifications gagal", err)
 	}
 
@@ -33,9 +37,9 @@ func (r *emailVerificationRepository) Create(ctx context.Context, ev *model.Emai
 }
 
 func (r *emailVerificationRepository) FindByToken(ctx context.Context, token string) (*model.EmailVerificationModel, error) {
-	const query = `SELECT id, user_id, expires_at, is_used FROM email_verifications WHERE token = ? LIMIT 1`
+	const query = `SELECT id, user_id, email, expires_at, is_used, action_type FROM email_verifications WHERE token = ? LIMIT 1`
 	var ev model.EmailVerificationModel
-	if err := r.db.QueryRowContext(ctx, query, token).Scan(&ev.ID, &ev.UserID, &ev.ExpiresAt, &ev.IsUsed); err != nil {
+	if err := r.db.QueryRowContext(ctx, query, token).Scan(&ev.ID, &ev.UserID, &ev.Email, &ev.ExpiresAt, &ev.IsUsed, &ev.ActionType); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, apperror.New("[TOKEN_NOT_FOUND]", "token tidak ditemukan", err, http.StatusUnauthorized)
 		}
@@ -55,6 +59,44 @@ func (r *emailVerificationRepository) MarkAsUsed(ctx context.Context, evID strin
 	return nil
 }
 
+// Claim menandai token sebagai terpakai secara atomik, token yang sudah terpakai tidak bisa diklaim lagi
+func (r *emailVerificationRepository) Claim(ctx context.Context, evID string) error {
+	const query = `UPDATE email_verifications SET is_used = true WHERE id = ? AND is_used = false`
+	result, err := r.db.ExecContext(ctx, query, evID)
+	if err != nil {
+		return apperror.New(apperror.CodeDBError, "query update is_used gagal", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return apperror.New(apperror.CodeDBError, "cek is_used gagal", err)
+	}
+	if affected == 0 {
+		return apperror.New("[TOKEN_IS_USED]", "token sudah digunakan", nil, http.StatusUnauthorized)
+	}
+
+	return nil
+}
+
+func (r *emailVerificationRepository) CountRecent(ctx context.Context, userID, actionType string, window time.Duration) (int, error) {
+	const query = `SELECT COUNT(*) FROM email_verifications WHERE user_id = ? AND action_type = ? AND created_at >= ?`
+	var total int
+	if err := r.db.QueryRowContext(ctx, query, userID, actionType, time.Now().Add(-window)).Scan(&total); err != nil {
+		return 0, apperror.New(apperror.CodeDBError, "query hitung email_verifications gagal", err)
+	}
+
+	return total, nil
+}
+
+func (r *emailVerificationRepository) MarkAllAsUsed(ctx context.Context, userID, actionType string) error {
+	const query = `UPDATE email_verifications SET is_used = true WHERE user_id = ? AND action_type = ? AND is_used = false`
+	if _, err := r.db.ExecContext(ctx, query, userID, actionType); err != nil {
+		return apperror.New(apperror.CodeDBError, "query update is_used gagal", err)
+	}
+
+	return nil
+}
+
 func (r *emailVerificationRepository) UpdateRegisterByAdmin(ctx context.Context, username, password, userID string) error {
 	const query = `UPDATE users SET username = ?, password = ?, email_verified = true WHERE id = ?`
 	if _, err := r.db.ExecContext(ctx, query, username, password, userID); err != nil {
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/gogaruda/apperror"
+	"github.com/gogaruda/dbtx"
+	"github.com/irawankilmer/auth-service/internal/model"
+	"time"
+)
+
+type LoginAttemptRepository interface {
+	Find(ctx context.Context, identifier, ipAddress string) (*model.LoginAttemptModel, error)
+	IncrementFailure(ctx context.Context, identifier, ipAddress string, window time.Duration) (*model.LoginAttemptModel, error)
+	Lock(ctx context.Context, identifier, ipAddress string, lockoutCount int, lockedUntil time.Time) error
+	Delete(ctx context.Context, identifier, ipAddress string) error
+	DeleteByIdentifiers(ctx context.Context, identifiers ...string) error
+	DeleteByUserID(ctx context.Context, userID string) error
+}
+
+type loginAttemptRepository struct {
+	db *sql.DB
+}
+
+func NewLoginAttemptRepository(db *sql.DB) LoginAttemptRepository {
+	return &loginAttemptRepository{db: db}
+}
+
+func (r *loginAttemptRepository) Find(ctx context.Context, identifier, ipAddress string) (*model.LoginAttemptModel, error) {
+	const query = `
+		SELECT failed_attempts, lockout_count, locked_until, last_failed_at
+		FROM login_attempts WHERE identifier = ? AND ip_address = ?`
+
+	attempt := model.LoginAttemptModel{Identifier: identifier, IPAddress: ipAddress}
+	var lockedUntil, lastFailedAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, query, identifier, ipAddress).
+		Scan(&attempt.FailedAttempts, &attempt.LockoutCount, &lockedUntil, &lastFailedAt)
+	if err != nil {
+		// belum pernah gagal, kembalikan data kosong
+		if err == sql.ErrNoRows {
+			return &attempt, nil
+		}
+
+		return nil, apperror.New(apperror.CodeDBError, "query login_attempts gagal", err)
+	}
+
+	if lockedUntil.Valid {
+		attempt.LockedUntil = &lockedUntil.Time
+	}
+	if lastFailedAt.Valid {
+		attempt.LastFailedAt = &lastFailedAt.Time
+	}
+
+	return &attempt, nil
+}
+
+func (r *loginAttemptRepository) IncrementFailure(ctx context.Context, identifier, ipAddress string, window time.Duration) (*model.LoginAttemptModel, error) {
+	const (
+		queryUpsert = `
+			INSERT INTO login_attempts(identifier, ip_address, failed_attempts, last_failed_at)
+			VALUES(?, ?, 1, ?)
+			ON DUPLICATE KEY UPDATE
+				failed_attempts = IF(last_failed_at IS NULL OR last_failed_at < ?, 1, failed_attempts + 1),
+				last_failed_at = VALUES(last_failed_at)`
+		querySelect = `SELECT failed_attempts, lockout_count FROM login_attempts WHERE identifier = ? AND ip_address = ?`
+	)
+
+	now := time.Now()
+	attempt := model.LoginAttemptModel{Identifier: identifier, IPAddress: ipAddress, LastFailedAt: &now}
+	err := dbtx.WithTxContext(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		// tambah jumlah percobaan gagal, reset jika percobaan terakhir sudah di luar window
+		if _, err := tx.ExecContext(ctx, queryUpsert, identifier, ipAddress, now, now.Add(-window)); err != nil {
+			return apperror.New(apperror.CodeDBError, "upsert login_attempts gagal", err)
+		}
+
+		// ambil jumlah terbaru
+		if err := tx.QueryRowContext(ctx, querySelect, identifier, ipAddress).
+			Scan(&attempt.FailedAttempts, &attempt.LockoutCount); err != nil {
+			return apperror.New(apperror.CodeDBError, "query login_attempts gagal", err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &attempt, nil
+}
+
+func (r *loginAttemptRepository) Lock(ctx context.Context, identifier, ipAddress string, lockoutCount int, lockedUntil time.Time) error {
+	const query = `
+		UPDATE login_attempts SET failed_attempts = 0, lockout_count = ?, locked_until = ?
+		WHERE identifier = ? AND ip_address = ?`
+	if _, err := r.db.ExecContext(ctx, query, lockoutCount, lockedUntil, identifier, ipAddress); err != nil {
+		return apperror.New(apperror.CodeDBError, "kunci login_attempts gagal", err)
+	}
+
+	return nil
+}
+
+func (r *loginAttemptRepository) Delete(ctx context.Context, identifier, ipAddress string) error {
+	const query = `DELETE FROM login_attempts WHERE identifier = ? AND ip_address = ?`
+	if _, err := r.db.ExecContext(ctx, query, identifier, ipAddress); err != nil {
+		return apperror.New(apperror.CodeDBError, "hapus login_attempts gagal", err)
+	}
+
+	return nil
+}
+
+func (r *loginAttemptRepository) DeleteByIdentifiers(ctx context.Context, identifiers ...string) error {
+	const query = `DELETE FROM login_attempts WHERE identifier = ?`
+	return dbtx.WithTxContext(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		for _, identifier := range identifiers {
+			if _, err := tx.ExecContext(ctx, query, identifier); err != nil {
+				return apperror.New(apperror.CodeDBError, "hapus login_attempts gagal", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// DeleteByUserID menghapus kunci identifier yang memakai format "<jenis>:<user_id>"
+// (password, mfa, passkey, magic_link dan provider login eksternal)
+func (r *loginAttemptRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	const query = `DELETE FROM login_attempts WHERE identifier LIKE ?`
+	if _, err := r.db.ExecContext(ctx, query, "%:"+userID); err != nil {
+		return apperror.New(apperror.CodeDBError, "hapus login_attempts gagal", err)
+	}
+
+	return nil
+}
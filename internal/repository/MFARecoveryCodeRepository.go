@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/gogaruda/apperror"
+	"github.com/gogaruda/dbtx"
+	"github.com/irawankilmer/auth-service/internal/model"
+)
+
+type MFARecoveryCodeRepository interface {
+	Replace(ctx context.Context, userID string, codes []model.MFARecoveryCodeModel) error
+	Use(ctx context.Context, userID, codeHash, ipAddress, userAgent string) (bool, error)
+	CountUnused(ctx context.Context, userID string) (int, error)
+	DeleteByUserID(ctx context.Context, userID string) error
+}
+
+type mfaRecoveryCodeRepository struct {
+	db *sql.DB
+}
+
+func NewMFARecoveryCodeRepository(db *sql.DB) MFARecoveryCodeRepository {
+	return &mfaRecoveryCodeRepository{db: db}
+}
+
+// Replace menghapus semua recovery code lama lalu menyimpan yang baru dalam satu transaksi
+func (r *mfaRecoveryCodeRepository) Replace(ctx context.Context, userID string, codes []model.MFARecoveryCodeModel) error {
+	return dbtx.WithTxContext(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		const (
+			queryDelete = `DELETE FROM mfa_recovery_codes WHERE user_id = ?`
+			queryInsert = `INSERT INTO mfa_recovery_codes(id, user_id, code_hash) VALUES(?, ?, ?)`
+		)
+
+		// hapus kode lama
+		if _, err := tx.ExecContext(ctx, queryDelete, userID); err != nil {
+			return apperror.New(apperror.CodeDBError, "hapus recovery code lama gagal", err)
+		}
+
+		// prepare kode baru
+		stmt, err := tx.PrepareContext(ctx, queryInsert)
+		if err != nil {
+			return apperror.New(apperror.CodeDBPrepareError, "prepare recovery code gagal", err)
+		}
+		defer stmt.Close()
+
+		// insert kode baru
+		for _, code := range codes {
+			if _, err := stmt.ExecContext(ctx, code.ID, userID, code.CodeHash); err != nil {
+				return apperror.New(apperror.CodeDBError, "insert recovery code gagal", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// Use menandai recovery code sudah dipakai, false jika kode tidak ada atau sudah pernah dipakai
+func (r *mfaRecoveryCodeRepository) Use(ctx context.Context, userID, codeHash, ipAddress, userAgent string) (bool, error) {
+	const query = `
+		UPDATE mfa_recovery_codes SET used_at = NOW(), used_ip_address = ?, used_user_agent = ?
+		WHERE user_id = ? AND code_hash = ? AND used_at IS NULL`
+	result, err := r.db.ExecContext(ctx, query, ipAddress, userAgent, userID, codeHash)
+	if err != nil {
+		return false, apperror.New(apperror.CodeDBError, "update recovery code gagal", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, apperror.New(apperror.CodeDBError, "cek recovery code gagal", err)
+	}
+
+	return affected == 1, nil
+}
+
+func (r *mfaRecoveryCodeRepository) CountUnused(ctx context.Context, userID string) (int, error) {
+	const query = `SELECT COUNT(*) FROM mfa_recovery_codes WHERE user_id = ? AND used_at IS NULL`
+	var total int
+	if err := r.db.QueryRowContext(ctx, query, userID).Scan(&total); err != nil {
+		return 0, apperror.New(apperror.CodeDBError, "hitung recovery code gagal", err)
+	}
+
+	return total, nil
+}
+
+func (r *mfaRecoveryCodeRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	const query = `DELETE FROM mfa_recovery_codes WHERE user_id = ?`
+	if _, err := r.db.ExecContext(ctx, query, userID); err != nil {
+		return apperror.New(apperror.CodeDBError, "hapus recovery code gagal", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/gogaruda/apperror"
+	"github.com/irawankilmer/auth-service/internal/model"
+	"net/http"
+)
+
+type MFARepository interface {
+	FindByUserID(ctx context.Context, userID string) (*model.MFAModel, error)
+	SavePending(ctx context.Context, userID, secretEncrypted string) error
+	Enable(ctx context.Context, userID string, step int64) error
+	UseStep(ctx context.Context, userID string, step int64) (bool, error)
+	Delete(ctx context.Context, userID string) error
+}
+
+type mfaRepository struct {
+	db *sql.DB
+}
+
+func NewMFARepository(db *sql.DB) MFARepository {
+	return &mfaRepository{db: db}
+}
+
+func (r *mfaRepository) FindByUserID(ctx context.Context, userID string) (*model.MFAModel, error) {
+	const query = `SELECT user_id, secret_encrypted, enabled, last_used_step, confirmed_at FROM user_mfa WHERE user_id = ?`
+	var mfa model.MFAModel
+	var confirmedAt sql.NullTime
+	if err := r.db.QueryRowContext(ctx, query, userID).Scan(
+		&mfa.UserID, &mfa.SecretEncrypted, &mfa.Enabled, &mfa.LastUsedStep, &confirmedAt,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperror.New("[MFA_NOT_FOUND]", "MFA belum didaftarkan", err, http.StatusNotFound)
+		}
+
+		return nil, apperror.New(apperror.CodeDBError, "query user_mfa gagal", err)
+	}
+
+	if confirmedAt.Valid {
+		mfa.ConfirmedAt = &confirmedAt.Time
+	}
+
+	return &mfa, nil
+}
+
+// SavePending menyimpan secret baru yang belum dikonfirmasi, menimpa pendaftaran sebelumnya yang belum aktif
+func (r *mfaRepository) SavePending(ctx context.Context, userID, secretEncrypted string) error {
+	const query = `
+		INSERT INTO user_mfa(user_id, secret_encrypted, enabled, last_used_step)
+		VALUES(?, ?, false, 0)
+		ON DUPLICATE KEY UPDATE secret_encrypted = VALUES(secret_encrypted), last_used_step = 0, confirmed_at = NULL`
+	if _, err := r.db.ExecContext(ctx, query, userID, secretEncrypted); err != nil {
+		return apperror.New(apperror.CodeDBError, "simpan user_mfa gagal", err)
+	}
+
+	return nil
+}
+
+func (r *mfaRepository) Enable(ctx context.Context, userID string, step int64) error {
+	const query = `UPDATE user_mfa SET enabled = true, last_used_step = ?, confirmed_at = NOW() WHERE user_id = ?`
+	if _, err := r.db.ExecContext(ctx, query, step, userID); err != nil {
+		return apperror.New(apperror.CodeDBError, "aktivasi MFA gagal", err)
+	}
+
+	return nil
+}
+
+// UseStep menandai time step sudah dipakai, false jika step tersebut (atau yang lebih baru) sudah pernah dipakai
+func (r *mfaRepository) UseStep(ctx context.Context, userID string, step int64) (bool, error) {
+	const query = `UPDATE user_mfa SET last_used_step = ? WHERE user_id = ? AND last_used_step < ?`
+	result, err := r.db.ExecContext(ctx, query, step, userID, step)
+	if err != nil {
+		return false, apperror.New(apperror.CodeDBError, "update last_used_step gagal", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, apperror.New(apperror.CodeDBError, "cek last_used_step gagal", err)
+	}
+
+	return affected == 1, nil
+}
+
+func (r *mfaRepository) Delete(ctx context.Context, userID string) error {
+	const query = `DELETE FROM user_mfa WHERE user_id = ?`
+	if _, err := r.db.ExecContext(ctx, query, userID); err != nil {
+		return apperror.New(apperror.CodeDBError, "hapus user_mfa gagal", err)
+	}
+
+	return nil
+}
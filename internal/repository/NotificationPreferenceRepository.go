@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/gogaruda/apperror"
+	"github.com/gogaruda/dbtx"
+)
+
+type NotificationPreferenceRepository interface {
+	FindByUserID(ctx context.Context, userID string) (map[string]bool, error)
+	Upsert(ctx context.Context, userID string, prefs map[string]bool) error
+}
+
+type notificationPreferenceRepository struct {
+	db *sql.DB
+}
+
+func NewNotificationPreferenceRepository(db *sql.DB) NotificationPreferenceRepository {
+	return &notificationPreferenceRepository{db: db}
+}
+
+// FindByUserID hanya mengembalikan jenis yang pernah diatur user
+func (r *notificationPreferenceRepository) FindByUserID(ctx context.Context, userID string) (map[string]bool, error) {
+	const query = `SELECT notification_type, enabled FROM notification_preferences WHERE user_id = ?`
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, apperror.New(apperror.CodeDBError, "query notification preferences gagal", err)
+	}
+	defer rows.Close()
+
+	prefs := map[string]bool{}
+	for rows.Next() {
+		var (
+			notificationType string
+			enabled          bool
+		)
+		if err := rows.Scan(&notificationType, &enabled); err != nil {
+			return nil, apperror.New(apperror.CodeDBError, "scan notification preferences gagal", err)
+		}
+		prefs[notificationType] = enabled
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, apperror.New(apperror.CodeDBError, "gagal setelah iterasi", err)
+	}
+
+	return prefs, nil
+}
+
+func (r *notificationPreferenceRepository) Upsert(ctx context.Context, userID string, prefs map[string]bool) error {
+	const query = `INSERT INTO notification_preferences (user_id, notification_type, enabled) VALUES (?, ?, ?)
+									ON DUPLICATE KEY UPDATE enabled = VALUES(enabled)`
+
+	return dbtx.WithTxContext(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		for notificationType, enabled := range prefs {
+			if _, err := tx.ExecContext(ctx, query, userID, notificationType, enabled); err != nil {
+				return apperror.New(apperror.CodeDBError, "simpan notification preferences gagal", err)
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/gogaruda/apperror"
+	"github.com/irawankilmer/auth-service/internal/model"
+	"net/http"
+	"time"
+)
+
+type ProfileRepository interface {
+	FindByUserID(ctx context.Context, userID string) (*model.ProfileModel, error)
+	Update(ctx context.Context, profile *model.ProfileModel) error
+}
+
+type profileRepository struct {
+	db *sql.DB
+}
+
+func NewProfileRepository(db *sql.DB) ProfileRepository {
+	return &profileRepository{db: db}
+}
+
+func (r *profileRepository) FindByUserID(ctx context.Context, userID string) (*model.ProfileModel, error) {
+	const query = `SELECT id, user_id, full_name, address, gender, image, updated_at FROM profiles WHERE user_id = ?`
+	var profile model.ProfileModel
+	if err := r.db.QueryRowContext(ctx, query, userID).Scan(
+		&profile.ID, &profile.UserID, &profile.FullName, &profile.Address, &profile.Gender, &profile.Image, &profile.UpdatedAt,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperror.New("[PROFILE_NOT_FOUND]", "profil tidak ditemukan", err, http.StatusNotFound)
+		}
+
+		return nil, apperror.New(apperror.CodeDBError, "query profiles gagal", err)
+	}
+
+	return &profile, nil
+}
+
+func (r *profileRepository) Update(ctx context.Context, profile *model.ProfileModel) error {
+	const query = `UPDATE profiles SET full_name = ?, address = ?, gender = ?, image = ?, updated_at = ? WHERE id = ?`
+	profile.UpdatedAt = time.Now().Truncate(time.Second)
+	if _, err := r.db.ExecContext(ctx, query,
+		profile.FullName, profile.Address, profile.Gender, profile.Image, profile.UpdatedAt, profile.ID,
+	); err != nil {
+		return apperror.New(apperror.CodeDBError, "update profiles gagal", err)
+	}
+
+	return nil
+}
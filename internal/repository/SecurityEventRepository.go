@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"github.com/gogaruda/apperror"
+	"github.com/irawankilmer/auth-service/internal/model"
+)
+
+type SecurityEventRepository interface {
+	Create(ctx context.Context, event *model.SecurityEventModel) error
+}
+
+type securityEventRepository struct {
+	db *sql.DB
+}
+
+func NewSecurityEventRepository(db *sql.DB) SecurityEventRepository {
+	return &securityEventRepository{db: db}
+}
+
+func (r *securityEventRepository) Create(ctx context.Context, event *model.SecurityEventModel) error {
+	const query = `INSERT INTO security_events (id, user_id, event_type, ip_address, user_agent, metadata, created_at)
+									VALUES(?, ?, ?, ?, ?, ?, ?)`
+
+	var metadata []byte
+	if len(event.Metadata) > 0 {
+		encoded, err := json.Marshal(event.Metadata)
+		if err != nil {
+			return apperror.New(apperror.CodeInternalError, "encode metadata security event gagal", err)
+		}
+		metadata = encoded
+	}
+
+	if _, err := r.db.ExecContext(ctx, query,
+		event.ID, event.UserID, event.EventType, event.IPAddress, event.UserAgent, metadata, event.CreatedAt,
+	); err != nil {
+		return apperror.New(apperror.CodeDBError, "simpan security event gagal", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/gogaruda/apperror"
+	"github.com/irawankilmer/auth-service/internal/model"
+	"net/http"
+	"time"
+)
+
+type UserDeviceRepository interface {
+	Upsert(ctx context.Context, device *model.UserDeviceModel) error
+	FindByDeviceID(ctx context.Context, userID, deviceID string) (*model.UserDeviceModel, error)
+	FindByID(ctx context.Context, userID, id string) (*model.UserDeviceModel, error)
+	FindByUserID(ctx context.Context, userID string) ([]model.UserDeviceModel, error)
+	UpdateName(ctx context.Context, userID, id string, name *string) error
+	UpdateTrustedUntil(ctx context.Context, userID, id string, trustedUntil *time.Time) error
+	UntrustAll(ctx context.Context, userID string) error
+}
+
+type userDeviceRepository struct {
+	db *sql.DB
+}
+
+func NewUserDeviceRepository(db *sql.DB) UserDeviceRepository {
+	return &userDeviceRepository{db: db}
+}
+
+const userDeviceColumns = `id, user_id, device_id, name, COALESCE(browser, ''), COALESCE(browser_version, ''),
+	COALESCE(os, ''), COALESCE(os_version, ''), device_type, COALESCE(device_model, ''), COALESCE(last_ip, ''),
+	trusted_until, last_seen_at, created_at`
+
+// Upsert mencatat perangkat saat login/refresh. TrustedUntil nil tidak mengubah status tepercaya yang sudah ada
+func (r *userDeviceRepository) Upsert(ctx context.Context, d *model.UserDeviceModel) error {
+	const query = `
+		INSERT INTO user_devices
+			(id, user_id, device_id, browser, browser_version, os, os_version, device_type, device_model, last_ip, trusted_until, last_seen_at)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			browser = VALUES(browser), browser_version = VALUES(browser_version), os = VALUES(os), os_version = VALUES(os_version),
+			device_type = VALUES(device_type), device_model = VALUES(device_model), last_ip = VALUES(last_ip),
+			trusted_until = COALESCE(VALUES(trusted_until), trusted_until), last_seen_at = VALUES(last_seen_at)`
+	if _, err := r.db.ExecContext(ctx, query,
+		d.ID, d.UserID, d.DeviceID, d.Browser, d.BrowserVersion, d.OS, d.OSVersion, d.DeviceType, d.DeviceModel, d.LastIP,
+		d.TrustedUntil, d.LastSeenAt,
+	); err != nil {
+		return apperror.New(apperror.CodeDBError, "simpan user devices gagal", err)
+	}
+
+	return nil
+}
+
+func (r *userDeviceRepository) FindByDeviceID(ctx context.Context, userID, deviceID string) (*model.UserDeviceModel, error) {
+	query := `SELECT ` + userDeviceColumns + ` FROM user_devices WHERE user_id = ? AND device_id = ?`
+	return scanUserDevice(r.db.QueryRowContext(ctx, query, userID, deviceID))
+}
+
+func (r *userDeviceRepository) FindByID(ctx context.Context, userID, id string) (*model.UserDeviceModel, error) {
+	query := `SELECT ` + userDeviceColumns + ` FROM user_devices WHERE user_id = ? AND id = ?`
+	return scanUserDevice(r.db.QueryRowContext(ctx, query, userID, id))
+}
+
+func (r *userDeviceRepository) FindByUserID(ctx context.Context, userID string) ([]model.UserDeviceModel, error) {
+	query := `SELECT ` + userDeviceColumns + ` FROM user_devices WHERE user_id = ? ORDER BY last_seen_at DESC`
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, apperror.New(apperror.CodeDBError, "query user devices gagal", err)
+	}
+	defer rows.Close()
+
+	var devices []model.UserDeviceModel
+	for rows.Next() {
+		device, err := scanUserDevice(rows)
+		if err != nil {
+			return nil, err
+		}
+		devices = append(devices, *device)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, apperror.New(apperror.CodeDBError, "gagal setelah iterasi", err)
+	}
+
+	return devices, nil
+}
+
+func (r *userDeviceRepository) UpdateName(ctx context.Context, userID, id string, name *string) error {
+	const query = `UPDATE user_devices SET name = ? WHERE id = ? AND user_id = ?`
+	if _, err := r.db.ExecContext(ctx, query, name, id, userID); err != nil {
+		return apperror.New(apperror.CodeDBError, "update nama perangkat gagal", err)
+	}
+
+	return nil
+}
+
+func (r *userDeviceRepository) UpdateTrustedUntil(ctx context.Context, userID, id string, trustedUntil *time.Time) error {
+	const query = `UPDATE user_devices SET trusted_until = ? WHERE id = ? AND user_id = ?`
+	if _, err := r.db.ExecContext(ctx, query, trustedUntil, id, userID); err != nil {
+		return apperror.New(apperror.CodeDBError, "update perangkat tepercaya gagal", err)
+	}
+
+	return nil
+}
+
+func (r *userDeviceRepository) UntrustAll(ctx context.Context, userID string) error {
+	const query = `UPDATE user_devices SET trusted_until = NULL WHERE user_id = ?`
+	if _, err := r.db.ExecContext(ctx, query, userID); err != nil {
+		return apperror.New(apperror.CodeDBError, "update perangkat tepercaya gagal", err)
+	}
+
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanUserDevice(row rowScanner) (*model.UserDeviceModel, error) {
+	var d model.UserDeviceModel
+	if err := row.Scan(
+		&d.ID, &d.UserID, &d.DeviceID, &d.Name, &d.Browser, &d.BrowserVersion, &d.OS, &d.OSVersion,
+		&d.DeviceType, &d.DeviceModel, &d.LastIP, &d.TrustedUntil, &d.LastSeenAt, &d.CreatedAt,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperror.New("[DEVICE_NOT_FOUND]", "perangkat tidak ditemukan", err, http.StatusNotFound)
+		}
+
+		return nil, apperror.New(apperror.CodeDBError, "scan user devices gagal", err)
+	}
+
+	return &d, nil
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/go-sql-driver/mysql"
+	"github.com/gogaruda/apperror"
+	"github.com/irawankilmer/auth-service/internal/model"
+	"net/http"
+)
+
+type UserIdentityRepository interface {
+	FindByUserID(ctx context.Context, userID string) ([]model.UserIdentityModel, error)
+	Create(ctx context.Context, identity *model.UserIdentityModel) error
+	Delete(ctx context.Context, userID, provider string) error
+}
+
+type userIdentityRepository struct {
+	db *sql.DB
+}
+
+func NewUserIdentityRepository(db *sql.DB) UserIdentityRepository {
+	return &userIdentityRepository{db: db}
+}
+
+func (r *userIdentityRepository) FindByUserID(ctx context.Context, userID string) ([]model.UserIdentityModel, error) {
+	const query = `
+		SELECT user_id, provider, subject, email, linked_at
+		FROM user_identities WHERE user_id = ? ORDER BY provider`
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, apperror.New(apperror.CodeDBError, "query user_identities gagal", err)
+	}
+	defer rows.Close()
+
+	var identities []model.UserIdentityModel
+	for rows.Next() {
+		var identity model.UserIdentityModel
+		if err := rows.Scan(&identity.UserID, &identity.Provider, &identity.Subject, &identity.Email, &identity.LinkedAt); err != nil {
+			return nil, apperror.New(apperror.CodeDBError, "user_identities gagal scan", err)
+		}
+
+		identities = append(identities, identity)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, apperror.New(apperror.CodeDBError, "gagal setelah iterasi user_identities", err)
+	}
+
+	return identities, nil
+}
+
+func (r *userIdentityRepository) Create(ctx context.Context, identity *model.UserIdentityModel) error {
+	const query = `INSERT INTO user_identities(user_id, provider, subject, email) VALUES(?, ?, ?, ?)`
+	if _, err := r.db.ExecContext(ctx, query, identity.UserID, identity.Provider, identity.Subject, identity.Email); err != nil {
+		// akun provider sudah dipakai user lain atau user sudah punya akun di provider yang sama
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+			return apperror.New("[IDENTITY_CONFLICT]", "akun "+identity.Provider+" sudah terhubung", err, http.StatusConflict)
+		}
+
+		return apperror.New(apperror.CodeDBError, "insert user_identities gagal", err)
+	}
+
+	return nil
+}
+
+func (r *userIdentityRepository) Delete(ctx context.Context, userID, provider string) error {
+	const query = `DELETE FROM user_identities WHERE user_id = ? AND provider = ?`
+	result, err := r.db.ExecContext(ctx, query, userID, provider)
+	if err != nil {
+		return apperror.New(apperror.CodeDBError, "hapus user_identities gagal", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return apperror.New(apperror.CodeDBError, "cek user_identities gagal", err)
+	}
+	if affected == 0 {
+		return apperror.New("[IDENTITY_NOT_FOUND]", "akun "+provider+" belum terhubung", sql.ErrNoRows, http.StatusNotFound)
+	}
+
+	return nil
+}
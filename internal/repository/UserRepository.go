@@ -4,11 +4,14 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"github.com/go-sql-driver/mysql"
 	"github.com/gogaruda/apperror"
 	"github.com/gogaruda/dbtx"
 	"github.com/irawankilmer/auth-service/internal/dto/response"
 	"github.com/irawankilmer/auth-service/internal/model"
 	"net/http"
+	"strings"
+	"time"
 )
 
 type UserRepository interface {
@@ -16,12 +19,15 @@ type UserRepository interface {
 	FindUserByTokenVersion(ctx context.Context, userID string) (*model.UserModel, error)
 	CheckUsername(ctx context.Context, username string) (bool, error)
 	UsernameChange(ctx context.Context, user *response.UserDetailResponse, newUsername string) (bool, error)
+	FindUsername(ctx context.Context, userID string) (*model.UserModel, error)
+	UsernameUpdate(ctx context.Context, user *model.UserModel, newUsername string) error
 	CheckEmail(ctx context.Context, email string) (bool, error)
 	EmailChange(ctx context.Context, user *response.UserDetailResponse, newEmail string) (bool, error)
 	Create(ctx context.Context, user *model.UserModel) error
 	FindByID(ctx context.Context, userID string) (*response.UserDetailResponse, error)
 	EmailUpdate(ctx context.Context, user *response.UserDetailResponse, newEmail string) error
-	RoleUpdate(ctx context.Context, user *response.UserDetailResponse, newRoles []model.RoleModel) error
+	EmailRevert(ctx context.Context, userID, currentEmail, oldEmail string) error
+	RoleUpdate(ctx context.Context, user *response.UserDetailResponse, newRoles []model.RoleModel, newTokenVersion string) error
 	UpdateEmailVerified(ctx context.Context, user *response.UserDetailResponse) error
 	Delete(ctx context.Context, user *response.UserDetailResponse) error
 }
@@ -174,6 +180,52 @@ func (r *userRepository) UsernameChange(ctx context.Context, user *response.User
 	return exists, nil
 }
 
+func (r *userRepository) FindUsername(ctx context.Context, userID string) (*model.UserModel, error) {
+	const query = `SELECT id, username, username_changed_at FROM users WHERE id = ?`
+	var user model.UserModel
+	var changedAt sql.NullTime
+	if err := r.db.QueryRowContext(ctx, query, userID).Scan(&user.ID, &user.Username, &changedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperror.New(apperror.CodeUserNotFound, "user tidak ditemukan", err)
+		}
+
+		return nil, apperror.New(apperror.CodeDBError, "query username gagal", err)
+	}
+	if changedAt.Valid {
+		user.UsernameChangedAt = &changedAt.Time
+	}
+
+	return &user, nil
+}
+
+// UsernameUpdate mengganti username, username lama masuk username_history agar tidak bisa dipakai lagi
+func (r *userRepository) UsernameUpdate(ctx context.Context, user *model.UserModel, newUsername string) error {
+	return dbtx.WithTxContext(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		const (
+			queryUser            = `UPDATE users SET username = ?, username_changed_at = ? WHERE id = ?`
+			queryUsernameHistory = `INSERT INTO username_history(username) VALUES(?)`
+		)
+
+		// update username, username yang baru saja dipakai user lain ditolak unique index
+		if _, err := tx.ExecContext(ctx, queryUser, newUsername, time.Now(), user.ID); err != nil {
+			var mysqlErr *mysql.MySQLError
+			if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+				return apperror.New(apperror.CodeUsernameConflict, "username tidak dapat digunakan", err)
+			}
+
+			return apperror.New(apperror.CodeDBError, "update username gagal", err)
+		}
+
+		// insert username lama
+		if user.Username != nil {
+			if _, err := tx.ExecContext(ctx, queryUsernameHistory, *user.Username); err != nil {
+				return apperror.New(apperror.CodeDBError, "insert username_history gagal", err)
+			}
+		}
+		return nil
+	})
+}
+
 func (r *userRepository) CheckEmail(ctx context.Context, email string) (bool, error) {
 	const query = `SELECT exists(SELECT 1 FROM users WHERE email = ?)`
 	var exists bool
@@ -201,9 +253,10 @@ func (r *userRepository) Create(ctx context.Context, user *model.UserModel) erro
 		const (
 			queryUser = `
 									INSERT INTO 
-									users(id, username, email, password, token_version, email_verified, created_by_admin, google_id)
-									VALUES(?, ?, ?, ?, ?, ?, ?, ?)`
+									users(id, username, email, password, token_version, email_verified, created_by_admin)
+									VALUES(?, ?, ?, ?, ?, ?, ?)`
 			queryUserRoles = `INSERT INTO user_roles(user_id, role_id) VALUES(?, ?)`
+			queryIdentity  = `INSERT INTO user_identities(user_id, provider, subject, email) VALUES(?, ?, ?, ?)`
 			queryProfile   = `
 											INSERT INTO 
 											profiles(id, user_id, full_name, address, gender, image)
@@ -213,7 +266,7 @@ func (r *userRepository) Create(ctx context.Context, user *model.UserModel) erro
 		// create user
 		_, err := tx.ExecContext(ctx, queryUser,
 			user.ID, user.Username, user.Email, user.Password, user.TokenVersion,
-			user.EmailVerified, user.CreatedByAdmin, user.GoogleID,
+			user.EmailVerified, user.CreatedByAdmin,
 		)
 		if err != nil {
 			return apperror.New(apperror.CodeDBError, "create user gagal", err)
@@ -241,6 +294,13 @@ func (r *userRepository) Create(ctx context.Context, user *model.UserModel) erro
 				return apperror.New(apperror.CodeDBError, "create relation user_roles gagal", err)
 			}
 		}
+
+		// create identity untuk user dari provider eksternal
+		for _, identity := range user.Identities {
+			if _, err := tx.ExecContext(ctx, queryIdentity, user.ID, identity.Provider, identity.Subject, identity.Email); err != nil {
+				return apperror.New(apperror.CodeDBError, "create user_identities gagal", err)
+			}
+		}
 		return nil
 	})
 }
@@ -249,7 +309,8 @@ func (r *userRepository) FindByID(ctx context.Context, userID string) (*response
 	const (
 		queryUserWithProfile = `
 			SELECT 
-				u.id, u.username, u.email, u.email_verified, u.created_by_admin, u.google_id,
+				u.id, u.username, u.email, u.email_verified, u.created_by_admin,
+				(SELECT GROUP_CONCAT(ui.provider ORDER BY ui.provider) FROM user_identities ui WHERE ui.user_id = u.id),
 				p.id AS profile_id, p.full_name, p.address, p.gender, p.image
 			FROM users u
 			LEFT JOIN profiles p ON p.user_id = u.id
@@ -272,14 +333,14 @@ func (r *userRepository) FindByID(ctx context.Context, userID string) (*response
 	// Variabel scan
 	var (
 		id, email, profileID          string
-		username, googleID, fullName  sql.NullString
+		username, providers, fullName sql.NullString
 		emailVerified, createdByAdmin bool
 		address, gender, image        sql.NullString
 	)
 
 	// Ambil user + profile
 	err := r.db.QueryRowContext(ctx, queryUserWithProfile, userID, "admin", "super admin").Scan(
-		&id, &username, &email, &emailVerified, &createdByAdmin, &googleID,
+		&id, &username, &email, &emailVerified, &createdByAdmin, &providers,
 		&profileID, &fullName, &address, &gender, &image,
 	)
 	if err != nil {
@@ -295,6 +356,7 @@ func (r *userRepository) FindByID(ctx context.Context, userID string) (*response
 		Email:          email,
 		EmailVerified:  emailVerified,
 		CreatedByAdmin: createdByAdmin,
+		Providers:      []string{},
 		Roles:          []response.RoleResponse{},
 		Profile: response.ProfileDetailResponse{
 			ID: profileID,
@@ -305,8 +367,8 @@ func (r *userRepository) FindByID(ctx context.Context, userID string) (*response
 	if username.Valid {
 		user.Username = &username.String
 	}
-	if googleID.Valid {
-		user.GoogleID = &googleID.String
+	if providers.Valid {
+		user.Providers = strings.Split(providers.String, ",")
 	}
 	if fullName.Valid {
 		user.Profile.FullName = &fullName.String
@@ -365,11 +427,39 @@ func (r *userRepository) EmailUpdate(ctx context.Context, user *response.UserDet
 	})
 }
 
-func (r *userRepository) RoleUpdate(ctx context.Context, user *response.UserDetailResponse, newRoles []model.RoleModel) error {
+// EmailRevert mengembalikan email lama yang sudah masuk email_history, email saat ini gantian masuk email_history
+func (r *userRepository) EmailRevert(ctx context.Context, userID, currentEmail, oldEmail string) error {
+	return dbtx.WithTxContext(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		const (
+			queryDeleteHistory = `DELETE FROM email_history WHERE email = ?`
+			queryUser          = `UPDATE users SET email = ? WHERE id = ?`
+			queryEmailHistory  = `INSERT INTO email_history(email) VALUES(?)`
+		)
+
+		// lepas email lama dari history
+		if _, err := tx.ExecContext(ctx, queryDeleteHistory, oldEmail); err != nil {
+			return apperror.New(apperror.CodeDBError, "hapus email_history gagal", err)
+		}
+
+		// kembalikan email
+		if _, err := tx.ExecContext(ctx, queryUser, oldEmail, userID); err != nil {
+			return apperror.New(apperror.CodeDBError, "update email gagal", err)
+		}
+
+		// insert email
+		if _, err := tx.ExecContext(ctx, queryEmailHistory, currentEmail); err != nil {
+			return apperror.New(apperror.CodeDBError, "insert email_history gagal", err)
+		}
+		return nil
+	})
+}
+
+func (r *userRepository) RoleUpdate(ctx context.Context, user *response.UserDetailResponse, newRoles []model.RoleModel, newTokenVersion string) error {
 	return dbtx.WithTxContext(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
 		const (
-			queryDelete = `DELETE FROM user_roles WHERE user_id = ?`
-			queryInsert = `INSERT INTO user_roles(user_id, role_id) VALUES(?, ?)`
+			queryDelete       = `DELETE FROM user_roles WHERE user_id = ?`
+			queryInsert       = `INSERT INTO user_roles(user_id, role_id) VALUES(?, ?)`
+			queryTokenVersion = `UPDATE users SET token_version = ? WHERE id = ?`
 		)
 
 		// hapus semua roles lama
@@ -396,6 +486,11 @@ func (r *userRepository) RoleUpdate(ctx context.Context, user *response.UserDeta
 			}
 		}
 
+		// role ada di klaim access token, token lama harus ditolak
+		if _, err := tx.ExecContext(ctx, queryTokenVersion, newTokenVersion, user.ID); err != nil {
+			return apperror.New(apperror.CodeDBError, "update token_version gagal", err)
+		}
+
 		return nil
 	})
 }
@@ -7,6 +7,8 @@ import (
 	"github.com/gogaruda/dbtx"
 	"github.com/irawankilmer/auth-service/internal/model"
 	"net/http"
+	"strings"
+	"time"
 )
 
 type UserSessionRepository interface {
@@ -15,6 +17,13 @@ type UserSessionRepository interface {
 	GetTokenVersionByUserID(ctx context.Context, userID string) (*model.UserModel, error)
 	Revoked(ctx context.Context, usID string) error
 	RevokeAllSessionByUserID(ctx context.Context, userID string) error
+	RevokeOtherSessions(ctx context.Context, userID, keepSessionID string) error
+	FindByID(ctx context.Context, id string) (*model.UserSession, error)
+	FindRecentByUserID(ctx context.Context, userID, excludeID string, since time.Time, limit int) ([]model.UserSession, error)
+	Rotate(ctx context.Context, usID string) (bool, error)
+	RevokeFamily(ctx context.Context, familyID string) (bool, error)
+	FindActiveByUserID(ctx context.Context, userID string) ([]model.UserSession, error)
+	Search(ctx context.Context, userID, ipAddress, status string, limit, offset int) ([]model.UserSession, int, error)
 }
 
 type userSessionRepositoryImpl struct {
@@ -29,11 +38,23 @@ func (r *userSessionRepositoryImpl) Create(ctx context.Context, data *model.User
 	const query = `
 									INSERT
 									INTO user_sessions
-										(id, user_id, refresh_token_hash, device_id, ip_address, user_agent, expires_at)
-									VALUES(?, ?, ?, ?, ?, ?, ?)
+										(id, user_id, family_id, parent_id, refresh_token_hash, device_id, remember_me, ip_address, user_agent, expires_at, last_used_at, created_at)
+									VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 								`
+	// session dari login baru menjadi awal family, CreatedAt diisi dari session sebelumnya
+	// saat rotasi refresh token agar waktu login awal tidak hilang
+	if data.FamilyID == "" {
+		data.FamilyID = data.ID
+	}
+	now := time.Now().Truncate(time.Second)
+	if data.CreatedAt.IsZero() {
+		data.CreatedAt = now
+	}
+	data.LastUsedAt = now
+
 	if _, err := r.db.ExecContext(ctx, query,
-		data.ID, data.UserID, data.RefreshTokenHash, data.DeviceID, data.IPAddress, data.UserAgent, data.ExpiresAt,
+		data.ID, data.UserID, data.FamilyID, data.ParentID, data.RefreshTokenHash, data.DeviceID, data.RememberMe, data.IPAddress, data.UserAgent, data.ExpiresAt,
+		data.LastUsedAt, data.CreatedAt,
 	); err != nil {
 		return apperror.New(apperror.CodeDBError, "query user sessions gagal", err)
 	}
@@ -42,11 +63,16 @@ func (r *userSessionRepositoryImpl) Create(ctx context.Context, data *model.User
 }
 
 func (r *userSessionRepositoryImpl) FindRefreshToken(ctx context.Context, hashed string) (*model.UserSession, error) {
-	const query = `SELECT id, user_id, refresh_token_hash, revoked, expires_at 
+	const query = `SELECT id, user_id, COALESCE(family_id, id), refresh_token_hash, COALESCE(device_id, ''), remember_me, revoked, rotated_at,
+									expires_at, COALESCE(last_used_at, created_at), created_at 
 									FROM user_sessions WHERE refresh_token_hash = ?`
-	var us model.UserSession
+	var (
+		us        model.UserSession
+		rotatedAt sql.NullTime
+	)
 	if err := r.db.QueryRowContext(ctx, query, hashed).Scan(
-		&us.ID, &us.UserID, &us.RefreshTokenHash, &us.Revoked, &us.ExpiresAt,
+		&us.ID, &us.UserID, &us.FamilyID, &us.RefreshTokenHash, &us.DeviceID, &us.RememberMe, &us.Revoked, &rotatedAt,
+		&us.ExpiresAt, &us.LastUsedAt, &us.CreatedAt,
 	); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, apperror.New("[REFRESH_TOKEN_NOT_FOUND]", "refresh token tidak ditemukan", err, http.StatusUnauthorized)
@@ -54,6 +80,9 @@ func (r *userSessionRepositoryImpl) FindRefreshToken(ctx context.Context, hashed
 
 		return nil, apperror.New(apperror.CodeDBError, "query refresh token gagal", err)
 	}
+	if rotatedAt.Valid {
+		us.RotatedAt = &rotatedAt.Time
+	}
 
 	return &us, nil
 }
@@ -133,3 +162,212 @@ func (r *userSessionRepositoryImpl) RevokeAllSessionByUserID(ctx context.Context
 
 	return nil
 }
+
+func (r *userSessionRepositoryImpl) RevokeOtherSessions(ctx context.Context, userID, keepSessionID string) error {
+	const query = `UPDATE user_sessions SET revoked = true WHERE user_id = ? AND id <> ?`
+	if _, err := r.db.ExecContext(ctx, query, userID, keepSessionID); err != nil {
+		return apperror.New(apperror.CodeDBError, "revoke sesi lain gagal", err)
+	}
+
+	return nil
+}
+
+func (r *userSessionRepositoryImpl) FindByID(ctx context.Context, id string) (*model.UserSession, error) {
+	const query = `SELECT id, user_id, COALESCE(family_id, id), COALESCE(ip_address, ''), COALESCE(user_agent, ''), revoked, expires_at, created_at
+									FROM user_sessions WHERE id = ?`
+	var us model.UserSession
+	if err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&us.ID, &us.UserID, &us.FamilyID, &us.IPAddress, &us.UserAgent, &us.Revoked, &us.ExpiresAt, &us.CreatedAt,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperror.New("[SESSION_NOT_FOUND]", "session tidak ditemukan", err, http.StatusNotFound)
+		}
+
+		return nil, apperror.New(apperror.CodeDBError, "query user sessions gagal", err)
+	}
+
+	return &us, nil
+}
+
+// FindRecentByUserID session user sejak waktu tertentu (termasuk yang sudah revoked), terbaru lebih dulu
+func (r *userSessionRepositoryImpl) FindRecentByUserID(ctx context.Context, userID, excludeID string, since time.Time, limit int) ([]model.UserSession, error) {
+	const query = `SELECT id, COALESCE(device_id, ''), COALESCE(ip_address, ''), COALESCE(user_agent, ''), created_at
+									FROM user_sessions WHERE user_id = ? AND id <> ? AND created_at >= ?
+									ORDER BY created_at DESC LIMIT ?`
+	rows, err := r.db.QueryContext(ctx, query, userID, excludeID, since, limit)
+	if err != nil {
+		return nil, apperror.New(apperror.CodeDBError, "query user sessions gagal", err)
+	}
+	defer rows.Close()
+
+	var sessions []model.UserSession
+	for rows.Next() {
+		us := model.UserSession{UserID: userID}
+		if err := rows.Scan(&us.ID, &us.DeviceID, &us.IPAddress, &us.UserAgent, &us.CreatedAt); err != nil {
+			return nil, apperror.New(apperror.CodeDBError, "scan user sessions gagal", err)
+		}
+		sessions = append(sessions, us)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, apperror.New(apperror.CodeDBError, "gagal setelah iterasi", err)
+	}
+
+	return sessions, nil
+}
+
+// Rotate revoke session karena refresh token-nya diganti, false jika session sudah revoked lebih dulu
+// (misalnya oleh refresh lain yang berjalan bersamaan)
+func (r *userSessionRepositoryImpl) Rotate(ctx context.Context, usID string) (bool, error) {
+	const query = `UPDATE user_sessions SET revoked = true, rotated_at = ? WHERE id = ? AND revoked = false`
+	result, err := r.db.ExecContext(ctx, query, time.Now(), usID)
+	if err != nil {
+		return false, apperror.New(apperror.CodeDBError, "rotasi refresh token gagal", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, apperror.New(apperror.CodeDBError, "rotasi refresh token gagal", err)
+	}
+
+	return affected > 0, nil
+}
+
+// RevokeFamily revoke semua session hasil rotasi dari satu login, session lama tanpa family_id adalah family-nya sendiri.
+// Mengembalikan false jika tidak ada session family yang masih aktif
+func (r *userSessionRepositoryImpl) RevokeFamily(ctx context.Context, familyID string) (bool, error) {
+	const query = `UPDATE user_sessions SET revoked = true WHERE (family_id = ? OR id = ?) AND revoked = false`
+	result, err := r.db.ExecContext(ctx, query, familyID, familyID)
+	if err != nil {
+		return false, apperror.New(apperror.CodeDBError, "revoke family session gagal", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, apperror.New(apperror.CodeDBError, "revoke family session gagal", err)
+	}
+
+	return affected > 0, nil
+}
+
+// FindActiveByUserID session user yang belum revoked dan belum kadaluarsa beserta ID dan nama perangkatnya,
+// terakhir dipakai lebih dulu
+func (r *userSessionRepositoryImpl) FindActiveByUserID(ctx context.Context, userID string) ([]model.UserSession, error) {
+	const query = `SELECT s.id, s.user_id, s.refresh_token_hash, COALESCE(s.device_id, ''), COALESCE(s.ip_address, ''), COALESCE(s.user_agent, ''),
+									s.expires_at, COALESCE(s.last_used_at, s.created_at), s.created_at, d.id, d.name
+									FROM user_sessions s
+									LEFT JOIN user_devices d ON d.user_id = s.user_id AND d.device_id = s.device_id
+									WHERE s.user_id = ? AND s.revoked = false AND s.expires_at > ?
+									ORDER BY COALESCE(s.last_used_at, s.created_at) DESC`
+	rows, err := r.db.QueryContext(ctx, query, userID, time.Now())
+	if err != nil {
+		return nil, apperror.New(apperror.CodeDBError, "query user sessions gagal", err)
+	}
+	defer rows.Close()
+
+	var sessions []model.UserSession
+	for rows.Next() {
+		var (
+			us         model.UserSession
+			deviceID   sql.NullString
+			deviceName *string
+		)
+		if err := rows.Scan(
+			&us.ID, &us.UserID, &us.RefreshTokenHash, &us.DeviceID, &us.IPAddress, &us.UserAgent,
+			&us.ExpiresAt, &us.LastUsedAt, &us.CreatedAt, &deviceID, &deviceName,
+		); err != nil {
+			return nil, apperror.New(apperror.CodeDBError, "scan user sessions gagal", err)
+		}
+		if deviceID.Valid {
+			us.Device = &model.UserDeviceModel{ID: deviceID.String, Name: deviceName}
+		}
+		sessions = append(sessions, us)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, apperror.New(apperror.CodeDBError, "gagal setelah iterasi", err)
+	}
+
+	return sessions, nil
+}
+
+// Search session semua user untuk admin dengan filter opsional user, IP dan status, terakhir dipakai lebih dulu
+func (r *userSessionRepositoryImpl) Search(ctx context.Context, userID, ipAddress, status string, limit, offset int) ([]model.UserSession, int, error) {
+	var (
+		where []string
+		args  []any
+	)
+	if userID != "" {
+		where = append(where, "s.user_id = ?")
+		args = append(args, userID)
+	}
+	if ipAddress != "" {
+		where = append(where, "s.ip_address = ?")
+		args = append(args, ipAddress)
+	}
+	switch status {
+	case model.SessionStatusActive:
+		where = append(where, "s.revoked = false AND s.expires_at > ?")
+		args = append(args, time.Now())
+	case model.SessionStatusExpired:
+		where = append(where, "s.revoked = false AND s.expires_at <= ?")
+		args = append(args, time.Now())
+	case model.SessionStatusRevoked:
+		where = append(where, "s.revoked = true")
+	}
+
+	filter := ""
+	if len(where) > 0 {
+		filter = "WHERE " + strings.Join(where, " AND ")
+	}
+
+	queryTotal := `SELECT COUNT(*) FROM user_sessions s ` + filter
+	querySessions := `SELECT s.id, s.user_id, COALESCE(s.device_id, ''), COALESCE(s.ip_address, ''), COALESCE(s.user_agent, ''),
+									s.revoked, s.expires_at, COALESCE(s.last_used_at, s.created_at), s.created_at, d.id, d.name, u.username, u.email
+									FROM user_sessions s
+									INNER JOIN users u ON u.id = s.user_id
+									LEFT JOIN user_devices d ON d.user_id = s.user_id AND d.device_id = s.device_id
+									` + filter + `
+									ORDER BY COALESCE(s.last_used_at, s.created_at) DESC
+									LIMIT ? OFFSET ?`
+
+	// hitung total session sesuai filter
+	var total int
+	if err := r.db.QueryRowContext(ctx, queryTotal, args...).Scan(&total); err != nil {
+		return nil, 0, apperror.New(apperror.CodeDBError, "gagal menghitung total user sessions", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, querySessions, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, apperror.New(apperror.CodeDBError, "query user sessions gagal", err)
+	}
+	defer rows.Close()
+
+	var sessions []model.UserSession
+	for rows.Next() {
+		var (
+			us         model.UserSession
+			user       model.UserModel
+			deviceID   sql.NullString
+			deviceName *string
+		)
+		if err := rows.Scan(
+			&us.ID, &us.UserID, &us.DeviceID, &us.IPAddress, &us.UserAgent,
+			&us.Revoked, &us.ExpiresAt, &us.LastUsedAt, &us.CreatedAt, &deviceID, &deviceName, &user.Username, &user.Email,
+		); err != nil {
+			return nil, 0, apperror.New(apperror.CodeDBError, "scan user sessions gagal", err)
+		}
+		if deviceID.Valid {
+			us.Device = &model.UserDeviceModel{ID: deviceID.String, Name: deviceName}
+		}
+		user.ID = us.UserID
+		us.User = &user
+		sessions = append(sessions, us)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, apperror.New(apperror.CodeDBError, "gagal setelah iterasi", err)
+	}
+
+	return sessions, total, nil
+}
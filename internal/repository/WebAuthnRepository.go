@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/gogaruda/apperror"
+	"github.com/gogaruda/dbtx"
+	"github.com/irawankilmer/auth-service/internal/model"
+	"net/http"
+	"time"
+)
+
+type WebAuthnRepository interface {
+	CreateCredential(ctx context.Context, credential *model.WebAuthnCredentialModel) error
+	FindCredentialsByUserID(ctx context.Context, userID string) ([]model.WebAuthnCredentialModel, error)
+	UpdateCredentialUsage(ctx context.Context, id string, signCount uint32, cloneWarning bool, credentialData []byte) error
+	RenameCredential(ctx context.Context, userID, id, name string) error
+	DeleteCredential(ctx context.Context, userID, id string) error
+	CreateSession(ctx context.Context, session *model.WebAuthnSessionModel) error
+	TakeSession(ctx context.Context, id, ceremony string) (*model.WebAuthnSessionModel, error)
+}
+
+type webAuthnRepository struct {
+	db *sql.DB
+}
+
+func NewWebAuthnRepository(db *sql.DB) WebAuthnRepository {
+	return &webAuthnRepository{db: db}
+}
+
+func (r *webAuthnRepository) CreateCredential(ctx context.Context, credential *model.WebAuthnCredentialModel) error {
+	const query = `
+		INSERT INTO webauthn_credentials(id, user_id, credential_id, name, sign_count, clone_warning, credential_data)
+		VALUES(?, ?, ?, ?, ?, ?, ?)`
+	if _, err := r.db.ExecContext(ctx, query,
+		credential.ID, credential.UserID, credential.CredentialID, credential.Name,
+		credential.SignCount, credential.CloneWarning, credential.CredentialData,
+	); err != nil {
+		return apperror.New(apperror.CodeDBError, "insert webauthn_credentials gagal", err)
+	}
+
+	return nil
+}
+
+func (r *webAuthnRepository) FindCredentialsByUserID(ctx context.Context, userID string) ([]model.WebAuthnCredentialModel, error) {
+	const query = `
+		SELECT id, user_id, credential_id, name, sign_count, clone_warning, credential_data, last_used_at, created_at
+		FROM webauthn_credentials WHERE user_id = ? ORDER BY created_at`
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, apperror.New(apperror.CodeDBError, "query webauthn_credentials gagal", err)
+	}
+	defer rows.Close()
+
+	var credentials []model.WebAuthnCredentialModel
+	for rows.Next() {
+		var credential model.WebAuthnCredentialModel
+		var lastUsedAt sql.NullTime
+		if err := rows.Scan(
+			&credential.ID, &credential.UserID, &credential.CredentialID, &credential.Name, &credential.SignCount,
+			&credential.CloneWarning, &credential.CredentialData, &lastUsedAt, &credential.CreatedAt,
+		); err != nil {
+			return nil, apperror.New(apperror.CodeDBError, "webauthn_credentials gagal scan", err)
+		}
+		if lastUsedAt.Valid {
+			credential.LastUsedAt = &lastUsedAt.Time
+		}
+
+		credentials = append(credentials, credential)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, apperror.New(apperror.CodeDBError, "gagal setelah iterasi webauthn_credentials", err)
+	}
+
+	return credentials, nil
+}
+
+func (r *webAuthnRepository) UpdateCredentialUsage(ctx context.Context, id string, signCount uint32, cloneWarning bool, credentialData []byte) error {
+	const query = `
+		UPDATE webauthn_credentials SET sign_count = ?, clone_warning = ?, credential_data = ?, last_used_at = NOW()
+		WHERE id = ?`
+	if _, err := r.db.ExecContext(ctx, query, signCount, cloneWarning, credentialData, id); err != nil {
+		return apperror.New(apperror.CodeDBError, "update webauthn_credentials gagal", err)
+	}
+
+	return nil
+}
+
+func (r *webAuthnRepository) RenameCredential(ctx context.Context, userID, id, name string) error {
+	const query = `UPDATE webauthn_credentials SET name = ? WHERE id = ? AND user_id = ?`
+	result, err := r.db.ExecContext(ctx, query, name, id, userID)
+	if err != nil {
+		return apperror.New(apperror.CodeDBError, "update nama passkey gagal", err)
+	}
+
+	return passkeyAffected(result)
+}
+
+func (r *webAuthnRepository) DeleteCredential(ctx context.Context, userID, id string) error {
+	const query = `DELETE FROM webauthn_credentials WHERE id = ? AND user_id = ?`
+	result, err := r.db.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return apperror.New(apperror.CodeDBError, "hapus passkey gagal", err)
+	}
+
+	return passkeyAffected(result)
+}
+
+func (r *webAuthnRepository) CreateSession(ctx context.Context, session *model.WebAuthnSessionModel) error {
+	const query = `
+		INSERT INTO webauthn_sessions(id, user_id, ceremony, session_data, expires_at)
+		VALUES(?, ?, ?, ?, ?)`
+	if _, err := r.db.ExecContext(ctx, query,
+		session.ID, session.UserID, session.Ceremony, session.SessionData, session.ExpiresAt,
+	); err != nil {
+		return apperror.New(apperror.CodeDBError, "insert webauthn_sessions gagal", err)
+	}
+
+	return nil
+}
+
+// TakeSession mengambil lalu menghapus session ceremony, sehingga satu challenge hanya bisa dipakai sekali
+func (r *webAuthnRepository) TakeSession(ctx context.Context, id, ceremony string) (*model.WebAuthnSessionModel, error) {
+	const (
+		querySelect = `
+			SELECT id, user_id, ceremony, session_data, expires_at
+			FROM webauthn_sessions WHERE id = ? AND ceremony = ? FOR UPDATE`
+		queryDelete       = `DELETE FROM webauthn_sessions WHERE id = ?`
+		queryDeleteExpire = `DELETE FROM webauthn_sessions WHERE expires_at < ?`
+	)
+
+	invalid := apperror.New("[WEBAUTHN_SESSION_INVALID]", "session passkey tidak valid atau sudah kedaluwarsa", sql.ErrNoRows, http.StatusBadRequest)
+	var session model.WebAuthnSessionModel
+	err := dbtx.WithTxContext(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		// ambil session
+		var userID sql.NullString
+		if err := tx.QueryRowContext(ctx, querySelect, id, ceremony).Scan(
+			&session.ID, &userID, &session.Ceremony, &session.SessionData, &session.ExpiresAt,
+		); err != nil {
+			if err == sql.ErrNoRows {
+				return invalid
+			}
+
+			return apperror.New(apperror.CodeDBError, "query webauthn_sessions gagal", err)
+		}
+		if userID.Valid {
+			session.UserID = &userID.String
+		}
+
+		// hapus session ini sekaligus session lain yang sudah kedaluwarsa
+		if _, err := tx.ExecContext(ctx, queryDelete, id); err != nil {
+			return apperror.New(apperror.CodeDBError, "hapus webauthn_sessions gagal", err)
+		}
+		if _, err := tx.ExecContext(ctx, queryDeleteExpire, time.Now()); err != nil {
+			return apperror.New(apperror.CodeDBError, "hapus webauthn_sessions kedaluwarsa gagal", err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if session.ExpiresAt.Before(time.Now()) {
+		return nil, invalid
+	}
+
+	return &session, nil
+}
+
+func passkeyAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return apperror.New(apperror.CodeDBError, "cek passkey gagal", err)
+	}
+	if affected == 0 {
+		return apperror.New("[PASSKEY_NOT_FOUND]", "passkey tidak ditemukan", sql.ErrNoRows, http.StatusNotFound)
+	}
+
+	return nil
+}
@@ -9,13 +9,33 @@ import (
 	"github.com/irawankilmer/auth-service/internal/dto/response"
 	"github.com/irawankilmer/auth-service/internal/model"
 	"github.com/irawankilmer/auth-service/internal/repository"
+	"github.com/irawankilmer/auth-service/pkg/mailer"
+	"github.com/irawankilmer/auth-service/pkg/password"
+	"github.com/irawankilmer/auth-service/pkg/tokencache"
 	"github.com/irawankilmer/auth-service/pkg/utils"
+	"log"
 	"net/http"
+	"strings"
 	"time"
 )
 
 type AuthService interface {
-	Login(ctx context.Context, req request.LoginRequest, userAgent, ipAddress string) (*response.LoginResponse, error)
+	Login(ctx context.Context, req request.LoginRequest, deviceID, userAgent, ipAddress string) (*response.LoginResponse, *response.MFAChallengeResponse, error)
+	LoginMFA(ctx context.Context, req request.LoginMFARequest, deviceID, userAgent, ipAddress string) (*response.LoginResponse, error)
+	LoginRecovery(ctx context.Context, req request.LoginRecoveryRequest, deviceID, userAgent, ipAddress string) (*response.LoginResponse, error)
+	LoginIdentity(ctx context.Context, user *model.UserModel, provider, deviceID, userAgent, ipAddress string) (*response.LoginResponse, *response.MFAChallengeResponse, error)
+	MagicLink(ctx context.Context, req request.MagicLinkRequest) error
+	LoginMagicLink(ctx context.Context, req request.MagicLinkLoginRequest, deviceID, userAgent, ipAddress string) (*response.LoginResponse, *response.MFAChallengeResponse, error)
+	ForgotPassword(ctx context.Context, req request.ForgotPasswordRequest) error
+	ResetPassword(ctx context.Context, req request.ResetPasswordRequest, userAgent, ipAddress string) error
+	ChangePassword(ctx context.Context, userID string, req request.ChangePasswordRequest, refreshToken, userAgent, ipAddress string) (*response.LoginResponse, error)
+	EmailChange(ctx context.Context, userID string, req request.EmailChangeRequest, ipAddress string) error
+	EmailChangeConfirm(ctx context.Context, req request.VerifyRequest) error
+	EmailChangeRevert(ctx context.Context, req request.VerifyRequest) error
+	LoginPasskeyBegin(ctx context.Context) (*response.PasskeyBeginResponse, error)
+	LoginPasskey(ctx context.Context, req request.PasskeyLoginFinishRequest, deviceID, userAgent, ipAddress string) (*response.LoginResponse, error)
+	LoginMFAPasskeyBegin(ctx context.Context, req request.LoginPasskeyBeginRequest) (*response.PasskeyBeginResponse, error)
+	LoginMFAPasskey(ctx context.Context, req request.LoginPasskeyFinishRequest, deviceID, userAgent, ipAddress string) (*response.LoginResponse, error)
 	Logout(ctx context.Context, refreshToken string) error
 	LogoutAllDevices(ctx context.Context, userID string) error
 	Register(ctx context.Context, req request.RegisterRequest) (string, error)
@@ -23,45 +43,701 @@ type AuthService interface {
 }
 
 type authService struct {
-	authRepo     repository.AuthRepository
-	userRepo     repository.UserRepository
-	roleRepo     repository.RoleRepository
-	utility      utils.Utility
-	cfg          *configs.AppConfig
-	usernameRepo repository.UsernameHistoryRepository
-	emailRepo    repository.EmailHistoryRepository
-	evService    EmailVerificationService
-	usRepo       repository.UserSessionRepository
+	authRepo      repository.AuthRepository
+	userRepo      repository.UserRepository
+	roleRepo      repository.RoleRepository
+	utility       utils.Utility
+	cfg           *configs.AppConfig
+	usernameRepo  repository.UsernameHistoryRepository
+	emailRepo     repository.EmailHistoryRepository
+	evService     EmailVerificationService
+	usRepo        repository.UserSessionRepository
+	laService     LoginAttemptService
+	mfaService    MFAService
+	waService     WebAuthnService
+	profService   ProfileService
+	pwPolicy      password.Policy
+	notifyService LoginNotificationService
+	deviceService DeviceService
+	tokenCache    tokencache.Cache
+	mailQueue     *mailer.Queue
 }
 
 func NewAuthService(ar repository.AuthRepository, ut utils.Utility, cfg *configs.AppConfig,
 	ur repository.UserRepository, rp repository.RoleRepository,
 	username repository.UsernameHistoryRepository, email repository.EmailHistoryRepository,
-	ev EmailVerificationService, usR repository.UserSessionRepository,
+	ev EmailVerificationService, usR repository.UserSessionRepository, la LoginAttemptService, mfa MFAService,
+	wa WebAuthnService, ps ProfileService, pp password.Policy, ln LoginNotificationService, ds DeviceService,
+	tc tokencache.Cache, mq *mailer.Queue,
 ) AuthService {
 	return &authService{
 		authRepo: ar, utility: ut, cfg: cfg, userRepo: ur, roleRepo: rp,
-		usernameRepo: username, emailRepo: email, evService: ev, usRepo: usR,
+		usernameRepo: username, emailRepo: email, evService: ev, usRepo: usR, laService: la, mfaService: mfa,
+		waService: wa, profService: ps, pwPolicy: pp, notifyService: ln, deviceService: ds,
+		tokenCache: tc, mailQueue: mq,
 	}
 }
 
-func (s *authService) Login(ctx context.Context, req request.LoginRequest, userAgent, ipAddress string) (*response.LoginResponse, error) {
+func (s *authService) Login(ctx context.Context, req request.LoginRequest, deviceID, userAgent, ipAddress string) (*response.LoginResponse, *response.MFAChallengeResponse, error) {
 	// Cek identifikasi
 	user, err := s.authRepo.IdentifierCheck(ctx, req.Identifier)
 	if err != nil {
+		// identifier tidak ditemukan tetap dihitung per identifier+IP
+		if apperror.Is(err, "[IDENTIFIER_NOT_FOUND]") {
+			if err := s.laService.CheckLock(ctx, nil, req.Identifier, ipAddress); err != nil {
+				return nil, nil, err
+			}
+
+			// hash tetap dijalankan agar waktu respon sama dengan password salah
+			if err := s.utility.HashDummyCompare(ctx, req.Password); err != nil {
+				return nil, nil, err
+			}
+			if err := s.laService.RecordFailure(ctx, nil, req.Identifier, ipAddress); err != nil {
+				return nil, nil, err
+			}
+		}
+		return nil, nil, err
+	}
+
+	// cek kunci akun
+	if err := s.laService.CheckLock(ctx, user, req.Identifier, ipAddress); err != nil {
+		return nil, nil, err
+	}
+
+	// cek password
+	match, err := comparePassword(ctx, s.utility, user, req.Password)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !match {
+		if err := s.laService.RecordFailure(ctx, user, req.Identifier, ipAddress); err != nil {
+			return nil, nil, err
+		}
+		return nil, nil, apperror.New("[PASSWORD_INVALID]", "password salah", errors.New("Password salah"), http.StatusUnauthorized)
+	}
+
+	// cek verifikasi email, setelah password agar status akun tidak terlihat tanpa password yang benar
+	if !user.EmailVerified {
+		return nil, nil, apperror.New("[EMAIL_NOT_VERIFY]", "email belum di verifikasi", nil, http.StatusUnauthorized)
+	}
+
+	// reset percobaan gagal
+	if err := s.laService.Reset(ctx, user, req.Identifier, ipAddress); err != nil {
+		return nil, nil, err
+	}
+
+	// perbarui hash dengan algoritma dan parameter saat ini
+	s.rehashPassword(ctx, user, req.Password)
+
+	return s.completeLogin(ctx, user, deviceID, userAgent, ipAddress, req.RememberMe)
+}
+
+// rehashPassword membuat ulang hash lama (misal bcrypt) setelah password terbukti benar,
+// sehingga user lama pindah ke argon2id tanpa reset password. Gagal hanya dicatat agar login tetap jalan
+func (s *authService) rehashPassword(ctx context.Context, user *model.UserModel, password string) {
+	if !s.utility.HashNeedsRehash(*user.Password) {
+		return
+	}
+
+	hash, err := s.utility.HashGenerate(ctx, password)
+	if err != nil {
+		log.Printf("[WARN] rehash password user %s gagal: %v", user.ID, err)
+		return
+	}
+	if err := s.authRepo.UpdatePasswordHash(ctx, user.ID, *user.Password, hash); err != nil {
+		log.Printf("[WARN] simpan rehash password user %s gagal: %v", user.ID, err)
+		return
+	}
+
+	user.Password = &hash
+}
+
+func (s *authService) LoginMFA(ctx context.Context, req request.LoginMFARequest, deviceID, userAgent, ipAddress string) (*response.LoginResponse, error) {
+	// cek challenge token
+	userID, rememberMe, err := s.utility.MFAChallengeParse(req.MFAToken)
+	if err != nil {
+		return nil, err
+	}
+
+	// cek user dan kunci akun
+	user, err := s.authRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	mfaIdentifier := "mfa:" + user.ID
+	if err := s.laService.CheckLock(ctx, user, mfaIdentifier, ipAddress); err != nil {
+		return nil, err
+	}
+
+	// cek kode MFA, kode salah dihitung sebagai login gagal
+	if err := s.mfaService.VerifyCode(ctx, user.ID, req.Code); err != nil {
+		if apperror.Is(err, "[MFA_CODE_INVALID]") {
+			if err := s.laService.RecordFailure(ctx, user, mfaIdentifier, ipAddress); err != nil {
+				return nil, err
+			}
+		}
+		return nil, err
+	}
+
+	// reset percobaan gagal
+	if err := s.laService.Reset(ctx, user, mfaIdentifier, ipAddress); err != nil {
+		return nil, err
+	}
+
+	return s.issueTokens(ctx, user, deviceID, userAgent, ipAddress, req.TrustDevice, rememberMe)
+}
+
+func (s *authService) LoginRecovery(ctx context.Context, req request.LoginRecoveryRequest, deviceID, userAgent, ipAddress string) (*response.LoginResponse, error) {
+	// cek challenge token
+	userID, rememberMe, err := s.utility.MFAChallengeParse(req.MFAToken)
+	if err != nil {
+		return nil, err
+	}
+
+	// cek user dan kunci akun
+	user, err := s.authRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	mfaIdentifier := "mfa:" + user.ID
+	if err := s.laService.CheckLock(ctx, user, mfaIdentifier, ipAddress); err != nil {
+		return nil, err
+	}
+
+	// pakai recovery code, kode salah dihitung sebagai login gagal
+	if err := s.mfaService.UseRecoveryCode(ctx, user, req.RecoveryCode, ipAddress, userAgent); err != nil {
+		if apperror.Is(err, "[MFA_CODE_INVALID]") {
+			if err := s.laService.RecordFailure(ctx, user, mfaIdentifier, ipAddress); err != nil {
+				return nil, err
+			}
+		}
+		return nil, err
+	}
+
+	// reset percobaan gagal
+	if err := s.laService.Reset(ctx, user, mfaIdentifier, ipAddress); err != nil {
+		return nil, err
+	}
+
+	return s.issueTokens(ctx, user, deviceID, userAgent, ipAddress, false, rememberMe)
+}
+
+func (s *authService) LoginPasskeyBegin(ctx context.Context) (*response.PasskeyBeginResponse, error) {
+	return s.waService.BeginLogin(ctx)
+}
+
+func (s *authService) LoginPasskey(ctx context.Context, req request.PasskeyLoginFinishRequest, deviceID, userAgent, ipAddress string) (*response.LoginResponse, error) {
+	// verifikasi passkey, passkey dengan user verification sudah menjadi dua faktor.
+	// kunci akun dicek sebelum tanda tangan passkey diverifikasi
+	user, credentialKey, err := s.waService.FinishLogin(ctx, req, func(user *model.UserModel, credentialKey string) error {
+		return s.laService.CheckLock(ctx, user, "passkey:"+credentialKey, ipAddress)
+	})
+	if err != nil {
+		// passkey salah dihitung per credential+IP, user dari user handle belum terbukti pemiliknya
+		if credentialKey != "" {
+			if err := s.laService.RecordFailure(ctx, nil, "passkey:"+credentialKey, ipAddress); err != nil {
+				return nil, err
+			}
+		}
+		return nil, err
+	}
+
+	// reset percobaan gagal
+	if err := s.laService.Reset(ctx, user, "passkey:"+credentialKey, ipAddress); err != nil {
 		return nil, err
 	}
 
 	// cek verifikasi email
 	if !user.EmailVerified {
-		return nil, apperror.New("[EMAIL_NOT_VERIFY]", "email belum di verifikasi", err, http.StatusUnauthorized)
+		return nil, apperror.New("[EMAIL_NOT_VERIFY]", "email belum di verifikasi", nil, http.StatusUnauthorized)
+	}
+
+	return s.issueTokens(ctx, user, deviceID, userAgent, ipAddress, false, false)
+}
+
+func (s *authService) LoginMFAPasskeyBegin(ctx context.Context, req request.LoginPasskeyBeginRequest) (*response.PasskeyBeginResponse, error) {
+	// cek challenge token
+	userID, _, err := s.utility.MFAChallengeParse(req.MFAToken)
+	if err != nil {
+		return nil, err
+	}
+
+	// cek user
+	user, err := s.authRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.waService.BeginVerify(ctx, user)
+}
+
+func (s *authService) LoginMFAPasskey(ctx context.Context, req request.LoginPasskeyFinishRequest, deviceID, userAgent, ipAddress string) (*response.LoginResponse, error) {
+	// cek challenge token
+	userID, rememberMe, err := s.utility.MFAChallengeParse(req.MFAToken)
+	if err != nil {
+		return nil, err
+	}
+
+	// cek user dan kunci akun
+	user, err := s.authRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	mfaIdentifier := "mfa:" + user.ID
+	if err := s.laService.CheckLock(ctx, user, mfaIdentifier, ipAddress); err != nil {
+		return nil, err
+	}
+
+	// verifikasi passkey, kegagalan dihitung sebagai login gagal
+	if err := s.waService.FinishVerify(ctx, user, req.SessionID, req.Credential); err != nil {
+		if apperror.Is(err, "[WEBAUTHN_INVALID]") {
+			if err := s.laService.RecordFailure(ctx, user, mfaIdentifier, ipAddress); err != nil {
+				return nil, err
+			}
+		}
+		return nil, err
+	}
+
+	// reset percobaan gagal
+	if err := s.laService.Reset(ctx, user, mfaIdentifier, ipAddress); err != nil {
+		return nil, err
+	}
+
+	return s.issueTokens(ctx, user, deviceID, userAgent, ipAddress, req.TrustDevice, rememberMe)
+}
+
+// LoginIdentity melanjutkan login user yang sudah diverifikasi provider eksternal
+func (s *authService) LoginIdentity(ctx context.Context, user *model.UserModel, provider, deviceID, userAgent, ipAddress string) (*response.LoginResponse, *response.MFAChallengeResponse, error) {
+	// cek kunci akun
+	if err := s.laService.CheckLock(ctx, user, provider+":"+user.ID, ipAddress); err != nil {
+		return nil, nil, err
+	}
+
+	return s.completeLogin(ctx, user, deviceID, userAgent, ipAddress, false)
+}
+
+// MagicLink mengirim link login ke email. Hasilnya selalu sama baik email terdaftar atau tidak,
+// pengiriman berjalan di background agar waktu respon juga tidak membedakan
+func (s *authService) MagicLink(ctx context.Context, req request.MagicLinkRequest) error {
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	ctx = context.WithoutCancel(ctx)
+
+	// dikirim lewat antrean agar respon tidak membedakan email terdaftar
+	s.mailQueue.Enqueue("magic link", func() {
+		// cek user
+		user, err := s.authRepo.FindByEmail(ctx, email)
+		if err != nil {
+			if !apperror.Is(err, apperror.CodeUserNotFound) {
+				log.Printf("[WARN] cek email magic link gagal: %v", err)
+			}
+			return
+		}
+
+		// cek verifikasi email
+		if !user.EmailVerified && !s.cfg.MagicLink.AllowUnverified {
+			return
+		}
+
+		// kirim link, batas permintaan tercapai tidak dilaporkan ke client
+		if err := s.evService.SendMagicLink(ctx, user, s.cfg.MagicLink); err != nil && !apperror.Is(err, "[EMAIL_TOKEN_LIMITED]") {
+			log.Printf("[WARN] magic link gagal dikirim ke user %s: %v", user.ID, err)
+		}
+	})
+
+	return nil
+}
+
+// LoginMagicLink menukar token magic link dengan token login, sama seperti Login setelah password valid
+func (s *authService) LoginMagicLink(ctx context.Context, req request.MagicLinkLoginRequest, deviceID, userAgent, ipAddress string) (*response.LoginResponse, *response.MFAChallengeResponse, error) {
+	// cek dan pakai token
+	ev, err := s.evService.ConsumeToken(ctx, req.Token, "magic_link")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// cek user dan kunci akun
+	user, err := s.authRepo.FindByID(ctx, ev.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := s.laService.CheckLock(ctx, user, "magic_link:"+user.ID, ipAddress); err != nil {
+		return nil, nil, err
+	}
+
+	// cek verifikasi email, link yang sampai ke inbox sekaligus membuktikan kepemilikan email
+	if !user.EmailVerified {
+		if !s.cfg.MagicLink.AllowUnverified {
+			return nil, nil, apperror.New("[EMAIL_NOT_VERIFY]", "email belum di verifikasi", nil, http.StatusUnauthorized)
+		}
+		if err := s.userRepo.UpdateEmailVerified(ctx, &response.UserDetailResponse{ID: user.ID}); err != nil {
+			return nil, nil, err
+		}
+		user.EmailVerified = true
+	}
+
+	return s.completeLogin(ctx, user, deviceID, userAgent, ipAddress, false)
+}
+
+// ForgotPassword mengirim token reset password. Seperti MagicLink, hasilnya selalu sama baik email terdaftar atau tidak
+func (s *authService) ForgotPassword(ctx context.Context, req request.ForgotPasswordRequest) error {
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	ctx = context.WithoutCancel(ctx)
+
+	// dikirim lewat antrean agar respon tidak membedakan email terdaftar
+	s.mailQueue.Enqueue("reset password", func() {
+		// cek user
+		user, err := s.authRepo.FindByEmail(ctx, email)
+		if err != nil {
+			if !apperror.Is(err, apperror.CodeUserNotFound) {
+				log.Printf("[WARN] cek email reset password gagal: %v", err)
+			}
+			return
+		}
+
+		// kirim token, batas permintaan tercapai tidak dilaporkan ke client
+		if err := s.evService.SendPasswordReset(ctx, user, s.cfg.Reset); err != nil && !apperror.Is(err, "[EMAIL_TOKEN_LIMITED]") {
+			log.Printf("[WARN] reset password gagal dikirim ke user %s: %v", user.ID, err)
+		}
+	})
+
+	return nil
+}
+
+// ResetPassword mengganti password dengan token dari email, lalu mengeluarkan user dari semua perangkat
+func (s *authService) ResetPassword(ctx context.Context, req request.ResetPasswordRequest, userAgent, ipAddress string) error {
+	// cek token, belum dipakai agar password yang ditolak policy bisa diulang dengan token yang sama
+	ev, err := s.evService.FindByToken(ctx, req.Token)
+	if err != nil {
+		return err
+	}
+	if ev.ActionType != "password_change" {
+		return apperror.New("[TOKEN_NOT_FOUND]", "token tidak ditemukan", nil, http.StatusUnauthorized)
+	}
+
+	// cek user
+	user, err := s.authRepo.FindByID(ctx, ev.UserID)
+	if err != nil {
+		return err
+	}
+
+	// cek password policy
+	if err := checkPasswordPolicy(s.pwPolicy, req.Password, user.Email, user.Username); err != nil {
+		return err
+	}
+
+	// generate password
+	passHash, err := s.utility.HashGenerate(ctx, req.Password)
+	if err != nil {
+		return err
+	}
+
+	// pakai token
+	if _, err := s.evService.ConsumeToken(ctx, req.Token, "password_change"); err != nil {
+		return err
+	}
+
+	// generate token version baru
+	newTokenVersion, err := s.utility.UUIDGenerate()
+	if err != nil {
+		return apperror.New(apperror.CodeInternalError, "generate new token version gagal", err)
+	}
+
+	// update password, token version dan revoke semua session
+	if err := s.authRepo.ResetPassword(ctx, user.ID, passHash, newTokenVersion); err != nil {
+		return err
+	}
+	s.tokenCache.Invalidate(user.ID)
+
+	// link reset lain yang masih ada di email tidak berlaku lagi
+	if err := s.evService.CancelPending(ctx, user.ID, "password_change"); err != nil {
+		return err
+	}
+
+	s.evService.NotifyPasswordChanged(user, ipAddress, userAgent)
+	return nil
+}
+
+// ChangePassword mengganti password user login. Revoke "others" mempertahankan session saat ini dan
+// mengembalikan access token baru, revoke "all" mengakhiri semua session termasuk session saat ini
+func (s *authService) ChangePassword(ctx context.Context, userID string, req request.ChangePasswordRequest, refreshToken, userAgent, ipAddress string) (*response.LoginResponse, error) {
+	// cek user
+	user, err := s.authRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// akun dari provider eksternal belum punya password
+	if user.Password == nil {
+		return nil, apperror.New("[PASSWORD_NOT_SET]", "akun belum memiliki password, gunakan lupa password", nil, http.StatusBadRequest)
+	}
+
+	// cek password saat ini
+	if err := verifyPassword(ctx, s.laService, s.utility, user, req.CurrentPassword, ipAddress); err != nil {
+		return nil, err
+	}
+
+	// cek password policy
+	if err := checkPasswordPolicy(s.pwPolicy, req.Password, user.Email, user.Username); err != nil {
+		return nil, err
+	}
+
+	// cek session saat ini sebelum password diganti
+	var session *model.UserSession
+	if req.Revoke == "others" {
+		session, err = s.usRepo.FindRefreshToken(ctx, s.utility.HashToken(refreshToken))
+		if err != nil || session.UserID != user.ID || session.Revoked || session.ExpiresAt.Before(time.Now()) {
+			return nil, apperror.New("[REFRESH_TOKEN_INVALID]", "session saat ini tidak valid, gunakan revoke all", err, http.StatusUnauthorized)
+		}
+	}
+
+	// generate password
+	passHash, err := s.utility.HashGenerate(ctx, req.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	// generate token version baru
+	newTokenVersion, err := s.utility.UUIDGenerate()
+	if err != nil {
+		return nil, apperror.New(apperror.CodeInternalError, "generate new token version gagal", err)
+	}
+
+	// update password dan token version
+	if err := s.authRepo.UpdatePassword(ctx, user.ID, passHash, newTokenVersion); err != nil {
+		return nil, err
+	}
+	s.tokenCache.Invalidate(user.ID)
+
+	// link reset yang dikirim sebelum password diganti tidak berlaku lagi
+	if err := s.evService.CancelPending(ctx, user.ID, "password_change"); err != nil {
+		return nil, err
+	}
+
+	// revoke session
+	var token *response.LoginResponse
+	if session == nil {
+		if err := s.usRepo.RevokeAllSessionByUserID(ctx, user.ID); err != nil {
+			return nil, err
+		}
+	} else {
+		if err := s.usRepo.RevokeOtherSessions(ctx, user.ID, session.ID); err != nil {
+			return nil, err
+		}
+
+		// access token lama memakai token version lama
+		var roles []string
+		for _, r := range user.Roles {
+			roles = append(roles, r.Name)
+		}
+		accessToken, err := s.utility.JWTGenerate(user.ID, newTokenVersion, user.EmailVerified, roles, s.cfg)
+		if err != nil {
+			return nil, apperror.New(apperror.CodeInternalError, "Generate token gagal", err)
+		}
+		token = &response.LoginResponse{
+			AccessToken:      accessToken,
+			RefreshToken:     refreshToken,
+			ExpiresIn:        int(s.cfg.JWT.AccessTokenTTL.Seconds()),
+			RefreshExpiresIn: int(time.Until(session.ExpiresAt).Seconds()),
+		}
+	}
+
+	s.evService.NotifyPasswordChanged(user, ipAddress, userAgent)
+	return token, nil
+}
+
+// EmailChange meminta ganti email user login, email baru harus dikonfirmasi lewat link yang dikirim ke email tersebut
+func (s *authService) EmailChange(ctx context.Context, userID string, req request.EmailChangeRequest, ipAddress string) error {
+	newEmail := strings.ToLower(strings.TrimSpace(req.Email))
+
+	// cek user
+	user, err := s.authRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	// cek password saat ini, akun tanpa password cukup dengan session login
+	if user.Password != nil {
+		if err := verifyPassword(ctx, s.laService, s.utility, user, req.Password, ipAddress); err != nil {
+			return err
+		}
+	}
+
+	if user.Email == newEmail {
+		return apperror.New("[EMAIL_UNCHANGED]", "email baru sama dengan email saat ini", nil, http.StatusBadRequest)
+	}
+
+	// cek ketersediaan email
+	if err := s.checkEmailAvailable(ctx, user.ID, newEmail); err != nil {
+		return err
+	}
+
+	return s.evService.SendEmailChange(ctx, user, newEmail, s.cfg.EmailChange)
+}
+
+// EmailChangeConfirm mengganti email user setelah link dari email baru dipakai, email lama masuk email_history
+func (s *authService) EmailChangeConfirm(ctx context.Context, req request.VerifyRequest) error {
+	// cek dan pakai token
+	ev, err := s.evService.ConsumeToken(ctx, req.Token, "email_change")
+	if err != nil {
+		return err
+	}
+	if ev.Email == nil {
+		return apperror.New("[TOKEN_NOT_FOUND]", "token tidak ditemukan", nil, http.StatusUnauthorized)
+	}
+
+	// cek user
+	user, err := s.authRepo.FindByID(ctx, ev.UserID)
+	if err != nil {
+		return err
+	}
+
+	// email bisa saja sudah dipakai akun lain selama menunggu konfirmasi
+	if err := s.checkEmailAvailable(ctx, user.ID, *ev.Email); err != nil {
+		return err
+	}
+
+	// update email
+	return s.userRepo.EmailUpdate(ctx, &response.UserDetailResponse{ID: user.ID, Email: user.Email}, *ev.Email)
+}
+
+// EmailChangeRevert membatalkan permintaan ganti email yang belum dikonfirmasi, atau mengembalikan email lama jika
+// sudah terlanjur diganti. Karena pemilik akun menyatakan bukan dirinya, semua session ikut dicabut
+func (s *authService) EmailChangeRevert(ctx context.Context, req request.VerifyRequest) error {
+	// cek dan pakai token
+	ev, err := s.evService.ConsumeToken(ctx, req.Token, "email_revert")
+	if err != nil {
+		return err
+	}
+	if ev.Email == nil {
+		return apperror.New("[TOKEN_NOT_FOUND]", "token tidak ditemukan", nil, http.StatusUnauthorized)
+	}
+
+	// batalkan konfirmasi yang belum dipakai
+	if err := s.evService.CancelPending(ctx, ev.UserID, "email_change"); err != nil {
+		return err
+	}
+
+	// cek user
+	user, err := s.authRepo.FindByID(ctx, ev.UserID)
+	if err != nil {
+		return err
+	}
+
+	// kembalikan email lama
+	if user.Email != *ev.Email {
+		if err := s.userRepo.EmailRevert(ctx, user.ID, user.Email, *ev.Email); err != nil {
+			return err
+		}
+	}
+
+	return s.LogoutAllDevices(ctx, user.ID)
+}
+
+// comparePassword mencocokkan password lewat antrean hash, user tanpa password tetap menjalankan hash dummy
+func comparePassword(ctx context.Context, ut utils.Utility, user *model.UserModel, password string) (bool, error) {
+	if user.Password == nil {
+		return false, ut.HashDummyCompare(ctx, password)
+	}
+
+	return ut.HashCompare(ctx, *user.Password, password)
+}
+
+// verifyPassword mencocokkan password user login, password salah dihitung sebagai login gagal
+func verifyPassword(ctx context.Context, la LoginAttemptService, ut utils.Utility, user *model.UserModel, password, ipAddress string) error {
+	// cek kunci akun
+	passwordIdentifier := "password:" + user.ID
+	if err := la.CheckLock(ctx, user, passwordIdentifier, ipAddress); err != nil {
+		return err
 	}
 
 	// cek password
-	if !s.utility.HashCompare(*user.Password, req.Password) {
-		return nil, apperror.New("[PASSWORD_INVALID]", "password salah", errors.New("Password salah"), http.StatusUnauthorized)
+	match, err := comparePassword(ctx, ut, user, password)
+	if err != nil {
+		return err
+	}
+	if !match {
+		if err := la.RecordFailure(ctx, user, passwordIdentifier, ipAddress); err != nil {
+			return err
+		}
+		return apperror.New("[PASSWORD_INVALID]", "password saat ini salah", errors.New("Password salah"), http.StatusUnauthorized)
+	}
+
+	// reset percobaan gagal
+	return la.Reset(ctx, user, passwordIdentifier, ipAddress)
+}
+
+// checkPasswordPolicy mengubah pelanggaran policy menjadi error [PASSWORD_POLICY],
+// handler menampilkannya sebagai error validasi field password
+func checkPasswordPolicy(policy password.Policy, pass, email string, username *string) error {
+	identities := []string{email}
+	if username != nil {
+		identities = append(identities, *username)
 	}
 
+	if err := policy.Validate(pass, identities...); err != nil {
+		return apperror.New("[PASSWORD_POLICY]", err.Error(), err, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func (s *authService) checkEmailAvailable(ctx context.Context, userID, email string) error {
+	// cek email dari tabel users
+	emailExists, err := s.userRepo.EmailChange(ctx, &response.UserDetailResponse{ID: userID}, email)
+	if err != nil {
+		return err
+	}
+	if emailExists {
+		return apperror.New(apperror.CodeEmailConflict, "email sudah terdaftar", nil)
+	}
+
+	// cek email dari tabel email_history
+	emailHistory, err := s.emailRepo.IsEmailExists(ctx, email)
+	if err != nil {
+		return err
+	}
+	if emailHistory {
+		return apperror.New(apperror.CodeEmailConflict, "email sudah terdaftar", nil)
+	}
+
+	return nil
+}
+
+// completeLogin meminta faktor kedua jika user punya MFA atau passkey, selain itu langsung membuat token
+func (s *authService) completeLogin(ctx context.Context, user *model.UserModel, deviceID, userAgent, ipAddress string, rememberMe bool) (*response.LoginResponse, *response.MFAChallengeResponse, error) {
+	var methods []string
+	if user.MFAEnabled {
+		methods = append(methods, "totp", "recovery_code")
+	}
+	if user.PasskeyCount > 0 {
+		methods = append(methods, "webauthn")
+	}
+
+	// perangkat tepercaya tidak diminta MFA sampai masa percayanya habis
+	if len(methods) > 0 && !s.deviceService.IsTrusted(ctx, user.ID, deviceID) {
+		mfaToken, err := s.utility.MFAChallengeGenerate(user.ID, rememberMe)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return nil, &response.MFAChallengeResponse{
+			MFARequired: true,
+			MFAToken:    mfaToken,
+			ExpiresIn:   int(s.cfg.MFA.ChallengeTTL.Seconds()),
+			Methods:     methods,
+		}, nil
+	}
+
+	token, err := s.issueTokens(ctx, user, deviceID, userAgent, ipAddress, false, rememberMe)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return token, nil, nil
+}
+
+// issueTokens membuat access token dan refresh token untuk user yang sudah lolos autentikasi
+func (s *authService) issueTokens(ctx context.Context, user *model.UserModel, deviceID, userAgent, ipAddress string, trustDevice, rememberMe bool) (*response.LoginResponse, error) {
 	// ambil roles user
 	var roles []string
 	for _, r := range user.Roles {
@@ -80,22 +756,36 @@ func (s *authService) Login(ctx context.Context, req request.LoginRequest, userA
 		return nil, err
 	}
 
-	// insert refresh token
-	if err := s.usRepo.Create(ctx, &model.UserSession{
+	// insert refresh token, umur session mengikuti kebijakan role dan pilihan remember me
+	now := time.Now()
+	policy := sessionPolicy(s.cfg.Session, user.Roles, rememberMe)
+	session := &model.UserSession{
 		ID:               s.utility.ULIDGenerate(),
 		UserID:           user.ID,
 		RefreshTokenHash: s.utility.HashToken(refreshToken),
-		DeviceID:         "coba saja",
+		DeviceID:         deviceID,
+		RememberMe:       rememberMe,
 		IPAddress:        ipAddress,
 		UserAgent:        userAgent,
-		ExpiresAt:        time.Now().Add(7 * 24 * time.Hour),
-	}); err != nil {
+		ExpiresAt:        sessionExpiresAt(policy, now, now),
+	}
+	if err := s.usRepo.Create(ctx, session); err != nil {
+		return nil, err
+	}
+
+	// catat perangkat, trust hanya setelah MFA berhasil
+	if err := s.deviceService.Touch(ctx, user.ID, deviceID, userAgent, ipAddress, trustDevice); err != nil {
 		return nil, err
 	}
 
+	// kirim notifikasi jika login dari perangkat atau jaringan baru
+	s.notifyService.NotifyLogin(ctx, user, session)
+
 	return &response.LoginResponse{
-		AccessToken:  token,
-		RefreshToken: refreshToken,
+		AccessToken:      token,
+		RefreshToken:     refreshToken,
+		ExpiresIn:        int(s.cfg.JWT.AccessTokenTTL.Seconds()),
+		RefreshExpiresIn: int(session.ExpiresAt.Sub(now).Seconds()),
 	}, nil
 }
 
@@ -121,20 +811,37 @@ func (s *authService) LogoutAllDevices(ctx context.Context, userID string) error
 		return apperror.New(apperror.CodeInternalError, "generate new token version gagal", err)
 	}
 
-	// update token version
+	// update token version, access token yang sudah terbit langsung ditolak AuthMiddleware
 	if err := s.authRepo.UpdateTokenVersion(ctx, userID, newTokenVersion); err != nil {
 		return err
 	}
+	s.tokenCache.Invalidate(userID)
 
 	//revoke semua session
 	if err := s.usRepo.RevokeAllSessionByUserID(ctx, userID); err != nil {
 		return err
 	}
 
+	// perangkat tepercaya harus MFA lagi
+	if err := s.deviceService.UntrustAll(ctx, userID); err != nil {
+		return err
+	}
+
 	return nil
 }
 
 func (s *authService) Register(ctx context.Context, req request.RegisterRequest) (string, error) {
+	// cek kebijakan username, sama seperti saat ganti username
+	req.Username = strings.ToLower(strings.TrimSpace(req.Username))
+	if err := usernamePolicy(req.Username, s.cfg.Username); err != nil {
+		return "", err
+	}
+
+	// cek password policy
+	if err := checkPasswordPolicy(s.pwPolicy, req.Password, req.Email, &req.Username); err != nil {
+		return "", err
+	}
+
 	// cek roles
 	roles, err := s.roleRepo.CheckRoles(ctx, req.Roles)
 	if err != nil {
@@ -178,9 +885,9 @@ func (s *authService) Register(ctx context.Context, req request.RegisterRequest)
 	}
 
 	// generate password
-	passHash, err := s.utility.HashGenerate(req.Password)
+	passHash, err := s.utility.HashGenerate(ctx, req.Password)
 	if err != nil {
-		return "", apperror.New("[CODE_GENERATE_HASH_INVALID]", "gagal generate hash", err, 505)
+		return "", err
 	}
 
 	// generate token version
@@ -200,7 +907,6 @@ func (s *authService) Register(ctx context.Context, req request.RegisterRequest)
 		TokenVersion:   tokenVersion,
 		EmailVerified:  false,
 		CreatedByAdmin: false,
-		GoogleID:       nil,
 		Profile: model.ProfileModel{
 			ID:       s.utility.ULIDGenerate(),
 			UserID:   userID,
@@ -227,5 +933,11 @@ func (s *authService) Register(ctx context.Context, req request.RegisterRequest)
 }
 
 func (s *authService) Me(ctx context.Context, userID string) (*response.UserDetailResponse, error) {
-	return s.authRepo.Me(ctx, userID)
+	user, err := s.authRepo.Me(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	user.Profile.ImageURLs = s.profService.ImageURLs(user.Profile.Image)
+	return user, nil
 }
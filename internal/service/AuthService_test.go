@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/gogaruda/apperror"
+	"github.com/irawankilmer/auth-service/internal/configs"
+	"github.com/irawankilmer/auth-service/internal/dto/request"
+	"github.com/irawankilmer/auth-service/internal/model"
+	"github.com/irawankilmer/auth-service/internal/repository"
+	"github.com/irawankilmer/auth-service/pkg/password"
+	"github.com/irawankilmer/auth-service/pkg/utils"
+	"testing"
+	"time"
+)
+
+// fakeAuthRepo hanya mengimplementasikan method yang dipakai test, method lain panic lewat interface nil
+type fakeAuthRepo struct {
+	repository.AuthRepository
+	users     map[string]*model.UserModel
+	updateErr error
+	updates   []string
+}
+
+func (f *fakeAuthRepo) FindByID(_ context.Context, userID string) (*model.UserModel, error) {
+	user, ok := f.users[userID]
+	if !ok {
+		return nil, apperror.New(apperror.CodeUserNotFound, "user tidak ditemukan", nil)
+	}
+
+	copied := *user
+	return &copied, nil
+}
+
+func (f *fakeAuthRepo) UpdateTokenVersion(_ context.Context, userID, newTokenVersion string) error {
+	f.users[userID].TokenVersion = newTokenVersion
+	return nil
+}
+
+func (f *fakeAuthRepo) IncrementLoginFailure(_ context.Context, userID string, _ time.Duration) (*model.UserModel, error) {
+	user := f.users[userID]
+	user.FailedLoginAttempts++
+
+	return &model.UserModel{ID: userID, FailedLoginAttempts: user.FailedLoginAttempts, LockoutCount: user.LockoutCount}, nil
+}
+
+func (f *fakeAuthRepo) LockAccount(_ context.Context, userID string, lockoutCount int, lockedUntil time.Time) error {
+	user := f.users[userID]
+	user.FailedLoginAttempts, user.LockoutCount, user.LockedUntil = 0, lockoutCount, &lockedUntil
+	return nil
+}
+
+func (f *fakeAuthRepo) ResetLoginFailure(_ context.Context, userID string) error {
+	user := f.users[userID]
+	user.FailedLoginAttempts, user.LockoutCount, user.LockedUntil = 0, 0, nil
+	return nil
+}
+
+func (f *fakeAuthRepo) UpdatePasswordHash(_ context.Context, _, _, newHash string) error {
+	if f.updateErr != nil {
+		return f.updateErr
+	}
+	f.updates = append(f.updates, newHash)
+	return nil
+}
+
+func newTestUtility(t *testing.T, hash configs.PasswordHashConfig) utils.Utility {
+	t.Helper()
+
+	hash.Workers, hash.QueueSize, hash.QueueTimeout = 1, 4, time.Second
+	hasher, err := password.NewHasher(hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	utility, err := utils.NewUtility(&configs.AppConfig{Hash: hash}, hasher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return utility
+}
+
+// newTestBcrypt hasher bcrypt cost minimum untuk test yang tidak menguji hash password
+func newTestBcrypt(t *testing.T) password.Hasher {
+	t.Helper()
+
+	hasher, err := password.NewHasher(configs.PasswordHashConfig{Algorithm: "bcrypt", BcryptCost: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return hasher
+}
+
+// TestRehashPasswordOnLogin hash dari parameter lama dibuat ulang dengan parameter saat ini setelah login berhasil
+func TestRehashPasswordOnLogin(t *testing.T) {
+	oldArgon2 := configs.PasswordHashConfig{Algorithm: "argon2id", Argon2Memory: 64, Argon2Iterations: 1, Argon2Parallelism: 1}
+	newArgon2 := configs.PasswordHashConfig{Algorithm: "argon2id", Argon2Memory: 128, Argon2Iterations: 2, Argon2Parallelism: 1}
+	oldBcrypt := configs.PasswordHashConfig{Algorithm: "bcrypt", BcryptCost: 4}
+
+	tests := []struct {
+		name       string
+		old        configs.PasswordHashConfig
+		current    configs.PasswordHashConfig
+		updateErr  error
+		wantRehash bool
+	}{
+		{name: "parameter tidak berubah", old: newArgon2, current: newArgon2},
+		{name: "parameter argon2id dinaikkan", old: oldArgon2, current: newArgon2, wantRehash: true},
+		{name: "bcrypt pindah ke argon2id", old: oldBcrypt, current: newArgon2, wantRehash: true},
+		{name: "simpan hash gagal", old: oldBcrypt, current: newArgon2, updateErr: errors.New("db mati")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			oldHash, err := newTestUtility(t, tt.old).HashGenerate(ctx, "rahasia")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			repo := &fakeAuthRepo{updateErr: tt.updateErr}
+			current := newTestUtility(t, tt.current)
+			s := &authService{authRepo: repo, utility: current}
+			user := &model.UserModel{ID: "u1", Password: &oldHash}
+
+			// login tetap memakai hash lama untuk verifikasi
+			if ok, err := current.HashCompare(ctx, oldHash, "rahasia"); err != nil || !ok {
+				t.Fatalf("hash lama tidak bisa diverifikasi: ok=%v err=%v", ok, err)
+			}
+			s.rehashPassword(ctx, user, "rahasia")
+
+			if got := len(repo.updates) == 1; got != tt.wantRehash {
+				t.Fatalf("rehash disimpan = %v, ingin %v", got, tt.wantRehash)
+			}
+			if !tt.wantRehash {
+				if *user.Password != oldHash {
+					t.Error("hash user berubah padahal tidak disimpan")
+				}
+				return
+			}
+
+			if *user.Password != repo.updates[0] || current.HashNeedsRehash(*user.Password) {
+				t.Errorf("hash user = %q, ingin hash baru dengan parameter saat ini", *user.Password)
+			}
+			if ok, err := current.HashCompare(ctx, *user.Password, "rahasia"); err != nil || !ok {
+				t.Errorf("hash baru tidak cocok: ok=%v err=%v", ok, err)
+			}
+		})
+	}
+}
+
+// TestRegisterUsernamePolicy username yang melanggar kebijakan ditolak sebelum menyentuh database
+func TestRegisterUsernamePolicy(t *testing.T) {
+	s := &authService{cfg: &configs.AppConfig{Username: configs.UsernameConfig{MinLength: 3, MaxLength: 30}}}
+
+	tests := []struct {
+		name     string
+		username string
+	}{
+		{name: "terlalu pendek", username: "ab"},
+		{name: "terlalu panjang", username: "abcdefghijklmnopqrstuvwxyz12345"},
+		{name: "diawali angka", username: "1alice"},
+		{name: "diawali titik", username: ".alice"},
+		{name: "karakter tidak diizinkan", username: "alice-bob"},
+		{name: "huruf non ASCII", username: "alicé"},
+		{name: "hanya spasi", username: "   "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.Register(context.Background(), request.RegisterRequest{
+				FullName: "Alice", Username: tt.username, Email: "alice@example.com", Password: "rahasia-panjang-123",
+			})
+			if !apperror.Is(err, "[USERNAME_POLICY]") {
+				t.Errorf("err = %v, ingin [USERNAME_POLICY]", err)
+			}
+		})
+	}
+}
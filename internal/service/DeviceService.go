@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"github.com/irawankilmer/auth-service/internal/configs"
+	"github.com/irawankilmer/auth-service/internal/dto/request"
+	"github.com/irawankilmer/auth-service/internal/dto/response"
+	"github.com/irawankilmer/auth-service/internal/model"
+	"github.com/irawankilmer/auth-service/internal/repository"
+	"github.com/irawankilmer/auth-service/pkg/device"
+	"github.com/irawankilmer/auth-service/pkg/utils"
+	"strings"
+	"time"
+)
+
+type DeviceService interface {
+	Touch(ctx context.Context, userID, deviceID, userAgent, ipAddress string, trust bool) error
+	IsTrusted(ctx context.Context, userID, deviceID string) bool
+	List(ctx context.Context, userID, currentDeviceID string) ([]response.DeviceResponse, error)
+	Rename(ctx context.Context, userID, id string, req request.DeviceRenameRequest) error
+	Untrust(ctx context.Context, userID, id string) error
+	UntrustAll(ctx context.Context, userID string) error
+}
+
+type deviceService struct {
+	deviceRepo repository.UserDeviceRepository
+	utility    utils.Utility
+	cfg        configs.DeviceConfig
+}
+
+func NewDeviceService(dr repository.UserDeviceRepository, ut utils.Utility, cfg configs.DeviceConfig) DeviceService {
+	return &deviceService{deviceRepo: dr, utility: ut, cfg: cfg}
+}
+
+// Touch mencatat perangkat yang dipakai login/refresh beserta hasil parsing user agent-nya,
+// trust menandai perangkat tepercaya selama TrustTTL (dipakai setelah MFA berhasil)
+func (s *deviceService) Touch(ctx context.Context, userID, deviceID, userAgent, ipAddress string, trust bool) error {
+	if deviceID == "" {
+		return nil
+	}
+
+	now := s.utility.Now().Truncate(time.Second)
+	ua := device.ParseUserAgent(userAgent)
+	d := &model.UserDeviceModel{
+		ID:             s.utility.ULIDGenerate(),
+		UserID:         userID,
+		DeviceID:       deviceID,
+		Browser:        ua.Browser,
+		BrowserVersion: ua.BrowserVersion,
+		OS:             ua.OS,
+		OSVersion:      ua.OSVersion,
+		DeviceType:     ua.DeviceType,
+		DeviceModel:    ua.DeviceModel,
+		LastIP:         ipAddress,
+		LastSeenAt:     now,
+	}
+	if trust {
+		trustedUntil := now.Add(s.cfg.TrustTTL)
+		d.TrustedUntil = &trustedUntil
+	}
+
+	return s.deviceRepo.Upsert(ctx, d)
+}
+
+// IsTrusted gagal cek dianggap tidak tepercaya sehingga MFA tetap diminta
+func (s *deviceService) IsTrusted(ctx context.Context, userID, deviceID string) bool {
+	if deviceID == "" {
+		return false
+	}
+
+	d, err := s.deviceRepo.FindByDeviceID(ctx, userID, deviceID)
+	if err != nil {
+		return false
+	}
+
+	return d.TrustedUntil != nil && d.TrustedUntil.After(s.utility.Now())
+}
+
+func (s *deviceService) List(ctx context.Context, userID, currentDeviceID string) ([]response.DeviceResponse, error) {
+	devices, err := s.deviceRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.utility.Now()
+	result := make([]response.DeviceResponse, 0, len(devices))
+	for _, d := range devices {
+		trusted := d.TrustedUntil != nil && d.TrustedUntil.After(now)
+		item := response.DeviceResponse{
+			ID:             d.ID,
+			Name:           d.Name,
+			Description:    deviceDescription(d),
+			Browser:        d.Browser,
+			BrowserVersion: d.BrowserVersion,
+			OS:             d.OS,
+			OSVersion:      d.OSVersion,
+			DeviceType:     d.DeviceType,
+			DeviceModel:    d.DeviceModel,
+			LastIP:         d.LastIP,
+			Trusted:        trusted,
+			Current:        d.DeviceID == currentDeviceID,
+			LastSeenAt:     d.LastSeenAt,
+			CreatedAt:      d.CreatedAt,
+		}
+		if trusted {
+			item.TrustedUntil = d.TrustedUntil
+		}
+		result = append(result, item)
+	}
+
+	return result, nil
+}
+
+func (s *deviceService) Rename(ctx context.Context, userID, id string, req request.DeviceRenameRequest) error {
+	// cek perangkat milik user
+	if _, err := s.deviceRepo.FindByID(ctx, userID, id); err != nil {
+		return err
+	}
+
+	var name *string
+	if trimmed := strings.TrimSpace(req.Name); trimmed != "" {
+		name = &trimmed
+	}
+
+	return s.deviceRepo.UpdateName(ctx, userID, id, name)
+}
+
+func (s *deviceService) Untrust(ctx context.Context, userID, id string) error {
+	// cek perangkat milik user
+	if _, err := s.deviceRepo.FindByID(ctx, userID, id); err != nil {
+		return err
+	}
+
+	return s.deviceRepo.UpdateTrustedUntil(ctx, userID, id, nil)
+}
+
+func (s *deviceService) UntrustAll(ctx context.Context, userID string) error {
+	return s.deviceRepo.UntrustAll(ctx, userID)
+}
+
+func deviceDescription(d model.UserDeviceModel) string {
+	return device.UserAgent{
+		Browser:        d.Browser,
+		BrowserVersion: d.BrowserVersion,
+		OS:             d.OS,
+		OSVersion:      d.OSVersion,
+		DeviceType:     d.DeviceType,
+		DeviceModel:    d.DeviceModel,
+	}.String()
+}
@@ -9,7 +9,10 @@ import (
 	"github.com/irawankilmer/auth-service/internal/model"
 	"github.com/irawankilmer/auth-service/internal/repository"
 	"github.com/irawankilmer/auth-service/pkg/mailer"
+	"github.com/irawankilmer/auth-service/pkg/password"
 	"github.com/irawankilmer/auth-service/pkg/utils"
+	"html"
+	"log"
 	"net/http"
 	"time"
 )
@@ -20,6 +23,12 @@ type EmailVerificationService interface {
 	VerifyToken(ctx context.Context, token string) error
 	CheckToken(ctx context.Context, token string) (*model.UserModel, error)
 	UpdateRegisterByAdmin(ctx context.Context, req *request.VerifyRegisterByAdminRequest, ev *model.EmailVerificationModel) error
+	SendMagicLink(ctx context.Context, user *model.UserModel, cfg configs.MagicLinkConfig) error
+	ConsumeToken(ctx context.Context, token, actionType string) (*model.EmailVerificationModel, error)
+	SendPasswordReset(ctx context.Context, user *model.UserModel, cfg configs.PasswordResetConfig) error
+	NotifyPasswordChanged(user *model.UserModel, ipAddress, userAgent string)
+	SendEmailChange(ctx context.Context, user *model.UserModel, newEmail string, cfg configs.EmailChangeConfig) error
+	CancelPending(ctx context.Context, userID, actionType string) error
 }
 
 type emailVerificationService struct {
@@ -29,14 +38,15 @@ type emailVerificationService struct {
 	cfgMail      configs.EmailConfig
 	userRepo     repository.UserRepository
 	usernameRepo repository.UsernameHistoryRepository
+	pwPolicy     password.Policy
 }
 
 func NewEmailVerificationService(
 	ev repository.EmailVerificationRepository, m *mailer.Mailer, u utils.Utility,
 	cm configs.EmailConfig, ur repository.UserRepository,
-	uhr repository.UsernameHistoryRepository,
+	uhr repository.UsernameHistoryRepository, pp password.Policy,
 ) EmailVerificationService {
-	return &emailVerificationService{evRepo: ev, mail: m, utilities: u, cfgMail: cm, userRepo: ur, usernameRepo: uhr}
+	return &emailVerificationService{evRepo: ev, mail: m, utilities: u, cfgMail: cm, userRepo: ur, usernameRepo: uhr, pwPolicy: pp}
 }
 
 func (s *emailVerificationService) FindByToken(ctx context.Context, token string) (*model.EmailVerificationModel, error) {
@@ -60,21 +70,9 @@ func (s *emailVerificationService) FindByToken(ctx context.Context, token string
 }
 
 func (s *emailVerificationService) SendVerification(ctx context.Context, user *model.UserModel, urlTo, actionType string, duration time.Duration) (string, error) {
-	// generate token
-	token, err := s.mail.GenerateRandom(64)
-	if err != nil {
-		return "", err
-	}
-
 	// create verification
-	ev := &model.EmailVerificationModel{
-		ID:         s.utilities.ULIDGenerate(),
-		UserID:     user.ID,
-		Token:      token,
-		ExpiresAt:  time.Now().UTC().Add(duration),
-		ActionType: actionType,
-	}
-	if err := s.evRepo.Create(ctx, ev); err != nil {
+	token, err := s.createToken(ctx, user.ID, actionType, nil, duration)
+	if err != nil {
 		return "", err
 	}
 
@@ -186,10 +184,19 @@ func (s *emailVerificationService) UpdateRegisterByAdmin(ctx context.Context, re
 		apperror.New(apperror.CodeUsernameConflict, "username sudah tidak dapat digunakan", nil)
 	}
 
+	// cek password policy
+	user, err := s.userRepo.FindByID(ctx, ev.UserID)
+	if err != nil {
+		return err
+	}
+	if err := checkPasswordPolicy(s.pwPolicy, req.Password, user.Email, &req.Username); err != nil {
+		return err
+	}
+
 	// Generate password
-	passHash, err := s.utilities.HashGenerate(req.Password)
+	passHash, err := s.utilities.HashGenerate(ctx, req.Password)
 	if err != nil {
-		return apperror.New(apperror.CodeInternalError, "generate password gagal", err)
+		return err
 	}
 
 	// update is_used
@@ -199,3 +206,243 @@ func (s *emailVerificationService) UpdateRegisterByAdmin(ctx context.Context, re
 
 	return s.evRepo.UpdateRegisterByAdmin(ctx, req.Username, passHash, ev.UserID)
 }
+
+// SendMagicLink mengirim link login sekali pakai, dibatasi jumlah permintaan per alamat email dalam satu window
+func (s *emailVerificationService) SendMagicLink(ctx context.Context, user *model.UserModel, cfg configs.MagicLinkConfig) error {
+	// cek batas permintaan
+	if err := s.checkLimit(ctx, user.ID, "magic_link", cfg.MaxRequests, cfg.Window); err != nil {
+		return err
+	}
+
+	// create token
+	token, err := s.createToken(ctx, user.ID, "magic_link", nil, cfg.TTL)
+	if err != nil {
+		return err
+	}
+
+	// send mail
+	url := fmt.Sprintf("%s/magic-link?token=%s", s.cfgMail.FrontVerifyUrl, token)
+	body := fmt.Sprintf(`
+	<h2>Link Login Anda</h2>
+	<p>Halo,</p>
+	<p>Kami menerima permintaan login tanpa password untuk akun Anda. Klik tombol di bawah ini untuk masuk:</p>
+	<p><a href='%s' style='
+		display: inline-block;
+		padding: 10px 20px;
+		background-color: #4CAF50;
+		color: white;
+		text-decoration: none;
+		border-radius: 5px;
+		font-weight: bold;
+	'>Masuk</a></p>
+	<p>Jika tombol di atas tidak bekerja, salin dan tempel URL berikut ke browser Anda:</p>
+	<p><code>%s</code></p>
+	<p>Link ini hanya bisa dipakai sekali dan akan kadaluarsa dalam %d menit. Jika Anda tidak meminta link ini, abaikan email ini.</p>
+	<p>Salam hangat,<br><strong>Tim Support %s</strong></p>
+`, url, url, int(cfg.TTL.Minutes()), "Sekolah Kita")
+
+	if err := s.mail.Send(user.Email, "Link Login", body); err != nil {
+		return apperror.New("[SEND_MAGIC_LINK_FAILED]", "link login gagal dikirim", err, 505)
+	}
+
+	return nil
+}
+
+// ConsumeToken memvalidasi token dengan action_type tertentu lalu menandainya terpakai
+func (s *emailVerificationService) ConsumeToken(ctx context.Context, token, actionType string) (*model.EmailVerificationModel, error) {
+	// cek token, status pakai dan masa aktif
+	ev, err := s.FindByToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	// token dari alur lain tidak boleh dipakai
+	if ev.ActionType != actionType {
+		return nil, apperror.New("[TOKEN_NOT_FOUND]", "token tidak ditemukan", nil, http.StatusUnauthorized)
+	}
+
+	// tandai terpakai, permintaan bersamaan hanya satu yang berhasil
+	if err := s.evRepo.Claim(ctx, ev.ID); err != nil {
+		return nil, err
+	}
+
+	return ev, nil
+}
+
+// SendPasswordReset mengirim token reset password, dibatasi jumlah permintaan per alamat email dalam satu window
+func (s *emailVerificationService) SendPasswordReset(ctx context.Context, user *model.UserModel, cfg configs.PasswordResetConfig) error {
+	// cek batas permintaan
+	if err := s.checkLimit(ctx, user.ID, "password_change", cfg.MaxRequests, cfg.Window); err != nil {
+		return err
+	}
+
+	// create token
+	token, err := s.createToken(ctx, user.ID, "password_change", nil, cfg.TTL)
+	if err != nil {
+		return err
+	}
+
+	// send mail
+	url := fmt.Sprintf("%s/reset-password?token=%s", s.cfgMail.FrontVerifyUrl, token)
+	body := fmt.Sprintf(`
+	<h2>Reset Password</h2>
+	<p>Halo,</p>
+	<p>Kami menerima permintaan reset password untuk akun Anda. Klik tombol di bawah ini untuk membuat password baru:</p>
+	<p><a href='%s' style='
+		display: inline-block;
+		padding: 10px 20px;
+		background-color: #4CAF50;
+		color: white;
+		text-decoration: none;
+		border-radius: 5px;
+		font-weight: bold;
+	'>Reset Password</a></p>
+	<p>Jika tombol di atas tidak bekerja, salin dan tempel URL berikut ke browser Anda:</p>
+	<p><code>%s</code></p>
+	<p>Link ini hanya bisa dipakai sekali dan akan kadaluarsa dalam %d menit. Jika Anda tidak meminta reset password, abaikan email ini.</p>
+	<p>Salam hangat,<br><strong>Tim Support %s</strong></p>
+`, url, url, int(cfg.TTL.Minutes()), "Sekolah Kita")
+
+	if err := s.mail.Send(user.Email, "Reset Password", body); err != nil {
+		return apperror.New("[SEND_PASSWORD_RESET_FAILED]", "email reset password gagal dikirim", err, 505)
+	}
+
+	return nil
+}
+
+// NotifyPasswordChanged mengirim konfirmasi password berhasil diganti, kegagalan kirim hanya dicatat
+func (s *emailVerificationService) NotifyPasswordChanged(user *model.UserModel, ipAddress, userAgent string) {
+	body := fmt.Sprintf(`
+	<h2>Password Berhasil Diganti</h2>
+	<p>Halo,</p>
+	<p>Password akun Anda baru saja diganti dan sesi login di perangkat lain sudah diakhiri.</p>
+	<ul>
+		<li>Waktu: %s</li>
+		<li>Alamat IP: %s</li>
+		<li>Perangkat: %s</li>
+	</ul>
+	<p>Jika ini bukan Anda, segera lakukan reset password dan hubungi admin.</p>
+	<p>Salam hangat,<br><strong>Tim Support %s</strong></p>
+`, s.utilities.Now().Format(time.RFC1123), html.EscapeString(ipAddress), html.EscapeString(userAgent), "Sekolah Kita")
+
+	if err := s.mail.Send(user.Email, "Password Berhasil Diganti", body); err != nil {
+		log.Printf("[WARN] konfirmasi ganti password gagal dikirim ke user %s: %v", user.ID, err)
+	}
+}
+
+// SendEmailChange mengirim link konfirmasi ke email baru dan link pembatalan ke email lama.
+// Email user baru berubah setelah link konfirmasi dipakai, sampai saat itu email lama tetap dipakai login
+func (s *emailVerificationService) SendEmailChange(ctx context.Context, user *model.UserModel, newEmail string, cfg configs.EmailChangeConfig) error {
+	// cek batas permintaan
+	if err := s.checkLimit(ctx, user.ID, "email_change", cfg.MaxRequests, cfg.Window); err != nil {
+		return err
+	}
+
+	// permintaan sebelumnya yang belum dikonfirmasi dibatalkan
+	if err := s.evRepo.MarkAllAsUsed(ctx, user.ID, "email_change"); err != nil {
+		return err
+	}
+
+	// create token konfirmasi dan token pembatalan
+	confirmToken, err := s.createToken(ctx, user.ID, "email_change", &newEmail, cfg.TTL)
+	if err != nil {
+		return err
+	}
+	oldEmail := user.Email
+	revertToken, err := s.createToken(ctx, user.ID, "email_revert", &oldEmail, cfg.RevertTTL)
+	if err != nil {
+		return err
+	}
+
+	// send mail ke email baru
+	url := fmt.Sprintf("%s/email-change/confirm?token=%s", s.cfgMail.FrontVerifyUrl, confirmToken)
+	body := fmt.Sprintf(`
+	<h2>Konfirmasi Email Baru</h2>
+	<p>Halo,</p>
+	<p>Alamat email ini diminta menjadi email baru akun Anda. Klik tombol di bawah ini untuk mengonfirmasi:</p>
+	<p><a href='%s' style='
+		display: inline-block;
+		padding: 10px 20px;
+		background-color: #4CAF50;
+		color: white;
+		text-decoration: none;
+		border-radius: 5px;
+		font-weight: bold;
+	'>Konfirmasi Email</a></p>
+	<p>Jika tombol di atas tidak bekerja, salin dan tempel URL berikut ke browser Anda:</p>
+	<p><code>%s</code></p>
+	<p>Link ini akan kadaluarsa dalam %d menit. Jika Anda tidak meminta perubahan ini, abaikan email ini.</p>
+	<p>Salam hangat,<br><strong>Tim Support %s</strong></p>
+`, url, url, int(cfg.TTL.Minutes()), "Sekolah Kita")
+
+	if err := s.mail.Send(newEmail, "Konfirmasi Email Baru", body); err != nil {
+		return apperror.New("[SEND_EMAIL_CHANGE_FAILED]", "email konfirmasi gagal dikirim", err, 505)
+	}
+
+	// send mail ke email lama, kegagalan kirim hanya dicatat
+	url = fmt.Sprintf("%s/email-change/revert?token=%s", s.cfgMail.FrontVerifyUrl, revertToken)
+	body = fmt.Sprintf(`
+	<h2>Permintaan Ganti Email</h2>
+	<p>Halo,</p>
+	<p>Ada permintaan untuk mengganti email akun Anda menjadi <strong>%s</strong>. Email lama tetap dipakai sampai email baru dikonfirmasi.</p>
+	<p>Jika ini bukan Anda, klik tombol di bawah ini untuk membatalkan atau mengembalikan perubahan email dan mengeluarkan semua perangkat:</p>
+	<p><a href='%s' style='
+		display: inline-block;
+		padding: 10px 20px;
+		background-color: #f44336;
+		color: white;
+		text-decoration: none;
+		border-radius: 5px;
+		font-weight: bold;
+	'>Ini Bukan Saya</a></p>
+	<p>Jika tombol di atas tidak bekerja, salin dan tempel URL berikut ke browser Anda:</p>
+	<p><code>%s</code></p>
+	<p>Link ini berlaku selama %d jam.</p>
+	<p>Salam hangat,<br><strong>Tim Support %s</strong></p>
+`, html.EscapeString(newEmail), url, url, int(cfg.RevertTTL.Hours()), "Sekolah Kita")
+
+	if err := s.mail.Send(oldEmail, "Permintaan Ganti Email", body); err != nil {
+		log.Printf("[WARN] notifikasi ganti email gagal dikirim ke user %s: %v", user.ID, err)
+	}
+
+	return nil
+}
+
+func (s *emailVerificationService) CancelPending(ctx context.Context, userID, actionType string) error {
+	return s.evRepo.MarkAllAsUsed(ctx, userID, actionType)
+}
+
+// checkLimit menolak pembuatan token baru jika permintaan dalam window sudah mencapai batas
+func (s *emailVerificationService) checkLimit(ctx context.Context, userID, actionType string, max int, window time.Duration) error {
+	total, err := s.evRepo.CountRecent(ctx, userID, actionType, window)
+	if err != nil {
+		return err
+	}
+	if total >= max {
+		return apperror.New("[EMAIL_TOKEN_LIMITED]", "permintaan email terlalu sering", nil, http.StatusTooManyRequests)
+	}
+
+	return nil
+}
+
+func (s *emailVerificationService) createToken(ctx context.Context, userID, actionType string, email *string, duration time.Duration) (string, error) {
+	// generate token
+	token, err := s.mail.GenerateRandom(64)
+	if err != nil {
+		return "", err
+	}
+
+	ev := &model.EmailVerificationModel{
+		ID:         s.utilities.ULIDGenerate(),
+		UserID:     userID,
+		Token:      token,
+		Email:      email,
+		ExpiresAt:  time.Now().UTC().Add(duration),
+		ActionType: actionType,
+	}
+	if err := s.evRepo.Create(ctx, ev); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
@@ -0,0 +1,312 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gogaruda/apperror"
+	"github.com/irawankilmer/auth-service/internal/configs"
+	"github.com/irawankilmer/auth-service/internal/dto/response"
+	"github.com/irawankilmer/auth-service/internal/model"
+	"github.com/irawankilmer/auth-service/internal/repository"
+	"github.com/irawankilmer/auth-service/pkg/identity"
+	"github.com/irawankilmer/auth-service/pkg/utils"
+	"golang.org/x/oauth2"
+	"net/http"
+	"strings"
+)
+
+type IdentityService interface {
+	AuthURL(provider string) (string, string, error)
+	LinkURL(provider, userID string) (*response.IdentityLinkResponse, string, error)
+	Callback(ctx context.Context, provider, stateCookie, state, code string) (*model.UserModel, bool, error)
+	List(ctx context.Context, userID string) ([]response.IdentityResponse, error)
+	Unlink(ctx context.Context, userID, provider string) error
+}
+
+type identityService struct {
+	authRepo     repository.AuthRepository
+	userRepo     repository.UserRepository
+	roleRepo     repository.RoleRepository
+	emailRepo    repository.EmailHistoryRepository
+	identityRepo repository.UserIdentityRepository
+	providers    map[string]identity.Provider
+	utility      utils.Utility
+	cfg          *configs.AppConfig
+}
+
+func NewIdentityService(
+	ar repository.AuthRepository, ur repository.UserRepository, rp repository.RoleRepository,
+	email repository.EmailHistoryRepository, ir repository.UserIdentityRepository,
+	providers map[string]identity.Provider, ut utils.Utility, cfg *configs.AppConfig,
+) IdentityService {
+	return &identityService{
+		authRepo: ar, userRepo: ur, roleRepo: rp, emailRepo: email, identityRepo: ir,
+		providers: providers, utility: ut, cfg: cfg,
+	}
+}
+
+// identityState disimpan terenkripsi di cookie selama proses redirect ke provider,
+// UserID terisi jika redirect untuk menghubungkan akun, bukan login
+type identityState struct {
+	Provider  string  `json:"provider"`
+	State     string  `json:"state"`
+	Nonce     string  `json:"nonce"`
+	Verifier  string  `json:"verifier"`
+	UserID    *string `json:"user_id,omitempty"`
+	ExpiresAt int64   `json:"expires_at"`
+}
+
+// AuthURL membuat URL otorisasi provider untuk login beserta nilai cookie state
+func (s *identityService) AuthURL(provider string) (string, string, error) {
+	return s.authURL(provider, nil)
+}
+
+// LinkURL membuat URL otorisasi provider untuk menghubungkan akun ke user yang sedang login
+func (s *identityService) LinkURL(provider, userID string) (*response.IdentityLinkResponse, string, error) {
+	authURL, cookie, err := s.authURL(provider, &userID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &response.IdentityLinkResponse{AuthURL: authURL}, cookie, nil
+}
+
+func (s *identityService) authURL(provider string, linkUserID *string) (string, string, error) {
+	p, err := s.provider(provider)
+	if err != nil {
+		return "", "", err
+	}
+
+	state, err := s.utility.RefreshTokenGenerate()
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := s.utility.RefreshTokenGenerate()
+	if err != nil {
+		return "", "", err
+	}
+
+	payload := identityState{
+		Provider:  provider,
+		State:     state,
+		Nonce:     nonce,
+		Verifier:  oauth2.GenerateVerifier(),
+		UserID:    linkUserID,
+		ExpiresAt: s.utility.Now().Add(s.cfg.Identity.StateTTL).Unix(),
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", "", apperror.New(apperror.CodeInternalError, "encode state login gagal", err)
+	}
+	cookie, err := s.utility.Encrypt(string(data))
+	if err != nil {
+		return "", "", err
+	}
+
+	return p.AuthCodeURL(state, nonce, payload.Verifier), cookie, nil
+}
+
+// Callback memvalidasi callback provider. Untuk login mengembalikan user yang terhubung (dibuat baru jika belum ada),
+// untuk menghubungkan akun mengembalikan linked = true
+func (s *identityService) Callback(ctx context.Context, provider, stateCookie, state, code string) (*model.UserModel, bool, error) {
+	invalid := apperror.New("[IDENTITY_AUTH_INVALID]", "login "+provider+" gagal, silakan ulangi", errors.New("callback provider tidak valid"), http.StatusUnauthorized)
+
+	p, err := s.provider(provider)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// cek state
+	plain, err := s.utility.Decrypt(stateCookie)
+	if err != nil {
+		return nil, false, invalid
+	}
+	var payload identityState
+	if err := json.Unmarshal([]byte(plain), &payload); err != nil {
+		return nil, false, invalid
+	}
+	if payload.State == "" || payload.State != state || payload.Provider != provider || s.utility.Now().Unix() > payload.ExpiresAt {
+		return nil, false, invalid
+	}
+
+	// tukar code dan verifikasi identitas di provider
+	ident, err := p.Exchange(ctx, code, payload.Nonce, payload.Verifier)
+	if err != nil {
+		return nil, false, apperror.New("[IDENTITY_AUTH_INVALID]", "login "+provider+" gagal, silakan ulangi", err, http.StatusUnauthorized)
+	}
+
+	// hubungkan ke user yang sedang login
+	if payload.UserID != nil {
+		if err := s.identityRepo.Create(ctx, &model.UserIdentityModel{
+			UserID:   *payload.UserID,
+			Provider: provider,
+			Subject:  ident.Subject,
+			Email:    identityEmail(ident),
+		}); err != nil {
+			return nil, false, err
+		}
+
+		return nil, true, nil
+	}
+
+	user, err := s.resolveUser(ctx, provider, ident)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return user, false, nil
+}
+
+func (s *identityService) List(ctx context.Context, userID string) ([]response.IdentityResponse, error) {
+	identities, err := s.identityRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]response.IdentityResponse, 0, len(identities))
+	for _, i := range identities {
+		result = append(result, response.IdentityResponse{Provider: i.Provider, Email: i.Email, LinkedAt: i.LinkedAt})
+	}
+
+	return result, nil
+}
+
+func (s *identityService) Unlink(ctx context.Context, userID, provider string) error {
+	// cek sisa metode login
+	user, err := s.authRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if loginMethodCount(user) <= 1 {
+		return lastLoginMethodError()
+	}
+
+	return s.identityRepo.Delete(ctx, userID, provider)
+}
+
+func (s *identityService) resolveUser(ctx context.Context, provider string, ident *identity.Identity) (*model.UserModel, error) {
+	// user yang sudah terhubung
+	user, err := s.authRepo.FindByIdentity(ctx, provider, ident.Subject)
+	if err == nil {
+		return user, nil
+	}
+	if !apperror.Is(err, apperror.CodeUserNotFound) {
+		return nil, err
+	}
+
+	// tanpa email terverifikasi, akun hanya bisa dihubungkan manual dari akun yang sedang login
+	if !ident.EmailVerified || ident.Email == "" {
+		return nil, apperror.New("[IDENTITY_NOT_LINKED]", "akun "+provider+" belum terhubung, login lalu hubungkan dari pengaturan akun", nil, http.StatusUnauthorized)
+	}
+
+	// hubungkan ke akun dengan email yang sama, hanya jika email akun sudah terverifikasi
+	email := strings.ToLower(ident.Email)
+	user, err = s.authRepo.FindByEmail(ctx, email)
+	if err == nil {
+		if !user.EmailVerified {
+			return nil, apperror.New("[IDENTITY_CONFLICT]", "email sudah terdaftar tetapi belum diverifikasi, verifikasi email terlebih dulu", nil, http.StatusConflict)
+		}
+		if err := s.identityRepo.Create(ctx, &model.UserIdentityModel{
+			UserID:   user.ID,
+			Provider: provider,
+			Subject:  ident.Subject,
+			Email:    &email,
+		}); err != nil {
+			return nil, err
+		}
+
+		user.IdentityCount++
+		return user, nil
+	}
+	if !apperror.Is(err, apperror.CodeUserNotFound) {
+		return nil, err
+	}
+
+	return s.provision(ctx, provider, ident, email)
+}
+
+// provision membuat user baru dari akun provider dengan role default
+func (s *identityService) provision(ctx context.Context, provider string, ident *identity.Identity, email string) (*model.UserModel, error) {
+	// cek email history
+	emailHistoryExists, err := s.emailRepo.IsEmailExists(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	if emailHistoryExists {
+		return nil, apperror.New(apperror.CodeEmailConflict, "email sudah tidak dapat digunakan", nil)
+	}
+
+	// role default
+	roles, err := s.roleRepo.CheckRoles(ctx, []string{s.cfg.Identity.DefaultRole})
+	if err != nil {
+		return nil, err
+	}
+
+	// generate token version
+	tokenVersion, err := s.utility.UUIDGenerate()
+	if err != nil {
+		return nil, apperror.New("[UUID Generate VAILED]", "gagal membuat UUID", err, http.StatusInternalServerError)
+	}
+
+	var fullName *string
+	if ident.Name != "" {
+		fullName = &ident.Name
+	}
+
+	userID := s.utility.ULIDGenerate()
+	user := model.UserModel{
+		ID:             userID,
+		Email:          email,
+		TokenVersion:   tokenVersion,
+		EmailVerified:  true,
+		CreatedByAdmin: false,
+		IdentityCount:  1,
+		Profile: model.ProfileModel{
+			ID:       s.utility.ULIDGenerate(),
+			UserID:   userID,
+			FullName: fullName,
+		},
+		Roles: roles,
+		Identities: []model.UserIdentityModel{
+			{UserID: userID, Provider: provider, Subject: ident.Subject, Email: &email},
+		},
+	}
+	if err := s.userRepo.Create(ctx, &user); err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (s *identityService) provider(name string) (identity.Provider, error) {
+	p, ok := s.providers[name]
+	if !ok {
+		return nil, apperror.New("[IDENTITY_PROVIDER_NOT_FOUND]", "provider login "+name+" tidak tersedia", nil, http.StatusNotFound)
+	}
+
+	return p, nil
+}
+
+func identityEmail(ident *identity.Identity) *string {
+	if ident.Email == "" {
+		return nil
+	}
+
+	email := strings.ToLower(ident.Email)
+	return &email
+}
+
+// loginMethodCount menghitung metode login user: password, passkey dan akun provider eksternal
+func loginMethodCount(user *model.UserModel) int {
+	total := user.PasskeyCount + user.IdentityCount
+	if user.Password != nil {
+		total++
+	}
+
+	return total
+}
+
+func lastLoginMethodError() error {
+	return apperror.New("[LAST_LOGIN_METHOD]", "metode login terakhir tidak dapat dihapus", nil, http.StatusConflict)
+}
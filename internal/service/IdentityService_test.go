@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/gogaruda/apperror"
+	"github.com/irawankilmer/auth-service/internal/configs"
+	"github.com/irawankilmer/auth-service/pkg/identity"
+	"github.com/irawankilmer/auth-service/pkg/utils"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// stubProvider mencatat nilai yang diteruskan ke Exchange, Exchange selalu gagal agar callback berhenti di sana
+type stubProvider struct {
+	name      string
+	exchanges int
+	nonce     string
+	verifier  string
+}
+
+func (p *stubProvider) Name() string {
+	return p.name
+}
+
+func (p *stubProvider) AuthCodeURL(state, nonce, verifier string) string {
+	return "https://provider.example.com/auth?" + url.Values{"state": {state}, "nonce": {nonce}}.Encode()
+}
+
+func (p *stubProvider) Exchange(_ context.Context, _, nonce, verifier string) (*identity.Identity, error) {
+	p.exchanges++
+	p.nonce, p.verifier = nonce, verifier
+	return nil, errors.New("exchange dihentikan test")
+}
+
+func TestIdentityCallbackState(t *testing.T) {
+	newService := func(clock *fakeClock, key string) (*identityService, map[string]*stubProvider) {
+		cfg := &configs.AppConfig{
+			MFA:      configs.MFAConfig{EncryptionKey: key},
+			Identity: configs.IdentityConfig{StateTTL: 10 * time.Minute},
+			Hash:     configs.PasswordHashConfig{Workers: 1},
+		}
+		stubs := map[string]*stubProvider{"google": {name: "google"}, "github": {name: "github"}}
+		providers := map[string]identity.Provider{"google": stubs["google"], "github": stubs["github"]}
+
+		utility, err := utils.NewUtilityWithClock(cfg, newTestBcrypt(t), clock)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return &identityService{providers: providers, utility: utility, cfg: cfg}, stubs
+	}
+
+	tests := []struct {
+		name         string
+		provider     string
+		state        func(state string) string
+		cookie       func(cookie string) string
+		after        time.Duration
+		otherKey     bool
+		wantExchange bool
+	}{
+		{name: "state valid diteruskan ke provider", provider: "google", wantExchange: true},
+		{name: "state tidak cocok", provider: "google", state: func(string) string { return "state-lain" }},
+		{name: "state kosong", provider: "google", state: func(string) string { return "" }},
+		{name: "callback provider lain", provider: "github"},
+		{name: "state kedaluwarsa", provider: "google", after: 10*time.Minute + time.Second},
+		{name: "cookie diubah", provider: "google", cookie: func(c string) string { return c[:len(c)-2] + "AA" }},
+		{name: "cookie kosong", provider: "google", cookie: func(string) string { return "" }},
+		{name: "cookie dari key lain", provider: "google", otherKey: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+			s, stubs := newService(clock, "identity-key")
+
+			authURL, cookie, err := s.AuthURL("google")
+			if err != nil {
+				t.Fatal(err)
+			}
+			u, err := url.Parse(authURL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			state := u.Query().Get("state")
+
+			if tt.state != nil {
+				state = tt.state(state)
+			}
+			if tt.cookie != nil {
+				cookie = tt.cookie(cookie)
+			}
+			if tt.otherKey {
+				s, stubs = newService(clock, "key-lain")
+			}
+			clock.now = clock.now.Add(tt.after)
+
+			_, _, err = s.Callback(context.Background(), tt.provider, cookie, state, "code")
+			if !apperror.Is(err, "[IDENTITY_AUTH_INVALID]") {
+				t.Fatalf("Callback() err = %v, ingin [IDENTITY_AUTH_INVALID]", err)
+			}
+
+			exchanges := stubs["google"].exchanges + stubs["github"].exchanges
+			if tt.wantExchange != (exchanges == 1) {
+				t.Fatalf("provider dipanggil %d kali, ingin dipanggil %v", exchanges, tt.wantExchange)
+			}
+			if tt.wantExchange && (stubs["google"].nonce != u.Query().Get("nonce") || stubs["google"].verifier == "") {
+				t.Errorf("nonce/verifier ke provider = %q/%q, ingin nonce dari URL otorisasi", stubs["google"].nonce, stubs["google"].verifier)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/gogaruda/apperror"
+	"github.com/irawankilmer/auth-service/internal/configs"
+	"github.com/irawankilmer/auth-service/internal/model"
+	"github.com/irawankilmer/auth-service/internal/repository"
+	"net/http"
+	"time"
+)
+
+type LoginAttemptService interface {
+	CheckLock(ctx context.Context, user *model.UserModel, identifier, ipAddress string) error
+	RecordFailure(ctx context.Context, user *model.UserModel, identifier, ipAddress string) error
+	Reset(ctx context.Context, user *model.UserModel, identifier, ipAddress string) error
+	Unlock(ctx context.Context, userID string, identifiers ...string) error
+}
+
+type loginAttemptService struct {
+	authRepo repository.AuthRepository
+	laRepo   repository.LoginAttemptRepository
+	cfg      configs.LockoutConfig
+}
+
+func NewLoginAttemptService(ar repository.AuthRepository, la repository.LoginAttemptRepository, cfg configs.LockoutConfig) LoginAttemptService {
+	return &loginAttemptService{authRepo: ar, laRepo: la, cfg: cfg}
+}
+
+// CheckLock memeriksa kunci per identifier+IP dan, jika user ditemukan, kunci per user
+func (s *loginAttemptService) CheckLock(ctx context.Context, user *model.UserModel, identifier, ipAddress string) error {
+	now := time.Now()
+
+	// cek kunci identifier+IP
+	attempt, err := s.laRepo.Find(ctx, identifier, ipAddress)
+	if err != nil {
+		return err
+	}
+	if attempt.LockedUntil != nil && attempt.LockedUntil.After(now) {
+		return accountLockedError(*attempt.LockedUntil)
+	}
+
+	// cek kunci user
+	if user != nil && user.LockedUntil != nil && user.LockedUntil.After(now) {
+		return accountLockedError(*user.LockedUntil)
+	}
+
+	return nil
+}
+
+// RecordFailure mencatat login gagal dan mengunci jika batas percobaan terlampaui.
+// Mengembalikan [ACCOUNT_LOCKED] jika percobaan ini yang memicu kunci.
+func (s *loginAttemptService) RecordFailure(ctx context.Context, user *model.UserModel, identifier, ipAddress string) error {
+	now := time.Now()
+
+	// catat kegagalan identifier+IP
+	attempt, err := s.laRepo.IncrementFailure(ctx, identifier, ipAddress, s.cfg.AttemptWindow)
+	if err != nil {
+		return err
+	}
+
+	var lockedUntil *time.Time
+	if s.cfg.IPMaxAttempts > 0 && attempt.FailedAttempts >= s.cfg.IPMaxAttempts {
+		until := now.Add(s.lockoutDuration(attempt.LockoutCount + 1))
+		if err := s.laRepo.Lock(ctx, identifier, ipAddress, attempt.LockoutCount+1, until); err != nil {
+			return err
+		}
+		lockedUntil = &until
+	}
+
+	// catat kegagalan user
+	if user != nil {
+		state, err := s.authRepo.IncrementLoginFailure(ctx, user.ID, s.cfg.AttemptWindow)
+		if err != nil {
+			return err
+		}
+
+		if s.cfg.MaxAttempts > 0 && state.FailedLoginAttempts >= s.cfg.MaxAttempts {
+			until := now.Add(s.lockoutDuration(state.LockoutCount + 1))
+			if err := s.authRepo.LockAccount(ctx, user.ID, state.LockoutCount+1, until); err != nil {
+				return err
+			}
+			if lockedUntil == nil || until.After(*lockedUntil) {
+				lockedUntil = &until
+			}
+		}
+	}
+
+	if lockedUntil != nil {
+		return accountLockedError(*lockedUntil)
+	}
+
+	return nil
+}
+
+func (s *loginAttemptService) Reset(ctx context.Context, user *model.UserModel, identifier, ipAddress string) error {
+	if err := s.laRepo.Delete(ctx, identifier, ipAddress); err != nil {
+		return err
+	}
+
+	// hanya update jika memang ada riwayat gagal
+	if user.FailedLoginAttempts == 0 && user.LockoutCount == 0 && user.LockedUntil == nil {
+		return nil
+	}
+
+	return s.authRepo.ResetLoginFailure(ctx, user.ID)
+}
+
+// Unlock membuka kunci user, kunci identifier+IP untuk username/email dan semua kunci per jenis login user
+func (s *loginAttemptService) Unlock(ctx context.Context, userID string, identifiers ...string) error {
+	if err := s.authRepo.ResetLoginFailure(ctx, userID); err != nil {
+		return err
+	}
+
+	if err := s.laRepo.DeleteByUserID(ctx, userID); err != nil {
+		return err
+	}
+
+	return s.laRepo.DeleteByIdentifiers(ctx, identifiers...)
+}
+
+// lockoutDuration menghitung lama kunci secara eksponensial: base * 2^(n-1), dibatasi MaxDuration
+func (s *loginAttemptService) lockoutDuration(lockoutCount int) time.Duration {
+	duration := s.cfg.BaseDuration
+	for i := 1; i < lockoutCount; i++ {
+		duration *= 2
+		if s.cfg.MaxDuration > 0 && duration >= s.cfg.MaxDuration {
+			return s.cfg.MaxDuration
+		}
+	}
+
+	if s.cfg.MaxDuration > 0 && duration > s.cfg.MaxDuration {
+		return s.cfg.MaxDuration
+	}
+
+	return duration
+}
+
+func accountLockedError(lockedUntil time.Time) error {
+	retryAfter := int(time.Until(lockedUntil).Seconds()) + 1
+	return apperror.New(
+		"[ACCOUNT_LOCKED]",
+		fmt.Sprintf("akun terkunci sementara karena terlalu banyak percobaan login gagal, coba lagi dalam %d detik (%s)",
+			retryAfter, lockedUntil.Format(time.RFC3339)),
+		nil, http.StatusLocked,
+	).WithResponseStatus("locked")
+}
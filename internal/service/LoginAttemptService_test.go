@@ -0,0 +1,257 @@
+package service
+
+import (
+	"context"
+	"github.com/gogaruda/apperror"
+	"github.com/irawankilmer/auth-service/internal/configs"
+	"github.com/irawankilmer/auth-service/internal/model"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeLoginAttemptRepo menyimpan login_attempts di memori dengan key identifier+IP
+type fakeLoginAttemptRepo struct {
+	attempts map[string]*model.LoginAttemptModel
+}
+
+func newFakeLoginAttemptRepo() *fakeLoginAttemptRepo {
+	return &fakeLoginAttemptRepo{attempts: map[string]*model.LoginAttemptModel{}}
+}
+
+func (r *fakeLoginAttemptRepo) Find(_ context.Context, identifier, ipAddress string) (*model.LoginAttemptModel, error) {
+	attempt, ok := r.attempts[identifier+"|"+ipAddress]
+	if !ok {
+		return &model.LoginAttemptModel{Identifier: identifier, IPAddress: ipAddress}, nil
+	}
+
+	copied := *attempt
+	return &copied, nil
+}
+
+func (r *fakeLoginAttemptRepo) IncrementFailure(_ context.Context, identifier, ipAddress string, _ time.Duration) (*model.LoginAttemptModel, error) {
+	key := identifier + "|" + ipAddress
+	attempt, ok := r.attempts[key]
+	if !ok {
+		attempt = &model.LoginAttemptModel{Identifier: identifier, IPAddress: ipAddress}
+		r.attempts[key] = attempt
+	}
+	attempt.FailedAttempts++
+
+	copied := *attempt
+	return &copied, nil
+}
+
+func (r *fakeLoginAttemptRepo) Lock(_ context.Context, identifier, ipAddress string, lockoutCount int, lockedUntil time.Time) error {
+	attempt := r.attempts[identifier+"|"+ipAddress]
+	attempt.FailedAttempts, attempt.LockoutCount, attempt.LockedUntil = 0, lockoutCount, &lockedUntil
+	return nil
+}
+
+func (r *fakeLoginAttemptRepo) Delete(_ context.Context, identifier, ipAddress string) error {
+	delete(r.attempts, identifier+"|"+ipAddress)
+	return nil
+}
+
+func (r *fakeLoginAttemptRepo) DeleteByIdentifiers(_ context.Context, identifiers ...string) error {
+	for key, attempt := range r.attempts {
+		for _, identifier := range identifiers {
+			if attempt.Identifier == identifier {
+				delete(r.attempts, key)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r *fakeLoginAttemptRepo) DeleteByUserID(_ context.Context, userID string) error {
+	for key, attempt := range r.attempts {
+		if strings.HasSuffix(attempt.Identifier, ":"+userID) {
+			delete(r.attempts, key)
+		}
+	}
+
+	return nil
+}
+
+var testLockoutConfig = configs.LockoutConfig{
+	MaxAttempts:   3,
+	IPMaxAttempts: 5,
+	BaseDuration:  time.Minute,
+	MaxDuration:   10 * time.Minute,
+	AttemptWindow: 15 * time.Minute,
+}
+
+func newTestLoginAttemptService(cfg configs.LockoutConfig, users ...*model.UserModel) (*loginAttemptService, *fakeAuthRepo, *fakeLoginAttemptRepo) {
+	authRepo := &fakeAuthRepo{users: map[string]*model.UserModel{}}
+	for _, user := range users {
+		authRepo.users[user.ID] = user
+	}
+	laRepo := newFakeLoginAttemptRepo()
+
+	return &loginAttemptService{authRepo: authRepo, laRepo: laRepo, cfg: cfg}, authRepo, laRepo
+}
+
+func TestLockoutDuration(t *testing.T) {
+	tests := []struct {
+		name         string
+		base, max    time.Duration
+		lockoutCount int
+		want         time.Duration
+	}{
+		{name: "kunci pertama", base: time.Minute, max: 10 * time.Minute, lockoutCount: 1, want: time.Minute},
+		{name: "kunci kedua dua kali lipat", base: time.Minute, max: 10 * time.Minute, lockoutCount: 2, want: 2 * time.Minute},
+		{name: "kunci keempat", base: time.Minute, max: 10 * time.Minute, lockoutCount: 4, want: 8 * time.Minute},
+		{name: "dibatasi max", base: time.Minute, max: 10 * time.Minute, lockoutCount: 5, want: 10 * time.Minute},
+		{name: "jauh melewati max", base: time.Minute, max: 10 * time.Minute, lockoutCount: 40, want: 10 * time.Minute},
+		{name: "base lebih besar dari max", base: 20 * time.Minute, max: 10 * time.Minute, lockoutCount: 1, want: 10 * time.Minute},
+		{name: "max 0 kunci pertama", base: time.Minute, lockoutCount: 1, want: time.Minute},
+		{name: "max 0 kunci kedua tidak dibatasi", base: time.Minute, lockoutCount: 2, want: 2 * time.Minute},
+		{name: "max 0 kunci kelima", base: time.Minute, lockoutCount: 5, want: 16 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &loginAttemptService{cfg: configs.LockoutConfig{BaseDuration: tt.base, MaxDuration: tt.max}}
+			if got := s.lockoutDuration(tt.lockoutCount); got != tt.want {
+				t.Errorf("lockoutDuration(%d) = %v, ingin %v", tt.lockoutCount, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckLock(t *testing.T) {
+	past, future := time.Now().Add(-time.Minute), time.Now().Add(time.Minute)
+
+	tests := []struct {
+		name       string
+		attempt    *model.LoginAttemptModel
+		user       *model.UserModel
+		wantLocked bool
+	}{
+		{name: "belum pernah gagal", user: &model.UserModel{ID: "u1"}},
+		{name: "tanpa user", user: nil},
+		{
+			name:       "identifier+IP terkunci",
+			attempt:    &model.LoginAttemptModel{Identifier: "alice", IPAddress: "10.0.0.1", LockedUntil: &future},
+			wantLocked: true,
+		},
+		{
+			name:    "kunci identifier+IP sudah lewat",
+			attempt: &model.LoginAttemptModel{Identifier: "alice", IPAddress: "10.0.0.1", LockedUntil: &past},
+			user:    &model.UserModel{ID: "u1"},
+		},
+		{
+			name:    "identifier sama dari IP lain",
+			attempt: &model.LoginAttemptModel{Identifier: "alice", IPAddress: "10.0.0.2", LockedUntil: &future},
+			user:    &model.UserModel{ID: "u1"},
+		},
+		{name: "user terkunci", user: &model.UserModel{ID: "u1", LockedUntil: &future}, wantLocked: true},
+		{name: "kunci user sudah lewat", user: &model.UserModel{ID: "u1", LockedUntil: &past}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, laRepo := newTestLoginAttemptService(testLockoutConfig)
+			if tt.attempt != nil {
+				laRepo.attempts[tt.attempt.Identifier+"|"+tt.attempt.IPAddress] = tt.attempt
+			}
+
+			err := s.CheckLock(context.Background(), tt.user, "alice", "10.0.0.1")
+			if locked := apperror.Is(err, "[ACCOUNT_LOCKED]"); locked != tt.wantLocked {
+				t.Errorf("CheckLock() err = %v, ingin terkunci %v", err, tt.wantLocked)
+			}
+		})
+	}
+}
+
+func TestRecordFailureLocks(t *testing.T) {
+	tests := []struct {
+		name         string
+		maxDuration  time.Duration
+		wantDuration []time.Duration
+	}{
+		{name: "dengan batas", maxDuration: 3 * time.Minute, wantDuration: []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute}},
+		{name: "tanpa batas", wantDuration: []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// hanya kunci user, kunci identifier+IP punya batas percobaan sendiri
+			cfg := testLockoutConfig
+			cfg.IPMaxAttempts, cfg.MaxDuration = 0, tt.maxDuration
+			s, authRepo, _ := newTestLoginAttemptService(cfg, &model.UserModel{ID: "u1"})
+			ctx := context.Background()
+
+			for round, want := range tt.wantDuration {
+				// percobaan sebelum batas tidak mengunci
+				for i := 1; i < cfg.MaxAttempts; i++ {
+					user, _ := authRepo.FindByID(ctx, "u1")
+					if err := s.RecordFailure(ctx, user, "password:u1", "10.0.0.1"); err != nil {
+						t.Fatalf("kunci ke-%d, percobaan %d: err = %v", round+1, i, err)
+					}
+				}
+
+				user, _ := authRepo.FindByID(ctx, "u1")
+				start := time.Now()
+				if err := s.RecordFailure(ctx, user, "password:u1", "10.0.0.1"); !apperror.Is(err, "[ACCOUNT_LOCKED]") {
+					t.Fatalf("kunci ke-%d: err = %v, ingin [ACCOUNT_LOCKED]", round+1, err)
+				}
+
+				user, _ = authRepo.FindByID(ctx, "u1")
+				if err := s.CheckLock(ctx, user, "password:u1", "10.0.0.1"); !apperror.Is(err, "[ACCOUNT_LOCKED]") {
+					t.Fatalf("kunci ke-%d: CheckLock err = %v, ingin [ACCOUNT_LOCKED]", round+1, err)
+				}
+				if got := user.LockedUntil.Sub(start); got < want || got > want+time.Second {
+					t.Errorf("kunci ke-%d: lama kunci = %v, ingin %v", round+1, got, want)
+				}
+
+				// kunci berakhir, percobaan berikutnya memakai lockout count yang naik
+				expired := time.Now().Add(-time.Second)
+				authRepo.users["u1"].LockedUntil = &expired
+			}
+		})
+	}
+}
+
+func TestUnlock(t *testing.T) {
+	future := time.Now().Add(time.Hour)
+	s, authRepo, laRepo := newTestLoginAttemptService(testLockoutConfig,
+		&model.UserModel{ID: "u1", FailedLoginAttempts: 2, LockoutCount: 3, LockedUntil: &future})
+
+	identifiers := []string{"alice", "alice@example.com", "password:u1", "mfa:u1", "passkey:u1", "google:u1", "password:u2", "bob"}
+	for _, identifier := range identifiers {
+		laRepo.attempts[identifier+"|10.0.0.1"] = &model.LoginAttemptModel{Identifier: identifier, IPAddress: "10.0.0.1", LockedUntil: &future}
+	}
+
+	if err := s.Unlock(context.Background(), "u1", "alice", "alice@example.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		identifier string
+		wantLocked bool
+	}{
+		{identifier: "alice"},
+		{identifier: "alice@example.com"},
+		{identifier: "password:u1"},
+		{identifier: "mfa:u1"},
+		{identifier: "passkey:u1"},
+		{identifier: "google:u1"},
+		{identifier: "password:u2", wantLocked: true},
+		{identifier: "bob", wantLocked: true},
+	}
+
+	for _, tt := range tests {
+		err := s.CheckLock(context.Background(), nil, tt.identifier, "10.0.0.1")
+		if locked := apperror.Is(err, "[ACCOUNT_LOCKED]"); locked != tt.wantLocked {
+			t.Errorf("%s: terkunci = %v, ingin %v", tt.identifier, locked, tt.wantLocked)
+		}
+	}
+
+	user := authRepo.users["u1"]
+	if user.FailedLoginAttempts != 0 || user.LockoutCount != 0 || user.LockedUntil != nil {
+		t.Errorf("user setelah unlock = %+v, ingin percobaan gagal dan kunci direset", user)
+	}
+}
@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/irawankilmer/auth-service/internal/configs"
+	"github.com/irawankilmer/auth-service/internal/dto/request"
+	"github.com/irawankilmer/auth-service/internal/dto/response"
+	"github.com/irawankilmer/auth-service/internal/model"
+	"github.com/irawankilmer/auth-service/internal/repository"
+	"github.com/irawankilmer/auth-service/pkg/device"
+	"github.com/irawankilmer/auth-service/pkg/mailer"
+	"github.com/irawankilmer/auth-service/pkg/utils"
+	"html"
+	"log"
+	"net"
+	"net/url"
+	"time"
+)
+
+type LoginNotificationService interface {
+	NotifyLogin(ctx context.Context, user *model.UserModel, session *model.UserSession)
+	Preferences(ctx context.Context, userID string) (*response.NotificationPreferencesResponse, error)
+	UpdatePreferences(ctx context.Context, userID string, req request.NotificationPreferencesRequest) (*response.NotificationPreferencesResponse, error)
+}
+
+type loginNotificationService struct {
+	usRepo   repository.UserSessionRepository
+	prefRepo repository.NotificationPreferenceRepository
+	mail     *mailer.Mailer
+	utility  utils.Utility
+	cfg      *configs.AppConfig
+}
+
+func NewLoginNotificationService(usR repository.UserSessionRepository, pr repository.NotificationPreferenceRepository,
+	mail *mailer.Mailer, ut utils.Utility, cfg *configs.AppConfig,
+) LoginNotificationService {
+	return &loginNotificationService{usRepo: usR, prefRepo: pr, mail: mail, utility: ut, cfg: cfg}
+}
+
+// NotifyLogin membandingkan session baru dengan riwayat session user, lalu mengirim email jika perangkat
+// atau jaringannya belum pernah dipakai. Berjalan di background agar login tidak menunggu pengiriman email
+func (s *loginNotificationService) NotifyLogin(ctx context.Context, user *model.UserModel, session *model.UserSession) {
+	if !s.cfg.LoginNotify.Enabled {
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+
+	go func() {
+		// ambil riwayat session, login pertama tidak punya pembanding
+		since := s.utility.Now().Add(-s.cfg.LoginNotify.HistoryWindow)
+		history, err := s.usRepo.FindRecentByUserID(ctx, user.ID, session.ID, since, s.cfg.LoginNotify.HistoryLimit)
+		if err != nil {
+			log.Printf("[WARN] cek riwayat session user %s gagal: %v", user.ID, err)
+			return
+		}
+		if len(history) == 0 {
+			return
+		}
+
+		// cek preferensi lalu perangkat dan jaringan
+		prefs, err := s.preferences(ctx, user.ID)
+		if err != nil {
+			log.Printf("[WARN] cek preferensi notifikasi user %s gagal: %v", user.ID, err)
+			return
+		}
+		reason := newLoginReason(history, session, prefs)
+		if reason == "" {
+			return
+		}
+
+		if err := s.sendNewLogin(user, session, reason); err != nil {
+			log.Printf("[WARN] notifikasi login baru gagal dikirim ke user %s: %v", user.ID, err)
+		}
+	}()
+}
+
+func (s *loginNotificationService) sendNewLogin(user *model.UserModel, session *model.UserSession, reason string) error {
+	token, err := s.utility.SessionRevokeGenerate(user.ID, session.ID)
+	if err != nil {
+		return err
+	}
+
+	revokeURL := fmt.Sprintf("%s/revoke-session?token=%s", s.cfg.Mail.FrontVerifyUrl, url.QueryEscape(token))
+	body := fmt.Sprintf(`
+	<h2>Login Baru di Akun Anda</h2>
+	<p>Halo,</p>
+	<p>Akun Anda baru saja login dari %s.</p>
+	<ul>
+		<li>Waktu: %s</li>
+		<li>Alamat IP: %s</li>
+		<li>Perangkat: %s</li>
+	</ul>
+	<p>Jika ini bukan Anda, akhiri session tersebut lalu segera ganti password:</p>
+	<p><a href='%s' style='
+		display: inline-block;
+		padding: 10px 20px;
+		background-color: #d9534f;
+		color: white;
+		text-decoration: none;
+		border-radius: 5px;
+		font-weight: bold;
+	'>Bukan Saya, Akhiri Session</a></p>
+	<p>Link ini berlaku %d hari. Notifikasi ini bisa dimatikan di pengaturan notifikasi akun.</p>
+	<p>Salam hangat,<br><strong>Tim Support %s</strong></p>
+`, reason, s.utility.Now().Format(time.RFC1123), html.EscapeString(session.IPAddress), html.EscapeString(device.ParseUserAgent(session.UserAgent).String()),
+		revokeURL, int(s.cfg.LoginNotify.RevokeLinkTTL.Hours()/24), "Sekolah Kita")
+
+	return s.mail.Send(user.Email, "Login Baru di Akun Anda", body)
+}
+
+func (s *loginNotificationService) Preferences(ctx context.Context, userID string) (*response.NotificationPreferencesResponse, error) {
+	return s.preferences(ctx, userID)
+}
+
+func (s *loginNotificationService) UpdatePreferences(ctx context.Context, userID string, req request.NotificationPreferencesRequest) (*response.NotificationPreferencesResponse, error) {
+	changes := map[string]bool{}
+	if req.NewDevice != nil {
+		changes[model.NotifyNewDevice] = *req.NewDevice
+	}
+	if req.NewNetwork != nil {
+		changes[model.NotifyNewNetwork] = *req.NewNetwork
+	}
+
+	if len(changes) > 0 {
+		if err := s.prefRepo.Upsert(ctx, userID, changes); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.preferences(ctx, userID)
+}
+
+// preferences jenis notifikasi yang belum pernah diatur dianggap aktif
+func (s *loginNotificationService) preferences(ctx context.Context, userID string) (*response.NotificationPreferencesResponse, error) {
+	prefs, err := s.prefRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	enabled := func(notificationType string) bool {
+		value, ok := prefs[notificationType]
+		return !ok || value
+	}
+
+	return &response.NotificationPreferencesResponse{
+		NewDevice:  enabled(model.NotifyNewDevice),
+		NewNetwork: enabled(model.NotifyNewNetwork),
+	}, nil
+}
+
+// newLoginReason alasan notifikasi jika perangkat atau jaringan session belum ada di riwayat, kosong jika tidak perlu
+// notifikasi. Satu login cukup satu email, perangkat baru didahulukan
+func newLoginReason(history []model.UserSession, session *model.UserSession, prefs *response.NotificationPreferencesResponse) string {
+	if len(history) == 0 {
+		return ""
+	}
+
+	newDevice, newNetwork := true, true
+	for _, h := range history {
+		if sameDevice(h, session) {
+			newDevice = false
+		}
+		if sameNetwork(h.IPAddress, session.IPAddress) {
+			newNetwork = false
+		}
+	}
+
+	switch {
+	case newDevice && prefs.NewDevice:
+		return "perangkat baru"
+	case newNetwork && prefs.NewNetwork:
+		return "jaringan baru"
+	default:
+		return ""
+	}
+}
+
+// sameDevice memakai device ID, session lama tanpa device ID dibandingkan dari user agent
+func sameDevice(a model.UserSession, b *model.UserSession) bool {
+	if device.IsID(a.DeviceID) && device.IsID(b.DeviceID) {
+		return a.DeviceID == b.DeviceID
+	}
+
+	return a.UserAgent == b.UserAgent
+}
+
+// sameNetwork membandingkan prefix jaringan (/24 untuk IPv4, /64 untuk IPv6) agar IP dinamis dari ISP yang sama
+// tidak dianggap jaringan baru
+func sameNetwork(a, b string) bool {
+	ipA, ipB := net.ParseIP(a), net.ParseIP(b)
+	if ipA == nil || ipB == nil {
+		return a == b
+	}
+
+	if v4A, v4B := ipA.To4(), ipB.To4(); v4A != nil || v4B != nil {
+		if v4A == nil || v4B == nil {
+			return false
+		}
+		mask := net.CIDRMask(24, 32)
+		return v4A.Mask(mask).Equal(v4B.Mask(mask))
+	}
+
+	mask := net.CIDRMask(64, 128)
+	return ipA.Mask(mask).Equal(ipB.Mask(mask))
+}
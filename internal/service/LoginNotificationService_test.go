@@ -0,0 +1,133 @@
+package service
+
+import (
+	"github.com/irawankilmer/auth-service/internal/dto/response"
+	"github.com/irawankilmer/auth-service/internal/model"
+	"testing"
+)
+
+const (
+	testDeviceA = "0123456789abcdef0123456789abcdef"
+	testDeviceB = "fedcba9876543210fedcba9876543210"
+	testChrome  = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) Chrome/120.0"
+	testFirefox = "Mozilla/5.0 (X11; Linux x86_64; rv:121.0) Firefox/121.0"
+)
+
+func TestNewLoginReason(t *testing.T) {
+	allOn := &response.NotificationPreferencesResponse{NewDevice: true, NewNetwork: true}
+	history := []model.UserSession{
+		{DeviceID: testDeviceA, UserAgent: testChrome, IPAddress: "203.0.113.10"},
+		// session lama sebelum ada device ID
+		{UserAgent: testFirefox, IPAddress: "2001:db8:1:2::10"},
+	}
+
+	tests := []struct {
+		name    string
+		history []model.UserSession
+		session model.UserSession
+		prefs   *response.NotificationPreferencesResponse
+		want    string
+	}{
+		{
+			name:    "login pertama tanpa riwayat",
+			session: model.UserSession{DeviceID: testDeviceB, UserAgent: testChrome, IPAddress: "198.51.100.1"},
+			prefs:   allOn,
+		},
+		{
+			name:    "perangkat dan jaringan dikenal",
+			history: history,
+			session: model.UserSession{DeviceID: testDeviceA, UserAgent: testChrome, IPAddress: "203.0.113.10"},
+			prefs:   allOn,
+		},
+		{
+			name:    "IP dinamis di /24 yang sama",
+			history: history,
+			session: model.UserSession{DeviceID: testDeviceA, UserAgent: testChrome, IPAddress: "203.0.113.200"},
+			prefs:   allOn,
+		},
+		{
+			name:    "IPv6 di /64 yang sama",
+			history: history,
+			session: model.UserSession{UserAgent: testFirefox, IPAddress: "2001:db8:1:2:ffff::1"},
+			prefs:   allOn,
+		},
+		{
+			name:    "device ID baru dengan user agent sama",
+			history: history,
+			session: model.UserSession{DeviceID: testDeviceB, UserAgent: testChrome, IPAddress: "203.0.113.10"},
+			prefs:   allOn,
+			want:    "perangkat baru",
+		},
+		{
+			name:    "device ID sama setelah browser update",
+			history: history,
+			session: model.UserSession{DeviceID: testDeviceA, UserAgent: testChrome + " Edg/120.0", IPAddress: "203.0.113.10"},
+			prefs:   allOn,
+		},
+		{
+			name:    "tanpa device ID dibandingkan dari user agent",
+			history: history,
+			session: model.UserSession{UserAgent: "curl/8.0", IPAddress: "203.0.113.10"},
+			prefs:   allOn,
+			want:    "perangkat baru",
+		},
+		{
+			name:    "jaringan baru",
+			history: history,
+			session: model.UserSession{DeviceID: testDeviceA, UserAgent: testChrome, IPAddress: "198.51.100.1"},
+			prefs:   allOn,
+			want:    "jaringan baru",
+		},
+		{
+			name:    "perangkat dan jaringan baru cukup satu alasan",
+			history: history,
+			session: model.UserSession{DeviceID: testDeviceB, UserAgent: testChrome, IPAddress: "198.51.100.1"},
+			prefs:   allOn,
+			want:    "perangkat baru",
+		},
+		{
+			name:    "notifikasi perangkat baru dimatikan",
+			history: history,
+			session: model.UserSession{DeviceID: testDeviceB, UserAgent: testChrome, IPAddress: "198.51.100.1"},
+			prefs:   &response.NotificationPreferencesResponse{NewNetwork: true},
+			want:    "jaringan baru",
+		},
+		{
+			name:    "semua notifikasi dimatikan",
+			history: history,
+			session: model.UserSession{DeviceID: testDeviceB, UserAgent: testChrome, IPAddress: "198.51.100.1"},
+			prefs:   &response.NotificationPreferencesResponse{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newLoginReason(tt.history, &tt.session, tt.prefs); got != tt.want {
+				t.Errorf("alasan = %q, ingin %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSameNetwork(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{a: "203.0.113.10", b: "203.0.113.10", want: true},
+		{a: "203.0.113.10", b: "203.0.113.254", want: true},
+		{a: "203.0.113.10", b: "203.0.114.10"},
+		{a: "203.0.113.10", b: "::ffff:203.0.113.20", want: true},
+		{a: "203.0.113.10", b: "2001:db8::1"},
+		{a: "2001:db8:1:2::1", b: "2001:db8:1:2:abcd::1", want: true},
+		{a: "2001:db8:1:2::1", b: "2001:db8:1:3::1"},
+		{a: "bukan-ip", b: "bukan-ip", want: true},
+		{a: "bukan-ip", b: "203.0.113.10"},
+	}
+
+	for _, tt := range tests {
+		if got := sameNetwork(tt.a, tt.b); got != tt.want {
+			t.Errorf("sameNetwork(%q, %q) = %v, ingin %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,316 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gogaruda/apperror"
+	"github.com/irawankilmer/auth-service/internal/configs"
+	"github.com/irawankilmer/auth-service/internal/dto/response"
+	"github.com/irawankilmer/auth-service/internal/model"
+	"github.com/irawankilmer/auth-service/internal/repository"
+	"github.com/irawankilmer/auth-service/pkg/mailer"
+	"github.com/irawankilmer/auth-service/pkg/utils"
+	"html"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type MFAService interface {
+	Enroll(ctx context.Context, userID string) (*response.MFAEnrollResponse, error)
+	Confirm(ctx context.Context, userID, code, ipAddress string) (*response.MFARecoveryCodesResponse, error)
+	Disable(ctx context.Context, userID, code, password, ipAddress string) error
+	VerifyCode(ctx context.Context, userID, code string) error
+	RegenerateRecoveryCodes(ctx context.Context, userID, code, password, ipAddress string) (*response.MFARecoveryCodesResponse, error)
+	UseRecoveryCode(ctx context.Context, user *model.UserModel, code, ipAddress, userAgent string) error
+}
+
+// jumlah recovery code yang dibuat setiap kali MFA didaftarkan atau kode dibuat ulang
+const recoveryCodeCount = 10
+
+type mfaService struct {
+	mfaRepo   repository.MFARepository
+	rcRepo    repository.MFARecoveryCodeRepository
+	authRepo  repository.AuthRepository
+	laService LoginAttemptService
+	utility   utils.Utility
+	cfg       *configs.AppConfig
+	mail      *mailer.Mailer
+	mailQueue *mailer.Queue
+}
+
+func NewMFAService(
+	mr repository.MFARepository, rc repository.MFARecoveryCodeRepository, ar repository.AuthRepository,
+	la LoginAttemptService, ut utils.Utility, cfg *configs.AppConfig, m *mailer.Mailer, mq *mailer.Queue,
+) MFAService {
+	return &mfaService{mfaRepo: mr, rcRepo: rc, authRepo: ar, laService: la, utility: ut, cfg: cfg, mail: m, mailQueue: mq}
+}
+
+func (s *mfaService) Enroll(ctx context.Context, userID string) (*response.MFAEnrollResponse, error) {
+	// cek user
+	user, err := s.authRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// MFA yang sudah aktif harus dinonaktifkan dulu
+	if user.MFAEnabled {
+		return nil, apperror.New("[MFA_ALREADY_ENABLED]", "MFA sudah aktif", nil, http.StatusConflict)
+	}
+
+	// generate secret
+	secret, err := s.utility.TOTPSecretGenerate()
+	if err != nil {
+		return nil, err
+	}
+
+	// simpan secret terenkripsi
+	encrypted, err := s.utility.Encrypt(secret)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfaRepo.SavePending(ctx, user.ID, encrypted); err != nil {
+		return nil, err
+	}
+
+	// otpauth URL untuk QR code aplikasi authenticator
+	label := url.PathEscape(fmt.Sprintf("%s:%s", s.cfg.MFA.Issuer, user.Email))
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", s.cfg.MFA.Issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", "6")
+	query.Set("period", "30")
+
+	return &response.MFAEnrollResponse{
+		Secret:     secret,
+		OtpauthURL: fmt.Sprintf("otpauth://totp/%s?%s", label, query.Encode()),
+	}, nil
+}
+
+func (s *mfaService) Confirm(ctx context.Context, userID, code, ipAddress string) (*response.MFARecoveryCodesResponse, error) {
+	// cek pendaftaran MFA
+	mfa, err := s.mfaRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if mfa.Enabled {
+		return nil, apperror.New("[MFA_ALREADY_ENABLED]", "MFA sudah aktif", nil, http.StatusConflict)
+	}
+	user, err := s.authRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// cek kode, kode salah dihitung sebagai login gagal
+	var step int64
+	err = s.checkCode(ctx, user, ipAddress, func() error {
+		secret, err := s.utility.Decrypt(mfa.SecretEncrypted)
+		if err != nil {
+			return err
+		}
+		var ok bool
+		step, ok = s.utility.TOTPValidate(secret, code, s.utility.Now())
+		if !ok {
+			return apperror.New("[MFA_CODE_INVALID]", "kode MFA salah", errors.New("kode MFA salah"), http.StatusUnauthorized)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// aktifkan MFA
+	if err := s.mfaRepo.Enable(ctx, userID, step); err != nil {
+		return nil, err
+	}
+
+	// buat recovery code, hanya ditampilkan sekali ini
+	return s.generateRecoveryCodes(ctx, userID)
+}
+
+func (s *mfaService) Disable(ctx context.Context, userID, code, password, ipAddress string) error {
+	// cek kode MFA dan password
+	if err := s.verifyCodeAndPassword(ctx, userID, code, password, ipAddress); err != nil {
+		return err
+	}
+
+	// hapus recovery code
+	if err := s.rcRepo.DeleteByUserID(ctx, userID); err != nil {
+		return err
+	}
+
+	return s.mfaRepo.Delete(ctx, userID)
+}
+
+func (s *mfaService) VerifyCode(ctx context.Context, userID, code string) error {
+	// cek MFA aktif
+	mfa, err := s.mfaRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !mfa.Enabled {
+		return apperror.New("[MFA_NOT_ENABLED]", "MFA belum aktif", nil, http.StatusBadRequest)
+	}
+
+	// cek kode
+	secret, err := s.utility.Decrypt(mfa.SecretEncrypted)
+	if err != nil {
+		return err
+	}
+	step, ok := s.utility.TOTPValidate(secret, code, s.utility.Now())
+	if !ok {
+		return apperror.New("[MFA_CODE_INVALID]", "kode MFA salah", errors.New("kode MFA salah"), http.StatusUnauthorized)
+	}
+
+	// kode yang sama tidak boleh dipakai dua kali
+	used, err := s.mfaRepo.UseStep(ctx, userID, step)
+	if err != nil {
+		return err
+	}
+	if !used {
+		return apperror.New("[MFA_CODE_INVALID]", "kode MFA sudah digunakan", errors.New("replay kode MFA"), http.StatusUnauthorized)
+	}
+
+	return nil
+}
+
+func (s *mfaService) RegenerateRecoveryCodes(ctx context.Context, userID, code, password, ipAddress string) (*response.MFARecoveryCodesResponse, error) {
+	// cek kode MFA dan password
+	if err := s.verifyCodeAndPassword(ctx, userID, code, password, ipAddress); err != nil {
+		return nil, err
+	}
+
+	// kode lama otomatis tidak berlaku
+	return s.generateRecoveryCodes(ctx, userID)
+}
+
+func (s *mfaService) UseRecoveryCode(ctx context.Context, user *model.UserModel, code, ipAddress, userAgent string) error {
+	invalid := apperror.New("[MFA_CODE_INVALID]", "recovery code salah atau sudah digunakan", errors.New("recovery code salah"), http.StatusUnauthorized)
+	if !user.MFAEnabled {
+		return invalid
+	}
+
+	// tandai kode sudah digunakan
+	used, err := s.rcRepo.Use(ctx, user.ID, s.utility.HashToken(normalizeRecoveryCode(code)), ipAddress, userAgent)
+	if err != nil {
+		return err
+	}
+	if !used {
+		return invalid
+	}
+
+	// hitung sisa kode
+	remaining, err := s.rcRepo.CountUnused(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	// kirim notifikasi lewat antrean, SMTP yang lambat atau gagal tidak menahan login
+	body := fmt.Sprintf(`
+	<h2>Recovery Code Digunakan</h2>
+	<p>Halo,</p>
+	<p>Salah satu recovery code MFA akun Anda baru saja digunakan untuk login.</p>
+	<ul>
+		<li>Waktu: %s</li>
+		<li>Alamat IP: %s</li>
+		<li>Perangkat: %s</li>
+		<li>Sisa recovery code: %d</li>
+	</ul>
+	<p>Jika ini bukan Anda, segera ganti password dan hubungi admin.</p>
+	<p>Salam hangat,<br><strong>Tim Support %s</strong></p>
+`, s.utility.Now().Format(time.RFC1123), html.EscapeString(ipAddress), html.EscapeString(userAgent), remaining, "Sekolah Kita")
+
+	s.mailQueue.Enqueue("notifikasi recovery code", func() {
+		if err := s.mail.Send(user.Email, "Recovery Code MFA Digunakan", body); err != nil {
+			log.Printf("[WARN] notifikasi recovery code gagal dikirim ke user %s: %v", user.ID, err)
+		}
+	})
+
+	return nil
+}
+
+// verifyCodeAndPassword kode dicek lebih dulu karena password yang benar mereset percobaan gagal user,
+// kode salah tidak boleh terhapus hitungannya oleh password yang benar
+func (s *mfaService) verifyCodeAndPassword(ctx context.Context, userID, code, password, ipAddress string) error {
+	user, err := s.authRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	// cek kode MFA, kode salah dihitung sebagai login gagal
+	if err := s.checkCode(ctx, user, ipAddress, func() error { return s.VerifyCode(ctx, userID, code) }); err != nil {
+		return err
+	}
+
+	// cek password
+	return verifyPassword(ctx, s.laService, s.utility, user, password, ipAddress)
+}
+
+// checkCode menjalankan verify dengan kunci akun identifier mfa:<user_id> yang sama dengan login MFA,
+// kode salah dihitung sebagai login gagal
+func (s *mfaService) checkCode(ctx context.Context, user *model.UserModel, ipAddress string, verify func() error) error {
+	mfaIdentifier := "mfa:" + user.ID
+	if err := s.laService.CheckLock(ctx, user, mfaIdentifier, ipAddress); err != nil {
+		return err
+	}
+
+	if err := verify(); err != nil {
+		if apperror.Is(err, "[MFA_CODE_INVALID]") {
+			if err := s.laService.RecordFailure(ctx, user, mfaIdentifier, ipAddress); err != nil {
+				return err
+			}
+		}
+		return err
+	}
+
+	// reset percobaan gagal
+	return s.laService.Reset(ctx, user, mfaIdentifier, ipAddress)
+}
+
+func (s *mfaService) generateRecoveryCodes(ctx context.Context, userID string) (*response.MFARecoveryCodesResponse, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	models := make([]model.MFARecoveryCodeModel, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := s.utility.RecoveryCodeGenerate()
+		if err != nil {
+			return nil, err
+		}
+
+		codes = append(codes, code)
+		models = append(models, model.MFARecoveryCodeModel{
+			ID:       s.utility.ULIDGenerate(),
+			UserID:   userID,
+			CodeHash: s.utility.HashToken(code),
+		})
+	}
+
+	// simpan hash saja, kode asli hanya dikembalikan ke user
+	if err := s.rcRepo.Replace(ctx, userID, models); err != nil {
+		return nil, err
+	}
+
+	return &response.MFARecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// normalizeRecoveryCode menerima input dengan huruf besar, spasi atau tanpa tanda hubung
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	var b strings.Builder
+	for _, r := range code {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+
+	normalized := b.String()
+	if len(normalized) != 10 {
+		return normalized
+	}
+
+	return normalized[:5] + "-" + normalized[5:]
+}
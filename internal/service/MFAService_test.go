@@ -0,0 +1,463 @@
+package service
+
+import (
+	"context"
+	"github.com/gogaruda/apperror"
+	"github.com/irawankilmer/auth-service/internal/configs"
+	"github.com/irawankilmer/auth-service/internal/model"
+	"github.com/irawankilmer/auth-service/pkg/mailer"
+	"github.com/irawankilmer/auth-service/pkg/utils"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeClock jam yang hanya bergerak saat diubah test
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+// fakeMFARepo menyimpan user_mfa di memori dengan aturan UseStep yang sama seperti query database
+type fakeMFARepo struct {
+	mfa map[string]*model.MFAModel
+}
+
+func (r *fakeMFARepo) FindByUserID(_ context.Context, userID string) (*model.MFAModel, error) {
+	mfa, ok := r.mfa[userID]
+	if !ok {
+		return nil, apperror.New("[MFA_NOT_FOUND]", "MFA belum didaftarkan", nil)
+	}
+
+	copied := *mfa
+	return &copied, nil
+}
+
+func (r *fakeMFARepo) SavePending(_ context.Context, userID, secretEncrypted string) error {
+	r.mfa[userID] = &model.MFAModel{UserID: userID, SecretEncrypted: secretEncrypted}
+	return nil
+}
+
+func (r *fakeMFARepo) Enable(_ context.Context, userID string, step int64) error {
+	r.mfa[userID].Enabled = true
+	r.mfa[userID].LastUsedStep = step
+	return nil
+}
+
+func (r *fakeMFARepo) UseStep(_ context.Context, userID string, step int64) (bool, error) {
+	mfa := r.mfa[userID]
+	if mfa.LastUsedStep >= step {
+		return false, nil
+	}
+
+	mfa.LastUsedStep = step
+	return true, nil
+}
+
+func (r *fakeMFARepo) Delete(_ context.Context, userID string) error {
+	delete(r.mfa, userID)
+	return nil
+}
+
+// fakeRecoveryCodeRepo menyimpan recovery code di memori, kode yang sudah dipakai tidak bisa dipakai lagi
+type fakeRecoveryCodeRepo struct {
+	codes map[string][]model.MFARecoveryCodeModel
+}
+
+func (r *fakeRecoveryCodeRepo) Replace(_ context.Context, userID string, codes []model.MFARecoveryCodeModel) error {
+	r.codes[userID] = codes
+	return nil
+}
+
+func (r *fakeRecoveryCodeRepo) Use(_ context.Context, userID, codeHash, ipAddress, userAgent string) (bool, error) {
+	for i, c := range r.codes[userID] {
+		if c.CodeHash == codeHash && c.UsedAt == nil {
+			now := time.Now()
+			r.codes[userID][i].UsedAt, r.codes[userID][i].UsedIPAddress, r.codes[userID][i].UsedUserAgent = &now, &ipAddress, &userAgent
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (r *fakeRecoveryCodeRepo) CountUnused(_ context.Context, userID string) (int, error) {
+	total := 0
+	for _, c := range r.codes[userID] {
+		if c.UsedAt == nil {
+			total++
+		}
+	}
+
+	return total, nil
+}
+
+func (r *fakeRecoveryCodeRepo) DeleteByUserID(_ context.Context, userID string) error {
+	delete(r.codes, userID)
+	return nil
+}
+
+// mulai di awal time step agar skew ke depan dan ke belakang sama-sama utuh
+var mfaTestStart = time.Unix(1_700_000_010, 0)
+
+type mfaTest struct {
+	service  *mfaService
+	clock    *fakeClock
+	mfaRepo  *fakeMFARepo
+	authRepo *fakeAuthRepo
+	secret   string
+}
+
+func newMFATest(t *testing.T) *mfaTest {
+	t.Helper()
+
+	cfg := &configs.AppConfig{
+		JWT:  configs.JWTConfig{Secret: "jwt-secret"},
+		MFA:  configs.MFAConfig{Issuer: "Auth Service", EncryptionKey: "mfa-key", ChallengeTTL: 5 * time.Minute},
+		Hash: configs.PasswordHashConfig{Workers: 1, QueueSize: 1, QueueTimeout: time.Second},
+		// port 1 ditolak seketika, email notifikasi gagal tanpa menunggu
+		Mail: configs.EmailConfig{MailHost: "127.0.0.1", MailPort: 1},
+	}
+	clock := &fakeClock{now: mfaTestStart}
+	utility, err := utils.NewUtilityWithClock(cfg, newTestBcrypt(t), clock)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hash, err := utility.HashGenerate(context.Background(), "rahasia")
+	if err != nil {
+		t.Fatal(err)
+	}
+	laService, authRepo, _ := newTestLoginAttemptService(testLockoutConfig,
+		&model.UserModel{ID: "u1", Email: "u1@example.com", Password: &hash})
+
+	mfaRepo := &fakeMFARepo{mfa: map[string]*model.MFAModel{}}
+	s := &mfaService{
+		mfaRepo:   mfaRepo,
+		rcRepo:    &fakeRecoveryCodeRepo{codes: map[string][]model.MFARecoveryCodeModel{}},
+		authRepo:  authRepo,
+		laService: laService,
+		utility:   utility,
+		cfg:       cfg,
+		mail:      mailer.NewMailer(cfg.Mail),
+		mailQueue: mailer.NewQueue(1, recoveryCodeCount),
+	}
+
+	secret, err := utility.TOTPSecretGenerate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted, err := utility.Encrypt(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mfaRepo.SavePending(context.Background(), "u1", encrypted); err != nil {
+		t.Fatal(err)
+	}
+
+	return &mfaTest{service: s, clock: clock, mfaRepo: mfaRepo, authRepo: authRepo, secret: secret}
+}
+
+// code kode TOTP sejumlah step dari jam saat ini
+func (m *mfaTest) code(t *testing.T, steps int) string {
+	t.Helper()
+
+	code, err := m.service.utility.TOTPCode(m.secret, m.clock.now.Add(time.Duration(steps)*30*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return code
+}
+
+func (m *mfaTest) enable(t *testing.T) {
+	t.Helper()
+
+	if err := m.mfaRepo.Enable(context.Background(), "u1", 0); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMFAVerifyCodeWindow(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps int
+		code  string
+		ok    bool
+	}{
+		{name: "step saat ini", steps: 0, ok: true},
+		{name: "satu step sebelumnya", steps: -1, ok: true},
+		{name: "satu step sesudahnya", steps: 1, ok: true},
+		{name: "dua step sebelumnya", steps: -2},
+		{name: "dua step sesudahnya", steps: 2},
+		{name: "kode bukan angka", code: "abcdef"},
+		{name: "kode terlalu pendek", code: "12345"},
+		{name: "kode kosong", code: " "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newMFATest(t)
+			m.enable(t)
+
+			code := tt.code
+			if code == "" {
+				code = m.code(t, tt.steps)
+			}
+
+			err := m.service.VerifyCode(context.Background(), "u1", code)
+			if tt.ok && err != nil {
+				t.Errorf("kode ditolak: %v", err)
+			}
+			if !tt.ok && !apperror.Is(err, "[MFA_CODE_INVALID]") {
+				t.Errorf("err = %v, ingin [MFA_CODE_INVALID]", err)
+			}
+		})
+	}
+}
+
+func TestMFAVerifyCodeSkewAcrossTime(t *testing.T) {
+	m := newMFATest(t)
+	m.enable(t)
+	code := m.code(t, 0)
+
+	// kode yang sama masih diterima selama satu step ke depan, lalu kedaluwarsa
+	tests := []struct {
+		after time.Duration
+		ok    bool
+	}{
+		{after: 29 * time.Second, ok: true},
+		{after: 59 * time.Second, ok: true},
+		{after: 60 * time.Second},
+		{after: 10 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		m.clock.now = mfaTestStart.Add(tt.after)
+		m.mfaRepo.mfa["u1"].LastUsedStep = 0
+
+		err := m.service.VerifyCode(context.Background(), "u1", code)
+		if (err == nil) != tt.ok {
+			t.Errorf("setelah %v: err = %v, ingin diterima %v", tt.after, err, tt.ok)
+		}
+	}
+}
+
+func TestMFAVerifyCodeReuse(t *testing.T) {
+	m := newMFATest(t)
+	m.enable(t)
+	ctx := context.Background()
+
+	current := m.code(t, 0)
+	if err := m.service.VerifyCode(ctx, "u1", current); err != nil {
+		t.Fatal(err)
+	}
+
+	// kode yang sama dalam step yang sama
+	if err := m.service.VerifyCode(ctx, "u1", current); !apperror.Is(err, "[MFA_CODE_INVALID]") {
+		t.Errorf("kode dipakai ulang: err = %v, ingin [MFA_CODE_INVALID]", err)
+	}
+
+	// kode step sebelumnya masih dalam skew, tetapi lebih lama dari kode yang sudah dipakai
+	if err := m.service.VerifyCode(ctx, "u1", m.code(t, -1)); !apperror.Is(err, "[MFA_CODE_INVALID]") {
+		t.Errorf("kode lebih lama: err = %v, ingin [MFA_CODE_INVALID]", err)
+	}
+
+	// step berikutnya diterima
+	m.clock.now = m.clock.now.Add(30 * time.Second)
+	if err := m.service.VerifyCode(ctx, "u1", m.code(t, 0)); err != nil {
+		t.Errorf("kode step berikutnya ditolak: %v", err)
+	}
+}
+
+func TestMFAConfirmMarksStepUsed(t *testing.T) {
+	m := newMFATest(t)
+	ctx := context.Background()
+	code := m.code(t, 0)
+
+	if err := m.service.VerifyCode(ctx, "u1", code); !apperror.Is(err, "[MFA_NOT_ENABLED]") {
+		t.Errorf("MFA belum dikonfirmasi: err = %v, ingin [MFA_NOT_ENABLED]", err)
+	}
+	if _, err := m.service.Confirm(ctx, "u1", code, "10.0.0.1"); err != nil {
+		t.Fatal(err)
+	}
+
+	// kode yang dipakai untuk konfirmasi tidak bisa dipakai lagi untuk login
+	if err := m.service.VerifyCode(ctx, "u1", code); !apperror.Is(err, "[MFA_CODE_INVALID]") {
+		t.Errorf("kode konfirmasi dipakai ulang: err = %v, ingin [MFA_CODE_INVALID]", err)
+	}
+	if _, err := m.service.Confirm(ctx, "u1", code, "10.0.0.1"); !apperror.Is(err, "[MFA_ALREADY_ENABLED]") {
+		t.Errorf("konfirmasi kedua: err = %v, ingin [MFA_ALREADY_ENABLED]", err)
+	}
+}
+
+func TestMFAChallengeExpiry(t *testing.T) {
+	m := newMFATest(t)
+	utility := m.service.utility
+
+	token, err := utility.MFAChallengeGenerate("u1", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		after time.Duration
+		ok    bool
+	}{
+		{name: "baru dibuat", token: token, ok: true},
+		{name: "tepat sebelum kedaluwarsa", token: token, after: 5*time.Minute - time.Second, ok: true},
+		{name: "kedaluwarsa", token: token, after: 5*time.Minute + time.Second},
+		{name: "token rusak", token: token + "x"},
+		{name: "token kosong"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m.clock.now = mfaTestStart.Add(tt.after)
+
+			userID, rememberMe, err := utility.MFAChallengeParse(tt.token)
+			if !tt.ok {
+				if !apperror.Is(err, "[MFA_TOKEN_INVALID]") {
+					t.Errorf("err = %v, ingin [MFA_TOKEN_INVALID]", err)
+				}
+				return
+			}
+			if err != nil || userID != "u1" || !rememberMe {
+				t.Errorf("MFAChallengeParse() = %q, %v, %v", userID, rememberMe, err)
+			}
+		})
+	}
+}
+
+func TestMFARecoveryCodeSingleUse(t *testing.T) {
+	m := newMFATest(t)
+	ctx := context.Background()
+
+	codes, err := m.service.Confirm(ctx, "u1", m.code(t, 0), "10.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes.RecoveryCodes) != recoveryCodeCount {
+		t.Fatalf("jumlah recovery code = %d, ingin %d", len(codes.RecoveryCodes), recoveryCodeCount)
+	}
+
+	user := &model.UserModel{ID: "u1", Email: "u1@example.com", MFAEnabled: true}
+	first, second := codes.RecoveryCodes[0], codes.RecoveryCodes[1]
+
+	tests := []struct {
+		name string
+		user *model.UserModel
+		code string
+		ok   bool
+	}{
+		{name: "kode valid", user: user, code: first, ok: true},
+		{name: "kode yang sama dipakai ulang", user: user, code: first},
+		{name: "huruf besar dan spasi", user: user, code: strings.ToUpper(strings.ReplaceAll(second, "-", " ")), ok: true},
+		{name: "kode kedua dipakai ulang tanpa tanda hubung", user: user, code: strings.ReplaceAll(second, "-", "")},
+		{name: "kode asal", user: user, code: "aaaaa-bbbbb"},
+		{name: "user tanpa MFA", user: &model.UserModel{ID: "u1"}, code: codes.RecoveryCodes[2]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := m.service.UseRecoveryCode(ctx, tt.user, tt.code, "10.0.0.1", "test")
+			if tt.ok && err != nil {
+				t.Errorf("recovery code ditolak: %v", err)
+			}
+			if !tt.ok && !apperror.Is(err, "[MFA_CODE_INVALID]") {
+				t.Errorf("err = %v, ingin [MFA_CODE_INVALID]", err)
+			}
+		})
+	}
+
+	// kode baru membatalkan semua kode lama yang belum dipakai
+	m.clock.now = m.clock.now.Add(30 * time.Second)
+	if _, err := m.service.RegenerateRecoveryCodes(ctx, "u1", m.code(t, 0), "rahasia", "10.0.0.1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.service.UseRecoveryCode(ctx, user, codes.RecoveryCodes[3], "10.0.0.1", "test"); !apperror.Is(err, "[MFA_CODE_INVALID]") {
+		t.Errorf("kode lama setelah dibuat ulang: err = %v, ingin [MFA_CODE_INVALID]", err)
+	}
+}
+
+// TestMFACodeFailuresLock kode salah saat konfirmasi, menonaktifkan MFA dan membuat ulang recovery code
+// dihitung seperti login MFA gagal, setelah terkunci kode yang benar pun ditolak
+func TestMFACodeFailuresLock(t *testing.T) {
+	tests := []struct {
+		name    string
+		enabled bool
+		submit  func(m *mfaTest, code string) error
+	}{
+		{
+			name: "confirm",
+			submit: func(m *mfaTest, code string) error {
+				_, err := m.service.Confirm(context.Background(), "u1", code, "10.0.0.1")
+				return err
+			},
+		},
+		{
+			name:    "disable",
+			enabled: true,
+			submit: func(m *mfaTest, code string) error {
+				return m.service.Disable(context.Background(), "u1", code, "rahasia", "10.0.0.1")
+			},
+		},
+		{
+			name:    "recovery codes",
+			enabled: true,
+			submit: func(m *mfaTest, code string) error {
+				_, err := m.service.RegenerateRecoveryCodes(context.Background(), "u1", code, "rahasia", "10.0.0.1")
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newMFATest(t)
+			if tt.enabled {
+				m.enable(t)
+			}
+
+			for i := 1; i < testLockoutConfig.MaxAttempts; i++ {
+				if err := tt.submit(m, "000000"); !apperror.Is(err, "[MFA_CODE_INVALID]") {
+					t.Fatalf("percobaan %d: err = %v, ingin [MFA_CODE_INVALID]", i, err)
+				}
+			}
+			if err := tt.submit(m, "000000"); !apperror.Is(err, "[ACCOUNT_LOCKED]") {
+				t.Fatalf("percobaan terakhir: err = %v, ingin [ACCOUNT_LOCKED]", err)
+			}
+			if err := tt.submit(m, m.code(t, 0)); !apperror.Is(err, "[ACCOUNT_LOCKED]") {
+				t.Errorf("kode benar saat terkunci: err = %v, ingin [ACCOUNT_LOCKED]", err)
+			}
+			if m.mfaRepo.mfa["u1"].Enabled != tt.enabled {
+				t.Error("status MFA berubah padahal akun terkunci")
+			}
+		})
+	}
+}
+
+// TestMFARegenerateRecoveryCodesPassword recovery code baru hanya dibuat dengan password yang benar
+func TestMFARegenerateRecoveryCodesPassword(t *testing.T) {
+	m := newMFATest(t)
+	m.enable(t)
+	ctx := context.Background()
+
+	if _, err := m.service.RegenerateRecoveryCodes(ctx, "u1", m.code(t, 0), "salah", "10.0.0.1"); !apperror.Is(err, "[PASSWORD_INVALID]") {
+		t.Fatalf("password salah: err = %v, ingin [PASSWORD_INVALID]", err)
+	}
+
+	m.clock.now = m.clock.now.Add(30 * time.Second)
+	codes, err := m.service.RegenerateRecoveryCodes(ctx, "u1", m.code(t, 0), "rahasia", "10.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes.RecoveryCodes) != recoveryCodeCount {
+		t.Errorf("jumlah recovery code = %d, ingin %d", len(codes.RecoveryCodes), recoveryCodeCount)
+	}
+}
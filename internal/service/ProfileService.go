@@ -0,0 +1,224 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/gogaruda/apperror"
+	"github.com/irawankilmer/auth-service/internal/configs"
+	"github.com/irawankilmer/auth-service/internal/dto/request"
+	"github.com/irawankilmer/auth-service/internal/dto/response"
+	"github.com/irawankilmer/auth-service/internal/model"
+	"github.com/irawankilmer/auth-service/internal/repository"
+	"github.com/irawankilmer/auth-service/pkg/imageproc"
+	"github.com/irawankilmer/auth-service/pkg/storage"
+	"github.com/irawankilmer/auth-service/pkg/utils"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// avatarPrefix prefix key storage avatar hasil upload, image lain (misal "default.jpg" dari seeder) dianggap satu file
+const avatarPrefix = "avatars/"
+
+type ProfileService interface {
+	Get(ctx context.Context, userID string) (*response.ProfileDetailResponse, error)
+	Update(ctx context.Context, userID string, req request.ProfileUpdateRequest) (*response.ProfileUpdateResponse, error)
+	UploadAvatar(ctx context.Context, userID string, data []byte) (*response.ProfileDetailResponse, error)
+	DeleteAvatar(ctx context.Context, userID string) error
+	ImageURLs(image *string) map[string]string
+}
+
+type profileService struct {
+	profileRepo repository.ProfileRepository
+	storage     storage.Storage
+	utility     utils.Utility
+	cfg         configs.AvatarConfig
+}
+
+func NewProfileService(pr repository.ProfileRepository, st storage.Storage, ut utils.Utility, cfg configs.AvatarConfig) ProfileService {
+	return &profileService{profileRepo: pr, storage: st, utility: ut, cfg: cfg}
+}
+
+func (s *profileService) Get(ctx context.Context, userID string) (*response.ProfileDetailResponse, error) {
+	profile, err := s.profileRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	detail := s.profileDetail(profile)
+	return &detail, nil
+}
+
+// Update mengubah sebagian field profil, hanya field yang nilainya berbeda yang dicatat sebagai perubahan
+func (s *profileService) Update(ctx context.Context, userID string, req request.ProfileUpdateRequest) (*response.ProfileUpdateResponse, error) {
+	// cek profil
+	profile, err := s.profileRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// terapkan perubahan
+	if req.FullName != nil && strings.TrimSpace(*req.FullName) == "" {
+		return nil, apperror.New(apperror.CodeInvalidInput, "full_name tidak boleh kosong", nil)
+	}
+	changed := []string{}
+	for _, f := range []struct {
+		name  string
+		field **string
+		value *string
+	}{
+		{"full_name", &profile.FullName, req.FullName},
+		{"address", &profile.Address, req.Address},
+		{"gender", &profile.Gender, req.Gender},
+	} {
+		if changeProfileField(f.field, f.value) {
+			changed = append(changed, f.name)
+		}
+	}
+
+	// simpan hanya jika ada perubahan
+	if len(changed) > 0 {
+		if err := s.profileRepo.Update(ctx, profile); err != nil {
+			return nil, err
+		}
+	}
+
+	return &response.ProfileUpdateResponse{
+		Profile:       s.profileDetail(profile),
+		ChangedFields: changed,
+		UpdatedAt:     profile.UpdatedAt,
+	}, nil
+}
+
+// UploadAvatar memproses gambar menjadi beberapa ukuran persegi JPEG, menyimpannya ke storage
+// lalu mengganti image profil dengan key baru. File avatar lama dihapus setelah profil tersimpan
+func (s *profileService) UploadAvatar(ctx context.Context, userID string, data []byte) (*response.ProfileDetailResponse, error) {
+	// cek profil
+	profile, err := s.profileRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// cek dan decode gambar
+	img, contentType, err := imageproc.Decode(data, s.cfg.MaxPixels)
+	if err != nil {
+		if errors.Is(err, imageproc.ErrTooLarge) {
+			return nil, apperror.New("[AVATAR_TOO_LARGE]", "dimensi gambar terlalu besar", err, http.StatusRequestEntityTooLarge)
+		}
+
+		return nil, apperror.New("[AVATAR_INVALID]", "file harus berupa gambar JPEG, PNG atau GIF", errors.New(contentType+": "+err.Error()), http.StatusUnsupportedMediaType)
+	}
+
+	// simpan semua ukuran, key baru setiap upload agar cache URL lama tidak terpakai
+	key := avatarPrefix + userID + "/" + s.utility.ULIDGenerate()
+	for _, size := range s.cfg.Sizes {
+		body, err := imageproc.SquareJPEG(img, size, s.cfg.Quality)
+		if err != nil {
+			return nil, apperror.New(apperror.CodeInternalError, "proses avatar gagal", err)
+		}
+		if err := s.storage.Put(ctx, avatarKey(key, size), body, "image/jpeg"); err != nil {
+			s.deleteAvatarFiles(key)
+			return nil, apperror.New(apperror.CodeDependencyError, "simpan avatar gagal", err)
+		}
+	}
+
+	// ganti image profil
+	oldImage := profile.Image
+	profile.Image = &key
+	if err := s.profileRepo.Update(ctx, profile); err != nil {
+		s.deleteAvatarFiles(key)
+		return nil, err
+	}
+	if oldImage != nil {
+		s.deleteAvatarFiles(*oldImage)
+	}
+
+	detail := s.profileDetail(profile)
+	return &detail, nil
+}
+
+func (s *profileService) DeleteAvatar(ctx context.Context, userID string) error {
+	// cek profil
+	profile, err := s.profileRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if profile.Image == nil {
+		return nil
+	}
+
+	// kosongkan image profil lalu hapus file
+	oldImage := *profile.Image
+	profile.Image = nil
+	if err := s.profileRepo.Update(ctx, profile); err != nil {
+		return err
+	}
+	s.deleteAvatarFiles(oldImage)
+
+	return nil
+}
+
+// ImageURLs mengubah key image profil menjadi URL per ukuran avatar
+func (s *profileService) ImageURLs(image *string) map[string]string {
+	urls := map[string]string{}
+	if image == nil || *image == "" {
+		return urls
+	}
+	if !strings.HasPrefix(*image, avatarPrefix) {
+		urls["original"] = s.storage.URL(*image)
+		return urls
+	}
+
+	for _, size := range s.cfg.Sizes {
+		urls[strconv.Itoa(size)] = s.storage.URL(avatarKey(*image, size))
+	}
+
+	return urls
+}
+
+// deleteAvatarFiles menghapus file avatar hasil upload, gagal hapus hanya dicatat karena profil sudah tersimpan
+func (s *profileService) deleteAvatarFiles(key string) {
+	if !strings.HasPrefix(key, avatarPrefix) {
+		return
+	}
+
+	for _, size := range s.cfg.Sizes {
+		if err := s.storage.Delete(context.Background(), avatarKey(key, size)); err != nil {
+			log.Printf("[WARN] hapus avatar %s gagal: %v", avatarKey(key, size), err)
+		}
+	}
+}
+
+func avatarKey(key string, size int) string {
+	return key + "_" + strconv.Itoa(size) + ".jpg"
+}
+
+// changeProfileField: nil berarti tidak diubah, string kosong berarti dikosongkan (NULL)
+func changeProfileField(field **string, value *string) bool {
+	if value == nil {
+		return false
+	}
+
+	var next *string
+	if trimmed := strings.TrimSpace(*value); trimmed != "" {
+		next = &trimmed
+	}
+	if (*field == nil && next == nil) || (*field != nil && next != nil && **field == *next) {
+		return false
+	}
+
+	*field = next
+	return true
+}
+
+func (s *profileService) profileDetail(profile *model.ProfileModel) response.ProfileDetailResponse {
+	return response.ProfileDetailResponse{
+		ID:        profile.ID,
+		FullName:  profile.FullName,
+		Address:   profile.Address,
+		Gender:    profile.Gender,
+		Image:     profile.Image,
+		ImageURLs: s.ImageURLs(profile.Image),
+	}
+}
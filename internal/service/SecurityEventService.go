@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/irawankilmer/auth-service/internal/configs"
+	"github.com/irawankilmer/auth-service/internal/model"
+	"github.com/irawankilmer/auth-service/internal/repository"
+	"github.com/irawankilmer/auth-service/pkg/device"
+	"github.com/irawankilmer/auth-service/pkg/mailer"
+	"github.com/irawankilmer/auth-service/pkg/utils"
+	"html"
+	"log"
+	"time"
+)
+
+type SecurityEventService interface {
+	RefreshTokenReused(ctx context.Context, session *model.UserSession, ipAddress, userAgent string) error
+}
+
+type securityEventService struct {
+	eventRepo repository.SecurityEventRepository
+	authRepo  repository.AuthRepository
+	mail      *mailer.Mailer
+	utility   utils.Utility
+	cfg       *configs.AppConfig
+}
+
+func NewSecurityEventService(er repository.SecurityEventRepository, ar repository.AuthRepository,
+	mail *mailer.Mailer, ut utils.Utility, cfg *configs.AppConfig,
+) SecurityEventService {
+	return &securityEventService{eventRepo: er, authRepo: ar, mail: mail, utility: ut, cfg: cfg}
+}
+
+// RefreshTokenReused mencatat pemakaian ulang refresh token yang sudah dirotasi lalu mengirim email peringatan
+// ke user di background
+func (s *securityEventService) RefreshTokenReused(ctx context.Context, session *model.UserSession, ipAddress, userAgent string) error {
+	event := &model.SecurityEventModel{
+		ID:        s.utility.ULIDGenerate(),
+		UserID:    session.UserID,
+		EventType: model.SecurityEventRefreshTokenReuse,
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+		Metadata: map[string]string{
+			"session_id": session.ID,
+			"family_id":  session.FamilyID,
+		},
+		CreatedAt: s.utility.Now(),
+	}
+	if err := s.eventRepo.Create(ctx, event); err != nil {
+		return err
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		user, err := s.authRepo.FindByID(ctx, session.UserID)
+		if err != nil {
+			log.Printf("[WARN] ambil user %s untuk email security event gagal: %v", session.UserID, err)
+			return
+		}
+
+		if err := s.sendRefreshTokenReused(user, event); err != nil {
+			log.Printf("[WARN] email security event gagal dikirim ke user %s: %v", user.ID, err)
+		}
+	}()
+
+	return nil
+}
+
+func (s *securityEventService) sendRefreshTokenReused(user *model.UserModel, event *model.SecurityEventModel) error {
+	body := fmt.Sprintf(`
+	<h2>Aktivitas Mencurigakan di Akun Anda</h2>
+	<p>Halo,</p>
+	<p>Token login lama akun Anda dipakai kembali. Ini bisa berarti token login Anda dicuri,
+	karena itu semua perangkat yang memakai login tersebut sudah kami keluarkan.</p>
+	<ul>
+		<li>Waktu: %s</li>
+		<li>Alamat IP: %s</li>
+		<li>Perangkat: %s</li>
+	</ul>
+	<p>Silakan login kembali. Jika Anda tidak mengenali aktivitas ini, segera ganti password
+	dan aktifkan MFA.</p>
+	<p>Salam hangat,<br><strong>Tim Support %s</strong></p>
+`, event.CreatedAt.Format(time.RFC1123), html.EscapeString(event.IPAddress),
+		html.EscapeString(device.ParseUserAgent(event.UserAgent).String()), "Sekolah Kita")
+
+	return s.mail.Send(user.Email, "Aktivitas Mencurigakan di Akun Anda", body)
+}
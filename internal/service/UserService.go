@@ -2,14 +2,17 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"github.com/gogaruda/apperror"
 	"github.com/irawankilmer/auth-service/internal/configs"
 	"github.com/irawankilmer/auth-service/internal/dto/request"
 	"github.com/irawankilmer/auth-service/internal/dto/response"
 	"github.com/irawankilmer/auth-service/internal/model"
 	"github.com/irawankilmer/auth-service/internal/repository"
+	"github.com/irawankilmer/auth-service/pkg/tokencache"
 	"github.com/irawankilmer/auth-service/pkg/utils"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -18,8 +21,10 @@ type UserService interface {
 	Create(ctx context.Context, req request.UserCreateRequest) error
 	FindByID(ctx context.Context, userID string) (*response.UserDetailResponse, error)
 	EmailUpdate(ctx context.Context, user *response.UserDetailResponse, newEmail string) (bool, error)
+	UsernameUpdate(ctx context.Context, userID, newUsername string, byAdmin bool) (bool, error)
 	RolesUpdate(ctx context.Context, user *response.UserDetailResponse, newRoles []string) (bool, error)
 	Delete(ctx context.Context, user *response.UserDetailResponse) error
+	Unlock(ctx context.Context, user *response.UserDetailResponse) error
 }
 
 type userService struct {
@@ -30,14 +35,19 @@ type userService struct {
 	utilities    utils.Utility
 	config       *configs.AppConfig
 	evService    EmailVerificationService
+	laService    LoginAttemptService
+	profService  ProfileService
+	tokenCache   tokencache.Cache
 }
 
 func NewUserService(
 	ur repository.UserRepository, rp repository.RoleRepository, un repository.UsernameHistoryRepository,
 	er repository.EmailHistoryRepository, ut utils.Utility, cfg *configs.AppConfig, ev EmailVerificationService,
+	la LoginAttemptService, ps ProfileService, tc tokencache.Cache,
 ) UserService {
 	return &userService{
 		userRepo: ur, roleRepo: rp, usernameRepo: un, emailRepo: er, utilities: ut, config: cfg, evService: ev,
+		laService: la, profService: ps, tokenCache: tc,
 	}
 }
 
@@ -86,7 +96,6 @@ func (s *userService) Create(ctx context.Context, req request.UserCreateRequest)
 		TokenVersion:   tokenVersion,
 		EmailVerified:  false,
 		CreatedByAdmin: true,
-		GoogleID:       nil,
 		Profile: model.ProfileModel{
 			ID:       s.utilities.ULIDGenerate(),
 			UserID:   userID,
@@ -111,7 +120,13 @@ func (s *userService) Create(ctx context.Context, req request.UserCreateRequest)
 }
 
 func (s *userService) FindByID(ctx context.Context, userID string) (*response.UserDetailResponse, error) {
-	return s.userRepo.FindByID(ctx, userID)
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	user.Profile.ImageURLs = s.profService.ImageURLs(user.Profile.Image)
+	return user, nil
 }
 
 func (s *userService) EmailUpdate(ctx context.Context, user *response.UserDetailResponse, newEmail string) (bool, error) {
@@ -157,14 +172,114 @@ func (s *userService) RolesUpdate(ctx context.Context, user *response.UserDetail
 		return false, nil
 	}
 
-	// update roles
-	if err := s.userRepo.RoleUpdate(ctx, user, newRolesCheck); err != nil {
+	// generate token version baru, access token dengan role lama harus di-refresh
+	newTokenVersion, err := s.utilities.UUIDGenerate()
+	if err != nil {
+		return false, apperror.New(apperror.CodeInternalError, "generate new token version gagal", err)
+	}
+
+	// update roles dan token version
+	if err := s.userRepo.RoleUpdate(ctx, user, newRolesCheck, newTokenVersion); err != nil {
 		return false, err
 	}
+	s.tokenCache.Invalidate(user.ID)
 
 	return true, nil
 }
 
 func (s *userService) Delete(ctx context.Context, user *response.UserDetailResponse) error {
-	return s.userRepo.Delete(ctx, user)
+	if err := s.userRepo.Delete(ctx, user); err != nil {
+		return err
+	}
+	s.tokenCache.Invalidate(user.ID)
+
+	return nil
+}
+
+func (s *userService) Unlock(ctx context.Context, user *response.UserDetailResponse) error {
+	// hapus juga kunci identifier+IP untuk username dan email user
+	identifiers := []string{user.Email}
+	if user.Username != nil {
+		identifiers = append(identifiers, *user.Username)
+	}
+
+	return s.laService.Unlock(ctx, user.ID, identifiers...)
+}
+
+// UsernameUpdate mengganti username sesuai kebijakan username. Interval ganti username dibedakan
+// antara perubahan oleh user sendiri dan oleh admin, interval 0 berarti tanpa batas
+func (s *userService) UsernameUpdate(ctx context.Context, userID, newUsername string, byAdmin bool) (bool, error) {
+	newUsername = strings.ToLower(strings.TrimSpace(newUsername))
+
+	// cek kebijakan username
+	if err := usernamePolicy(newUsername, s.config.Username); err != nil {
+		return false, err
+	}
+
+	// cek user
+	user, err := s.userRepo.FindUsername(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	if user.Username != nil && *user.Username == newUsername {
+		return false, nil
+	}
+
+	// cek interval ganti username
+	interval := s.config.Username.ChangeInterval
+	if byAdmin {
+		interval = s.config.Username.AdminChangeInterval
+	}
+	if interval > 0 && user.UsernameChangedAt != nil {
+		if next := user.UsernameChangedAt.Add(interval); time.Now().Before(next) {
+			return false, apperror.New("[USERNAME_CHANGE_TOO_SOON]",
+				"username baru bisa diganti lagi setelah "+next.Format("02-01-2006 15:04"), nil, http.StatusTooManyRequests)
+		}
+	}
+
+	// cek username dari tabel users
+	usernameExists, err := s.userRepo.UsernameChange(ctx, &response.UserDetailResponse{ID: user.ID}, newUsername)
+	if err != nil {
+		return false, err
+	}
+	if usernameExists {
+		return false, apperror.New(apperror.CodeUsernameConflict, "username tidak dapat digunakan", nil)
+	}
+
+	// cek username dari tabel username_history
+	usernameHistory, err := s.usernameRepo.IsUsernameExists(ctx, newUsername)
+	if err != nil {
+		return false, err
+	}
+	if usernameHistory {
+		return false, apperror.New(apperror.CodeUsernameConflict, "username sudah tidak dapat digunakan", nil)
+	}
+
+	// update username
+	if err := s.userRepo.UsernameUpdate(ctx, user, newUsername); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// usernamePolicy: huruf kecil, angka, titik dan underscore, diawali huruf, panjang sesuai konfigurasi
+func usernamePolicy(username string, cfg configs.UsernameConfig) error {
+	invalid := func(msg string) error {
+		return apperror.New("[USERNAME_POLICY]", msg, nil, http.StatusBadRequest)
+	}
+
+	if len(username) < cfg.MinLength || len(username) > cfg.MaxLength {
+		return invalid(fmt.Sprintf("username harus %d sampai %d karakter", cfg.MinLength, cfg.MaxLength))
+	}
+	if username[0] < 'a' || username[0] > 'z' {
+		return invalid("username harus diawali huruf")
+	}
+	for _, r := range username {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '.' && r != '_' {
+			return invalid("username hanya boleh berisi huruf, angka, titik dan underscore")
+		}
+	}
+
+	return nil
 }
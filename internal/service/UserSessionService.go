@@ -4,26 +4,44 @@ import (
 	"context"
 	"github.com/gogaruda/apperror"
 	"github.com/irawankilmer/auth-service/internal/configs"
+	"github.com/irawankilmer/auth-service/internal/dto/request"
 	"github.com/irawankilmer/auth-service/internal/dto/response"
 	"github.com/irawankilmer/auth-service/internal/model"
 	"github.com/irawankilmer/auth-service/internal/repository"
+	"github.com/irawankilmer/auth-service/pkg/device"
+	"github.com/irawankilmer/auth-service/pkg/tokencache"
 	"github.com/irawankilmer/auth-service/pkg/utils"
 	"net/http"
+	"slices"
 	"time"
 )
 
 type UserSessionService interface {
 	Refresh(ctx context.Context, refreshToken, deviceID, ipAddress, userAgent string) (*response.LoginResponse, error)
+	RevokeByLink(ctx context.Context, req request.SessionRevokeLinkRequest) error
+	List(ctx context.Context, userID, refreshToken, deviceID string) ([]response.SessionResponse, error)
+	Revoke(ctx context.Context, userID, sessionID string) error
+	ListByUser(ctx context.Context, userID string) ([]response.SessionResponse, error)
+	ManageTarget(ctx context.Context, actorRoles []string, userID string) error
+	Search(ctx context.Context, userID, ipAddress, status string, limit, offset int) ([]response.SessionAdminResponse, int, error)
 }
 
 type userSessionServiceImpl struct {
-	usRepo    repository.UserSessionRepository
-	utilities utils.Utility
-	cfg       *configs.AppConfig
+	usRepo          repository.UserSessionRepository
+	authRepo        repository.AuthRepository
+	utilities       utils.Utility
+	cfg             *configs.AppConfig
+	deviceService   DeviceService
+	securityService SecurityEventService
+	tokenCache      tokencache.Cache
 }
 
-func NewUserSessionService(usR repository.UserSessionRepository, util utils.Utility, cfg *configs.AppConfig) UserSessionService {
-	return &userSessionServiceImpl{usRepo: usR, utilities: util, cfg: cfg}
+func NewUserSessionService(usR repository.UserSessionRepository, authR repository.AuthRepository, util utils.Utility,
+	cfg *configs.AppConfig, ds DeviceService, ses SecurityEventService, tc tokencache.Cache,
+) UserSessionService {
+	return &userSessionServiceImpl{
+		usRepo: usR, authRepo: authR, utilities: util, cfg: cfg, deviceService: ds, securityService: ses, tokenCache: tc,
+	}
 }
 
 func (s *userSessionServiceImpl) Refresh(ctx context.Context, refreshToken, deviceID, ipAddress, userAgent string) (*response.LoginResponse, error) {
@@ -33,6 +51,11 @@ func (s *userSessionServiceImpl) Refresh(ctx context.Context, refreshToken, devi
 		return nil, err
 	}
 
+	// refresh token yang sudah dirotasi dipakai lagi di luar grace window dianggap dicuri
+	if session.Revoked && session.RotatedAt != nil && time.Since(*session.RotatedAt) > s.cfg.Session.ReuseGrace {
+		return nil, s.refreshTokenReused(ctx, session, ipAddress, userAgent)
+	}
+
 	// cek apakah refresh token revoked atau sudah kadaluarsa
 	if session.Revoked || session.ExpiresAt.Before(time.Now()) {
 		return nil, apperror.New("[REFRESH_TOKEN_INVALID]", "refresh token invalid", nil, http.StatusUnauthorized)
@@ -44,10 +67,26 @@ func (s *userSessionServiceImpl) Refresh(ctx context.Context, refreshToken, devi
 		return nil, err
 	}
 
-	// revoked token lama
-	if err := s.usRepo.Revoked(ctx, session.ID); err != nil {
+	// cek idle timeout dan umur maksimal session, kebijakan bisa sudah diperketat sejak session dibuat
+	now := time.Now()
+	policy := sessionPolicy(s.cfg.Session, user.Roles, session.RememberMe)
+	if now.Sub(session.LastUsedAt) > policy.IdleTimeout || now.Sub(session.CreatedAt) > policy.AbsoluteLifetime {
+		return nil, apperror.New("[SESSION_EXPIRED]", "session sudah berakhir, silakan login kembali", nil, http.StatusUnauthorized)
+	}
+
+	// revoked token lama, gagal jika refresh lain dengan token yang sama sudah lebih dulu merotasinya
+	rotated, err := s.usRepo.Rotate(ctx, session.ID)
+	if err != nil {
 		return nil, err
 	}
+	if !rotated {
+		return nil, apperror.New("[REFRESH_TOKEN_INVALID]", "refresh token invalid", nil, http.StatusUnauthorized)
+	}
+
+	// device ID tetap sama sepanjang rotasi, session lama tanpa device ID memakai device dari request
+	if device.IsID(session.DeviceID) {
+		deviceID = session.DeviceID
+	}
 
 	// ambil roles
 	var roles []string
@@ -67,21 +106,295 @@ func (s *userSessionServiceImpl) Refresh(ctx context.Context, refreshToken, devi
 		return nil, err
 	}
 
-	// create refresh token
+	// create refresh token, session hasil rotasi tetap dibatasi umur maksimal sejak login awal
+	expiresAt := sessionExpiresAt(policy, session.CreatedAt, now)
 	if err := s.usRepo.Create(ctx, &model.UserSession{
 		ID:               s.utilities.ULIDGenerate(),
 		UserID:           user.ID,
+		FamilyID:         session.FamilyID,
+		ParentID:         &session.ID,
 		RefreshTokenHash: s.utilities.HashToken(newRefreshToken),
 		DeviceID:         deviceID,
+		RememberMe:       session.RememberMe,
 		IPAddress:        ipAddress,
 		UserAgent:        userAgent,
-		ExpiresAt:        time.Now().Add(30 * 24 * time.Hour),
+		ExpiresAt:        expiresAt,
+		CreatedAt:        session.CreatedAt,
 	}); err != nil {
 		return nil, err
 	}
 
+	// perbarui waktu terakhir perangkat dipakai
+	if err := s.deviceService.Touch(ctx, user.ID, deviceID, userAgent, ipAddress, false); err != nil {
+		return nil, err
+	}
+
 	return &response.LoginResponse{
-		AccessToken:  accessToken,
-		RefreshToken: newRefreshToken,
+		AccessToken:      accessToken,
+		RefreshToken:     newRefreshToken,
+		ExpiresIn:        int(s.cfg.JWT.AccessTokenTTL.Seconds()),
+		RefreshExpiresIn: int(expiresAt.Sub(now).Seconds()),
 	}, nil
 }
+
+// RevokeByLink revoke session dari link "bukan saya" di email login baru, tanpa perlu login.
+// Link hanya berlaku sekali, link untuk family yang sudah di-revoke ditolak
+func (s *userSessionServiceImpl) RevokeByLink(ctx context.Context, req request.SessionRevokeLinkRequest) error {
+	invalid := apperror.New("[REVOKE_TOKEN_INVALID]", "link revoke session tidak valid atau sudah kadaluwarsa", nil, http.StatusUnauthorized)
+
+	// cek token link
+	userID, sessionID, err := s.utilities.SessionRevokeParse(req.Token)
+	if err != nil {
+		return err
+	}
+
+	// cek session milik user di token
+	session, err := s.usRepo.FindByID(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	if session.UserID != userID {
+		return invalid
+	}
+
+	// session lama mungkin sudah dirotasi, session hasil rotasinya ikut di-revoke
+	revoked, err := s.usRepo.RevokeFamily(ctx, session.FamilyID)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return invalid
+	}
+
+	// generate token version baru
+	newTokenVersion, err := s.utilities.UUIDGenerate()
+	if err != nil {
+		return apperror.New(apperror.CodeInternalError, "generate new token version gagal", err)
+	}
+
+	// access token session tersebut ikut tidak berlaku
+	if err := s.authRepo.UpdateTokenVersion(ctx, session.UserID, newTokenVersion); err != nil {
+		return err
+	}
+	s.tokenCache.Invalidate(session.UserID)
+
+	return nil
+}
+
+// refreshTokenReused mengakhiri semua session dalam family, mengganti token version agar access token
+// yang sudah terbit ikut tidak berlaku, lalu mencatat security event
+func (s *userSessionServiceImpl) refreshTokenReused(ctx context.Context, session *model.UserSession, ipAddress, userAgent string) error {
+	// revoke family
+	if _, err := s.usRepo.RevokeFamily(ctx, session.FamilyID); err != nil {
+		return err
+	}
+
+	// generate token version baru
+	newTokenVersion, err := s.utilities.UUIDGenerate()
+	if err != nil {
+		return apperror.New(apperror.CodeInternalError, "generate new token version gagal", err)
+	}
+
+	// update token version
+	if err := s.authRepo.UpdateTokenVersion(ctx, session.UserID, newTokenVersion); err != nil {
+		return err
+	}
+	s.tokenCache.Invalidate(session.UserID)
+
+	// catat security event dan kirim email
+	if err := s.securityService.RefreshTokenReused(ctx, session, ipAddress, userAgent); err != nil {
+		return err
+	}
+
+	return apperror.New("[REFRESH_TOKEN_REUSED]", "refresh token sudah pernah dipakai, silakan login kembali", nil, http.StatusUnauthorized)
+}
+
+// List session aktif user login. Session saat ini dikenali dari cookie refresh token,
+// client tanpa cookie (mobile) dikenali dari device ID
+func (s *userSessionServiceImpl) List(ctx context.Context, userID, refreshToken, deviceID string) ([]response.SessionResponse, error) {
+	sessions, err := s.usRepo.FindActiveByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// cek session saat ini dari cookie
+	currentHash := ""
+	if refreshToken != "" {
+		hash := s.utilities.HashToken(refreshToken)
+		for _, us := range sessions {
+			if us.RefreshTokenHash == hash {
+				currentHash = hash
+				break
+			}
+		}
+	}
+
+	result := make([]response.SessionResponse, 0, len(sessions))
+	for _, us := range sessions {
+		item := sessionResponse(us)
+		if currentHash != "" {
+			item.Current = us.RefreshTokenHash == currentHash
+		} else {
+			item.Current = deviceID != "" && us.DeviceID == deviceID
+		}
+		result = append(result, item)
+	}
+
+	return result, nil
+}
+
+// Revoke mengakhiri satu session milik user login
+func (s *userSessionServiceImpl) Revoke(ctx context.Context, userID, sessionID string) error {
+	// cek session milik user dan masih aktif
+	session, err := s.usRepo.FindByID(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	if session.UserID != userID || session.Revoked || session.ExpiresAt.Before(time.Now()) {
+		return apperror.New("[SESSION_NOT_FOUND]", "session tidak ditemukan", nil, http.StatusNotFound)
+	}
+
+	return s.usRepo.Revoked(ctx, session.ID)
+}
+
+// ListByUser session aktif user untuk admin, user dicari tanpa filter role agar akun admin juga bisa diperiksa
+func (s *userSessionServiceImpl) ListByUser(ctx context.Context, userID string) ([]response.SessionResponse, error) {
+	// cek user
+	if _, err := s.authRepo.FindByID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	sessions, err := s.usRepo.FindActiveByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]response.SessionResponse, 0, len(sessions))
+	for _, us := range sessions {
+		result = append(result, sessionResponse(us))
+	}
+
+	return result, nil
+}
+
+// ManageTarget memeriksa user tujuan sebelum admin mengakhiri session atau memaksa logout.
+// User dicari tanpa filter role agar akun admin yang disusupi tetap bisa ditangani,
+// tetapi session super admin hanya boleh diakhiri oleh super admin
+func (s *userSessionServiceImpl) ManageTarget(ctx context.Context, actorRoles []string, userID string) error {
+	// cek user
+	user, err := s.authRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	// cek role
+	if slices.Contains(actorRoles, "super admin") {
+		return nil
+	}
+	for _, r := range user.Roles {
+		if r.Name == "super admin" {
+			return apperror.New(apperror.CodeForbidden, "hanya super admin yang dapat mengakhiri session super admin", nil, http.StatusForbidden)
+		}
+	}
+
+	return nil
+}
+
+// Search session semua user untuk investigasi insiden, filter kosong diabaikan
+func (s *userSessionServiceImpl) Search(ctx context.Context, userID, ipAddress, status string, limit, offset int) ([]response.SessionAdminResponse, int, error) {
+	// cek status
+	switch status {
+	case "", model.SessionStatusActive, model.SessionStatusExpired, model.SessionStatusRevoked:
+	default:
+		return nil, 0, apperror.New(apperror.CodeBadRequest, "status harus active, expired atau revoked", nil)
+	}
+
+	sessions, total, err := s.usRepo.Search(ctx, userID, ipAddress, status, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	now := time.Now()
+	result := make([]response.SessionAdminResponse, 0, len(sessions))
+	for _, us := range sessions {
+		item := response.SessionAdminResponse{
+			SessionResponse: sessionResponse(us),
+			UserID:          us.UserID,
+			Status:          model.SessionStatusActive,
+		}
+		if us.User != nil {
+			item.Username = us.User.Username
+			item.Email = us.User.Email
+		}
+		if us.Revoked {
+			item.Status = model.SessionStatusRevoked
+		} else if !us.ExpiresAt.After(now) {
+			item.Status = model.SessionStatusExpired
+		}
+		result = append(result, item)
+	}
+
+	return result, total, nil
+}
+
+// sessionPolicy kebijakan umur session user. Role yang punya kebijakan sendiri menggantikan profil default
+// dan remember me, user dengan beberapa role tersebut memakai batas yang paling pendek
+func sessionPolicy(cfg configs.SessionConfig, roles []model.RoleModel, rememberMe bool) configs.SessionPolicy {
+	var (
+		policy  configs.SessionPolicy
+		matched bool
+	)
+	for _, r := range roles {
+		override, ok := cfg.Roles[r.Name]
+		if !ok {
+			continue
+		}
+		if !matched || override.IdleTimeout < policy.IdleTimeout {
+			policy.IdleTimeout = override.IdleTimeout
+		}
+		if !matched || override.AbsoluteLifetime < policy.AbsoluteLifetime {
+			policy.AbsoluteLifetime = override.AbsoluteLifetime
+		}
+		matched = true
+	}
+	if matched {
+		return policy
+	}
+
+	if rememberMe {
+		return cfg.RememberMe
+	}
+	return cfg.Default
+}
+
+// sessionExpiresAt refresh token berlaku selama idle timeout tetapi tidak melewati umur maksimal sejak login
+func sessionExpiresAt(policy configs.SessionPolicy, loginAt, now time.Time) time.Time {
+	expiresAt := now.Add(policy.IdleTimeout)
+	if limit := loginAt.Add(policy.AbsoluteLifetime); limit.Before(expiresAt) {
+		return limit
+	}
+
+	return expiresAt
+}
+
+func sessionResponse(us model.UserSession) response.SessionResponse {
+	ua := device.ParseUserAgent(us.UserAgent)
+	item := response.SessionResponse{
+		ID:         us.ID,
+		Device:     ua.String(),
+		Browser:    ua.Browser,
+		OS:         ua.OS,
+		DeviceType: ua.DeviceType,
+		IPAddress:  us.IPAddress,
+		UserAgent:  us.UserAgent,
+		CreatedAt:  us.CreatedAt,
+		LastUsedAt: us.LastUsedAt,
+		ExpiresAt:  us.ExpiresAt,
+	}
+	if us.Device != nil {
+		item.DeviceID = &us.Device.ID
+		item.DeviceName = us.Device.Name
+	}
+
+	return item
+}
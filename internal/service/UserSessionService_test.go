@@ -0,0 +1,522 @@
+package service
+
+import (
+	"context"
+	"github.com/gogaruda/apperror"
+	"github.com/irawankilmer/auth-service/internal/configs"
+	"github.com/irawankilmer/auth-service/internal/dto/request"
+	"github.com/irawankilmer/auth-service/internal/dto/response"
+	"github.com/irawankilmer/auth-service/internal/model"
+	"github.com/irawankilmer/auth-service/internal/repository"
+	"github.com/irawankilmer/auth-service/pkg/tokencache"
+	"github.com/irawankilmer/auth-service/pkg/utils"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeUserSessionRepo menyimpan user_sessions di memori dengan aturan revoke yang sama seperti query database
+type fakeUserSessionRepo struct {
+	repository.UserSessionRepository
+	sessions map[string]*model.UserSession
+	users    map[string]*model.UserModel
+}
+
+func (r *fakeUserSessionRepo) Create(_ context.Context, data *model.UserSession) error {
+	copied := *data
+	if copied.LastUsedAt.IsZero() {
+		copied.LastUsedAt = time.Now()
+	}
+	r.sessions[data.ID] = &copied
+	return nil
+}
+
+func (r *fakeUserSessionRepo) FindRefreshToken(_ context.Context, hashed string) (*model.UserSession, error) {
+	for _, session := range r.sessions {
+		if session.RefreshTokenHash == hashed {
+			copied := *session
+			return &copied, nil
+		}
+	}
+
+	return nil, apperror.New("[REFRESH_TOKEN_NOT_FOUND]", "refresh token tidak ditemukan", nil)
+}
+
+func (r *fakeUserSessionRepo) GetTokenVersionByUserID(_ context.Context, userID string) (*model.UserModel, error) {
+	user, ok := r.users[userID]
+	if !ok {
+		return nil, apperror.New(apperror.CodeUserNotFound, "user tidak ditemukan", nil)
+	}
+
+	copied := *user
+	return &copied, nil
+}
+
+// FindActiveByUserID diurutkan berdasarkan ID agar hasil test stabil
+func (r *fakeUserSessionRepo) FindActiveByUserID(_ context.Context, userID string) ([]model.UserSession, error) {
+	var sessions []model.UserSession
+	for _, session := range r.sessions {
+		if session.UserID == userID && !session.Revoked && session.ExpiresAt.After(time.Now()) {
+			sessions = append(sessions, *session)
+		}
+	}
+	slices.SortFunc(sessions, func(a, b model.UserSession) int { return strings.Compare(a.ID, b.ID) })
+
+	return sessions, nil
+}
+
+func (r *fakeUserSessionRepo) Revoked(_ context.Context, usID string) error {
+	r.sessions[usID].Revoked = true
+	return nil
+}
+
+func (r *fakeUserSessionRepo) Rotate(_ context.Context, usID string) (bool, error) {
+	session := r.sessions[usID]
+	if session.Revoked {
+		return false, nil
+	}
+
+	now := time.Now()
+	session.Revoked, session.RotatedAt = true, &now
+	return true, nil
+}
+
+func (r *fakeUserSessionRepo) FindByID(_ context.Context, id string) (*model.UserSession, error) {
+	session, ok := r.sessions[id]
+	if !ok {
+		return nil, apperror.New("[SESSION_NOT_FOUND]", "session tidak ditemukan", nil)
+	}
+
+	copied := *session
+	return &copied, nil
+}
+
+func (r *fakeUserSessionRepo) RevokeFamily(_ context.Context, familyID string) (bool, error) {
+	revoked := false
+	for _, session := range r.sessions {
+		if (session.FamilyID == familyID || session.ID == familyID) && !session.Revoked {
+			session.Revoked, revoked = true, true
+		}
+	}
+
+	return revoked, nil
+}
+
+func TestManageTarget(t *testing.T) {
+	authRepo := &fakeAuthRepo{users: map[string]*model.UserModel{
+		"user":  {ID: "user", Roles: []model.RoleModel{{Name: "user"}}},
+		"admin": {ID: "admin", Roles: []model.RoleModel{{Name: "admin"}}},
+		"super": {ID: "super", Roles: []model.RoleModel{{Name: "super admin"}}},
+	}}
+	s := &userSessionServiceImpl{authRepo: authRepo}
+
+	tests := []struct {
+		name     string
+		actor    []string
+		target   string
+		wantCode string
+	}{
+		{name: "admin ke user", actor: []string{"admin"}, target: "user"},
+		{name: "admin ke admin", actor: []string{"admin"}, target: "admin"},
+		{name: "admin ke super admin", actor: []string{"admin"}, target: "super", wantCode: apperror.CodeForbidden},
+		{name: "super admin ke super admin", actor: []string{"super admin"}, target: "super"},
+		{name: "user tidak ada", actor: []string{"super admin"}, target: "hilang", wantCode: apperror.CodeUserNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.ManageTarget(context.Background(), tt.actor, tt.target)
+			if tt.wantCode == "" && err != nil {
+				t.Errorf("err = %v, ingin diizinkan", err)
+			}
+			if tt.wantCode != "" && !apperror.Is(err, tt.wantCode) {
+				t.Errorf("err = %v, ingin %s", err, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestRevokeByLink(t *testing.T) {
+	cfg := &configs.AppConfig{
+		JWT:         configs.JWTConfig{Secret: "jwt-secret"},
+		LoginNotify: configs.LoginNotifyConfig{RevokeLinkTTL: time.Hour},
+	}
+	utility, err := utils.NewUtility(cfg, newTestBcrypt(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authRepo := &fakeAuthRepo{users: map[string]*model.UserModel{"u1": {ID: "u1", TokenVersion: "v1"}}}
+	usRepo := &fakeUserSessionRepo{sessions: map[string]*model.UserSession{
+		// s1 sudah dirotasi menjadi s2, s3 login lain
+		"s1": {ID: "s1", UserID: "u1", FamilyID: "s1", Revoked: true},
+		"s2": {ID: "s2", UserID: "u1", FamilyID: "s1"},
+		"s3": {ID: "s3", UserID: "u1", FamilyID: "s3"},
+		"s4": {ID: "s4", UserID: "u2", FamilyID: "s4"},
+	}}
+	cache := tokencache.New(time.Minute, 10)
+	s := &userSessionServiceImpl{usRepo: usRepo, authRepo: authRepo, utilities: utility, cfg: cfg, tokenCache: cache}
+
+	link := func(userID, sessionID string) string {
+		token, err := utility.SessionRevokeGenerate(userID, sessionID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	first := link("u1", "s1")
+
+	// request sebelumnya menyimpan token version ke cache
+	cache.Set("u1", "v1", cache.Generation())
+
+	if err := s.RevokeByLink(context.Background(), request.SessionRevokeLinkRequest{Token: first}); err != nil {
+		t.Fatal(err)
+	}
+	if !usRepo.sessions["s2"].Revoked || usRepo.sessions["s3"].Revoked {
+		t.Error("hanya session dalam family link yang di-revoke")
+	}
+	if authRepo.users["u1"].TokenVersion == "v1" {
+		t.Error("token version tidak diganti, access token lama masih berlaku")
+	}
+	if _, ok := cache.Get("u1"); ok {
+		t.Error("cache token version tidak di-invalidate")
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "link dipakai ulang", token: first},
+		{name: "session milik user lain", token: link("u1", "s4")},
+		{name: "token rusak", token: first + "x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			version := authRepo.users["u1"].TokenVersion
+			err := s.RevokeByLink(context.Background(), request.SessionRevokeLinkRequest{Token: tt.token})
+			if !apperror.Is(err, "[REVOKE_TOKEN_INVALID]") {
+				t.Errorf("err = %v, ingin [REVOKE_TOKEN_INVALID]", err)
+			}
+			if authRepo.users["u1"].TokenVersion != version {
+				t.Error("token version diganti oleh link yang tidak valid")
+			}
+		})
+	}
+}
+
+type fakeDeviceService struct {
+	DeviceService
+}
+
+func (fakeDeviceService) Touch(context.Context, string, string, string, string, bool) error {
+	return nil
+}
+
+// fakeSecurityEventService mencatat session yang dilaporkan sebagai refresh token reuse
+type fakeSecurityEventService struct {
+	reused []string
+}
+
+func (f *fakeSecurityEventService) RefreshTokenReused(_ context.Context, session *model.UserSession, _, _ string) error {
+	f.reused = append(f.reused, session.ID)
+	return nil
+}
+
+type userSessionTest struct {
+	s        *userSessionServiceImpl
+	usRepo   *fakeUserSessionRepo
+	authRepo *fakeAuthRepo
+	security *fakeSecurityEventService
+	cache    tokencache.Cache
+	utility  utils.Utility
+}
+
+// newUserSessionTest user u1 tanpa role khusus dan admin dengan kebijakan session lebih ketat
+func newUserSessionTest(t *testing.T) *userSessionTest {
+	t.Helper()
+
+	cfg := &configs.AppConfig{
+		JWT: configs.JWTConfig{Secret: "jwt-secret", AccessTokenTTL: 15 * time.Minute},
+		Session: configs.SessionConfig{
+			ReuseGrace: 30 * time.Second,
+			Default:    configs.SessionPolicy{IdleTimeout: 24 * time.Hour, AbsoluteLifetime: 168 * time.Hour},
+			RememberMe: configs.SessionPolicy{IdleTimeout: 720 * time.Hour, AbsoluteLifetime: 2160 * time.Hour},
+			Roles:      map[string]configs.SessionPolicy{"admin": {IdleTimeout: time.Hour, AbsoluteLifetime: 8 * time.Hour}},
+		},
+	}
+	utility, err := utils.NewUtility(cfg, newTestBcrypt(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	users := map[string]*model.UserModel{
+		"u1":    {ID: "u1", TokenVersion: "v1", EmailVerified: true},
+		"admin": {ID: "admin", TokenVersion: "v1", EmailVerified: true, Roles: []model.RoleModel{{Name: "admin"}}},
+	}
+	usRepo := &fakeUserSessionRepo{sessions: map[string]*model.UserSession{}, users: users}
+	authRepo := &fakeAuthRepo{users: users}
+	security := &fakeSecurityEventService{}
+	cache := tokencache.New(time.Minute, 10)
+
+	return &userSessionTest{
+		s: &userSessionServiceImpl{
+			usRepo: usRepo, authRepo: authRepo, utilities: utility, cfg: cfg,
+			deviceService: fakeDeviceService{}, securityService: security, tokenCache: cache,
+		},
+		usRepo: usRepo, authRepo: authRepo, security: security, cache: cache, utility: utility,
+	}
+}
+
+// addSession session dengan refresh token "rt-<id>"
+func (tt *userSessionTest) addSession(session model.UserSession) string {
+	token := "rt-" + session.ID
+	session.RefreshTokenHash = tt.utility.HashToken(token)
+	if session.FamilyID == "" {
+		session.FamilyID = session.ID
+	}
+	if session.ExpiresAt.IsZero() {
+		session.ExpiresAt = time.Now().Add(time.Hour)
+	}
+	tt.usRepo.sessions[session.ID] = &session
+
+	return token
+}
+
+func (tt *userSessionTest) refresh(token string) (*response.LoginResponse, error) {
+	return tt.s.Refresh(context.Background(), token, "", "10.0.0.1", "test-agent")
+}
+
+func TestRefreshRotation(t *testing.T) {
+	tt := newUserSessionTest(t)
+	loginAt := time.Now().Add(-time.Hour)
+	token := tt.addSession(model.UserSession{ID: "s1", UserID: "u1", CreatedAt: loginAt, LastUsedAt: loginAt})
+
+	// refresh dua kali berturut-turut, tiap token hasil rotasi tetap satu family
+	parentID := "s1"
+	for i := 1; i <= 2; i++ {
+		res, err := tt.refresh(token)
+		if err != nil {
+			t.Fatalf("refresh ke-%d: %v", i, err)
+		}
+
+		parent := tt.usRepo.sessions[parentID]
+		if !parent.Revoked || parent.RotatedAt == nil {
+			t.Fatalf("refresh ke-%d: session lama = %+v, ingin revoked karena rotasi", i, parent)
+		}
+
+		next, err := tt.usRepo.FindRefreshToken(context.Background(), tt.utility.HashToken(res.RefreshToken))
+		if err != nil {
+			t.Fatalf("refresh ke-%d: session baru tidak tersimpan: %v", i, err)
+		}
+		if next.FamilyID != "s1" || next.ParentID == nil || *next.ParentID != parentID || next.Revoked {
+			t.Errorf("refresh ke-%d: session baru = %+v, ingin family s1 dengan parent %s", i, next, parentID)
+		}
+		if !next.CreatedAt.Equal(loginAt) {
+			t.Errorf("refresh ke-%d: created_at = %v, ingin tetap waktu login %v", i, next.CreatedAt, loginAt)
+		}
+
+		token, parentID = res.RefreshToken, next.ID
+	}
+}
+
+func TestRefreshTokenReuse(t *testing.T) {
+	tests := []struct {
+		name        string
+		rotatedAgo  time.Duration
+		logout      bool
+		wantCode    string
+		wantRevoked bool
+	}{
+		{name: "dipakai ulang dalam grace window", rotatedAgo: 5 * time.Second, wantCode: "[REFRESH_TOKEN_INVALID]"},
+		{name: "dipakai ulang setelah grace window", rotatedAgo: time.Minute, wantCode: "[REFRESH_TOKEN_REUSED]", wantRevoked: true},
+		{name: "revoked karena logout", logout: true, wantCode: "[REFRESH_TOKEN_INVALID]"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tt := newUserSessionTest(t)
+			now := time.Now()
+			var rotatedAt *time.Time
+			if !tc.logout {
+				at := now.Add(-tc.rotatedAgo)
+				rotatedAt = &at
+			}
+
+			// s1 sudah dirotasi menjadi s2, s3 login lain milik user yang sama
+			reused := tt.addSession(model.UserSession{ID: "s1", UserID: "u1", Revoked: true, RotatedAt: rotatedAt, CreatedAt: now, LastUsedAt: now})
+			tt.addSession(model.UserSession{ID: "s2", UserID: "u1", FamilyID: "s1", CreatedAt: now, LastUsedAt: now})
+			tt.addSession(model.UserSession{ID: "s3", UserID: "u1", CreatedAt: now, LastUsedAt: now})
+			tt.cache.Set("u1", "v1", tt.cache.Generation())
+
+			if _, err := tt.refresh(reused); !apperror.Is(err, tc.wantCode) {
+				t.Fatalf("err = %v, ingin %s", err, tc.wantCode)
+			}
+
+			if got := tt.usRepo.sessions["s2"].Revoked; got != tc.wantRevoked {
+				t.Errorf("session family revoked = %v, ingin %v", got, tc.wantRevoked)
+			}
+			if tt.usRepo.sessions["s3"].Revoked {
+				t.Error("session family lain ikut di-revoke")
+			}
+			if got := tt.authRepo.users["u1"].TokenVersion != "v1"; got != tc.wantRevoked {
+				t.Errorf("token version diganti = %v, ingin %v", got, tc.wantRevoked)
+			}
+			if _, cached := tt.cache.Get("u1"); cached == tc.wantRevoked {
+				t.Errorf("token version masih di cache = %v, ingin %v", cached, !tc.wantRevoked)
+			}
+			if got := len(tt.security.reused) == 1; got != tc.wantRevoked {
+				t.Errorf("security event dicatat = %v, ingin %v", got, tc.wantRevoked)
+			}
+		})
+	}
+}
+
+func TestRefreshSessionExpiry(t *testing.T) {
+	tests := []struct {
+		name          string
+		userID        string
+		rememberMe    bool
+		loginAgo      time.Duration
+		idle          time.Duration
+		expired       bool
+		wantCode      string
+		wantExpiresIn time.Duration
+	}{
+		{name: "masih aktif", userID: "u1", loginAgo: time.Hour, idle: time.Hour, wantExpiresIn: 24 * time.Hour},
+		{name: "idle timeout", userID: "u1", loginAgo: 48 * time.Hour, idle: 25 * time.Hour, wantCode: "[SESSION_EXPIRED]"},
+		{name: "umur maksimal", userID: "u1", loginAgo: 169 * time.Hour, idle: time.Hour, wantCode: "[SESSION_EXPIRED]"},
+		{name: "mendekati umur maksimal", userID: "u1", loginAgo: 167 * time.Hour, idle: time.Hour, wantExpiresIn: time.Hour},
+		{name: "remember me lebih panjang", userID: "u1", rememberMe: true, loginAgo: 169 * time.Hour, idle: 25 * time.Hour, wantExpiresIn: 720 * time.Hour},
+		{name: "role admin lebih ketat", userID: "admin", loginAgo: 2 * time.Hour, idle: 2 * time.Hour, wantCode: "[SESSION_EXPIRED]"},
+		{name: "refresh token kadaluwarsa", userID: "u1", loginAgo: time.Hour, idle: time.Hour, expired: true, wantCode: "[REFRESH_TOKEN_INVALID]"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tt := newUserSessionTest(t)
+			now := time.Now()
+			session := model.UserSession{
+				ID: "s1", UserID: tc.userID, RememberMe: tc.rememberMe,
+				CreatedAt: now.Add(-tc.loginAgo), LastUsedAt: now.Add(-tc.idle),
+			}
+			if tc.expired {
+				session.ExpiresAt = now.Add(-time.Second)
+			}
+			token := tt.addSession(session)
+
+			res, err := tt.refresh(token)
+			if tc.wantCode != "" {
+				if !apperror.Is(err, tc.wantCode) {
+					t.Fatalf("err = %v, ingin %s", err, tc.wantCode)
+				}
+				if tt.usRepo.sessions["s1"].Revoked || len(tt.usRepo.sessions) != 1 {
+					t.Error("session berakhir tetap dirotasi")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := time.Duration(res.RefreshExpiresIn) * time.Second; got < tc.wantExpiresIn-time.Minute || got > tc.wantExpiresIn {
+				t.Errorf("refresh token berlaku %v, ingin %v", got, tc.wantExpiresIn)
+			}
+		})
+	}
+}
+
+func TestListSessions(t *testing.T) {
+	const deviceA, deviceB = "0123456789abcdef0123456789abcdef", "fedcba9876543210fedcba9876543210"
+
+	tests := []struct {
+		name         string
+		refreshToken string
+		deviceID     string
+		wantCurrent  string
+	}{
+		{name: "dikenali dari cookie refresh token", refreshToken: "rt-s2", deviceID: deviceA, wantCurrent: "s2"},
+		{name: "tanpa cookie dikenali dari device ID", deviceID: deviceA, wantCurrent: "s1"},
+		{name: "cookie milik session lain memakai device ID", refreshToken: "rt-s4", deviceID: deviceB, wantCurrent: "s2"},
+		{name: "tidak dikenali"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tt := newUserSessionTest(t)
+			now := time.Now()
+			tt.addSession(model.UserSession{ID: "s1", UserID: "u1", DeviceID: deviceA, CreatedAt: now})
+			tt.addSession(model.UserSession{ID: "s2", UserID: "u1", DeviceID: deviceB, CreatedAt: now})
+			tt.addSession(model.UserSession{ID: "s3", UserID: "u1", Revoked: true, CreatedAt: now})
+			tt.addSession(model.UserSession{ID: "s4", UserID: "u2", DeviceID: deviceA, CreatedAt: now})
+			tt.addSession(model.UserSession{ID: "s5", UserID: "u1", ExpiresAt: now.Add(-time.Second), CreatedAt: now})
+
+			sessions, err := tt.s.List(context.Background(), "u1", tc.refreshToken, tc.deviceID)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var ids []string
+			current := ""
+			for _, session := range sessions {
+				ids = append(ids, session.ID)
+				if session.Current {
+					if current != "" {
+						t.Errorf("lebih dari satu session saat ini: %s dan %s", current, session.ID)
+					}
+					current = session.ID
+				}
+			}
+			if !slices.Equal(ids, []string{"s1", "s2"}) {
+				t.Errorf("session = %v, ingin hanya session aktif milik user [s1 s2]", ids)
+			}
+			if current != tc.wantCurrent {
+				t.Errorf("session saat ini = %q, ingin %q", current, tc.wantCurrent)
+			}
+		})
+	}
+}
+
+func TestRevokeSession(t *testing.T) {
+	tests := []struct {
+		name      string
+		sessionID string
+		wantCode  string
+	}{
+		{name: "session sendiri", sessionID: "s1"},
+		{name: "session user lain", sessionID: "s2", wantCode: "[SESSION_NOT_FOUND]"},
+		{name: "sudah revoked", sessionID: "s3", wantCode: "[SESSION_NOT_FOUND]"},
+		{name: "sudah kadaluwarsa", sessionID: "s4", wantCode: "[SESSION_NOT_FOUND]"},
+		{name: "tidak ada", sessionID: "hilang", wantCode: "[SESSION_NOT_FOUND]"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tt := newUserSessionTest(t)
+			now := time.Now()
+			tt.addSession(model.UserSession{ID: "s1", UserID: "u1", CreatedAt: now})
+			tt.addSession(model.UserSession{ID: "s2", UserID: "u2", CreatedAt: now})
+			tt.addSession(model.UserSession{ID: "s3", UserID: "u1", Revoked: true, CreatedAt: now})
+			tt.addSession(model.UserSession{ID: "s4", UserID: "u1", ExpiresAt: now.Add(-time.Second), CreatedAt: now})
+
+			err := tt.s.Revoke(context.Background(), "u1", tc.sessionID)
+			if tc.wantCode == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !tt.usRepo.sessions[tc.sessionID].Revoked {
+					t.Error("session tidak di-revoke")
+				}
+				return
+			}
+
+			if !apperror.Is(err, tc.wantCode) {
+				t.Errorf("err = %v, ingin %s", err, tc.wantCode)
+			}
+			if tt.usRepo.sessions["s2"].Revoked {
+				t.Error("session user lain ikut di-revoke")
+			}
+		})
+	}
+}
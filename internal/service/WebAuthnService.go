@@ -0,0 +1,381 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/gogaruda/apperror"
+	"github.com/irawankilmer/auth-service/internal/configs"
+	"github.com/irawankilmer/auth-service/internal/dto/request"
+	"github.com/irawankilmer/auth-service/internal/dto/response"
+	"github.com/irawankilmer/auth-service/internal/model"
+	"github.com/irawankilmer/auth-service/internal/repository"
+	"github.com/irawankilmer/auth-service/pkg/utils"
+	"net/http"
+)
+
+type WebAuthnService interface {
+	BeginRegistration(ctx context.Context, userID string) (*response.PasskeyBeginResponse, error)
+	FinishRegistration(ctx context.Context, userID string, req request.PasskeyRegisterFinishRequest) (*response.PasskeyResponse, error)
+	List(ctx context.Context, userID string) ([]response.PasskeyResponse, error)
+	Rename(ctx context.Context, userID, id, name string) error
+	Delete(ctx context.Context, userID, id string) error
+	BeginLogin(ctx context.Context) (*response.PasskeyBeginResponse, error)
+	FinishLogin(ctx context.Context, req request.PasskeyLoginFinishRequest, check func(user *model.UserModel, credentialKey string) error) (*model.UserModel, string, error)
+	BeginVerify(ctx context.Context, user *model.UserModel) (*response.PasskeyBeginResponse, error)
+	FinishVerify(ctx context.Context, user *model.UserModel, sessionID string, credential json.RawMessage) error
+}
+
+// jenis ceremony yang disimpan di webauthn_sessions
+const (
+	ceremonyRegistration = "registration"
+	ceremonyLogin        = "login"
+	ceremonyMFA          = "mfa"
+)
+
+type webAuthnService struct {
+	waRepo   repository.WebAuthnRepository
+	authRepo repository.AuthRepository
+	wa       *webauthn.WebAuthn
+	utility  utils.Utility
+	cfg      *configs.AppConfig
+}
+
+func NewWebAuthnService(wr repository.WebAuthnRepository, ar repository.AuthRepository, wa *webauthn.WebAuthn, ut utils.Utility, cfg *configs.AppConfig) WebAuthnService {
+	return &webAuthnService{waRepo: wr, authRepo: ar, wa: wa, utility: ut, cfg: cfg}
+}
+
+// webauthnUser menghubungkan UserModel dengan interface webauthn.User
+type webauthnUser struct {
+	user        *model.UserModel
+	credentials []webauthn.Credential
+	ids         map[string]string
+}
+
+func (u *webauthnUser) WebAuthnID() []byte {
+	return []byte(u.user.ID)
+}
+
+func (u *webauthnUser) WebAuthnName() string {
+	if u.user.Username != nil {
+		return *u.user.Username
+	}
+
+	return u.user.Email
+}
+
+func (u *webauthnUser) WebAuthnDisplayName() string {
+	return u.user.Email
+}
+
+func (u *webauthnUser) WebAuthnCredentials() []webauthn.Credential {
+	return u.credentials
+}
+
+func (s *webAuthnService) BeginRegistration(ctx context.Context, userID string) (*response.PasskeyBeginResponse, error) {
+	// cek user
+	user, err := s.loadUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// passkey harus discoverable agar bisa dipakai login tanpa username
+	creation, session, err := s.wa.BeginRegistration(user,
+		webauthn.WithAuthenticatorSelection(protocol.AuthenticatorSelection{
+			RequireResidentKey: protocol.ResidentKeyRequired(),
+			ResidentKey:        protocol.ResidentKeyRequirementRequired,
+			UserVerification:   protocol.VerificationRequired,
+		}),
+		webauthn.WithConveyancePreference(protocol.PreferNoAttestation),
+		webauthn.WithExclusions(webauthn.Credentials(user.credentials).CredentialDescriptors()),
+	)
+	if err != nil {
+		return nil, apperror.New(apperror.CodeInternalError, "gagal memulai registrasi passkey", err)
+	}
+
+	return s.saveSession(ctx, &user.user.ID, ceremonyRegistration, session, creation)
+}
+
+func (s *webAuthnService) FinishRegistration(ctx context.Context, userID string, req request.PasskeyRegisterFinishRequest) (*response.PasskeyResponse, error) {
+	// ambil session ceremony
+	session, err := s.takeSession(ctx, req.SessionID, ceremonyRegistration, &userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// cek user
+	user, err := s.loadUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// verifikasi respon authenticator
+	parsed, err := protocol.ParseCredentialCreationResponseBytes(req.Credential)
+	if err != nil {
+		return nil, webauthnError(err)
+	}
+	credential, err := s.wa.CreateCredential(user, *session, parsed)
+	if err != nil {
+		return nil, webauthnError(err)
+	}
+
+	// simpan credential
+	data, err := json.Marshal(credential)
+	if err != nil {
+		return nil, apperror.New(apperror.CodeInternalError, "encode credential gagal", err)
+	}
+	passkey := model.WebAuthnCredentialModel{
+		ID:             s.utility.ULIDGenerate(),
+		UserID:         userID,
+		CredentialID:   credential.ID,
+		Name:           req.Name,
+		SignCount:      credential.Authenticator.SignCount,
+		CredentialData: data,
+		CreatedAt:      s.utility.Now(),
+	}
+	if err := s.waRepo.CreateCredential(ctx, &passkey); err != nil {
+		return nil, err
+	}
+
+	return toPasskeyResponse(passkey), nil
+}
+
+func (s *webAuthnService) List(ctx context.Context, userID string) ([]response.PasskeyResponse, error) {
+	credentials, err := s.waRepo.FindCredentialsByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	passkeys := make([]response.PasskeyResponse, 0, len(credentials))
+	for _, credential := range credentials {
+		passkeys = append(passkeys, *toPasskeyResponse(credential))
+	}
+
+	return passkeys, nil
+}
+
+func (s *webAuthnService) Rename(ctx context.Context, userID, id, name string) error {
+	return s.waRepo.RenameCredential(ctx, userID, id, name)
+}
+
+func (s *webAuthnService) Delete(ctx context.Context, userID, id string) error {
+	// cek sisa metode login
+	user, err := s.authRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if loginMethodCount(user) <= 1 {
+		return lastLoginMethodError()
+	}
+
+	return s.waRepo.DeleteCredential(ctx, userID, id)
+}
+
+func (s *webAuthnService) BeginLogin(ctx context.Context) (*response.PasskeyBeginResponse, error) {
+	// login tanpa username, user diketahui dari user handle passkey
+	assertion, session, err := s.wa.BeginDiscoverableLogin(webauthn.WithUserVerification(protocol.VerificationRequired))
+	if err != nil {
+		return nil, apperror.New(apperror.CodeInternalError, "gagal memulai login passkey", err)
+	}
+
+	return s.saveSession(ctx, nil, ceremonyLogin, session, assertion)
+}
+
+// FinishLogin memverifikasi passkey login. User dicari dari user handle lalu diperiksa lewat check sebelum
+// tanda tangan diverifikasi. User handle dikirim client sehingga kegagalan tidak boleh dicatat ke user tersebut,
+// credentialKey (hash credential ID) dikembalikan bersama error verifikasi agar kegagalan dicatat per credential+IP
+func (s *webAuthnService) FinishLogin(ctx context.Context, req request.PasskeyLoginFinishRequest, check func(user *model.UserModel, credentialKey string) error) (*model.UserModel, string, error) {
+	// ambil session ceremony
+	session, err := s.takeSession(ctx, req.SessionID, ceremonyLogin, nil)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// baca respon authenticator
+	parsed, err := protocol.ParseCredentialRequestResponseBytes(req.Credential)
+	if err != nil {
+		return nil, "", webauthnError(err)
+	}
+	if len(parsed.Response.UserHandle) == 0 {
+		return nil, "", webauthnError(errors.New("user handle kosong"))
+	}
+	credentialKey := s.utility.HashToken(parsed.ID)
+
+	// cek user dari user handle sebelum verifikasi
+	user, err := s.loadUser(ctx, string(parsed.Response.UserHandle))
+	if err != nil {
+		return nil, "", err
+	}
+	if err := check(user.user, credentialKey); err != nil {
+		return nil, "", err
+	}
+
+	// verifikasi respon authenticator
+	_, credential, err := s.wa.ValidatePasskeyLogin(func(rawID, userHandle []byte) (webauthn.User, error) {
+		return user, nil
+	}, *session, parsed)
+	if err != nil {
+		return nil, credentialKey, webauthnError(err)
+	}
+
+	// simpan sign count terbaru, credential yang bukan milik user juga dihitung gagal
+	if err := s.updateUsage(ctx, user, credential); err != nil {
+		return nil, credentialKey, err
+	}
+
+	return user.user, credentialKey, nil
+}
+
+func (s *webAuthnService) BeginVerify(ctx context.Context, user *model.UserModel) (*response.PasskeyBeginResponse, error) {
+	// cek passkey user
+	wu, err := s.loadUser(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(wu.credentials) == 0 {
+		return nil, apperror.New("[PASSKEY_NOT_FOUND]", "user belum mendaftarkan passkey", nil, http.StatusBadRequest)
+	}
+
+	assertion, session, err := s.wa.BeginLogin(wu)
+	if err != nil {
+		return nil, apperror.New(apperror.CodeInternalError, "gagal memulai verifikasi passkey", err)
+	}
+
+	return s.saveSession(ctx, &user.ID, ceremonyMFA, session, assertion)
+}
+
+func (s *webAuthnService) FinishVerify(ctx context.Context, user *model.UserModel, sessionID string, credential json.RawMessage) error {
+	// ambil session ceremony
+	session, err := s.takeSession(ctx, sessionID, ceremonyMFA, &user.ID)
+	if err != nil {
+		return err
+	}
+
+	// cek passkey user
+	wu, err := s.loadUser(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	// verifikasi respon authenticator
+	parsed, err := protocol.ParseCredentialRequestResponseBytes(credential)
+	if err != nil {
+		return webauthnError(err)
+	}
+	validated, err := s.wa.ValidateLogin(wu, *session, parsed)
+	if err != nil {
+		return webauthnError(err)
+	}
+
+	// simpan sign count terbaru
+	return s.updateUsage(ctx, wu, validated)
+}
+
+// loadUser mengambil user beserta semua passkey miliknya
+func (s *webAuthnService) loadUser(ctx context.Context, userID string) (*webauthnUser, error) {
+	user, err := s.authRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	credentials, err := s.waRepo.FindCredentialsByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	wu := &webauthnUser{user: user, ids: make(map[string]string, len(credentials))}
+	for _, c := range credentials {
+		var credential webauthn.Credential
+		if err := json.Unmarshal(c.CredentialData, &credential); err != nil {
+			return nil, apperror.New(apperror.CodeInternalError, "decode credential gagal", err)
+		}
+
+		// sign count di kolom tabel adalah nilai terbaru
+		credential.Authenticator.SignCount = c.SignCount
+		credential.Authenticator.CloneWarning = c.CloneWarning
+
+		wu.credentials = append(wu.credentials, credential)
+		wu.ids[string(c.CredentialID)] = c.ID
+	}
+
+	return wu, nil
+}
+
+// updateUsage menyimpan sign count terbaru, passkey yang terindikasi di-clone ditolak
+func (s *webAuthnService) updateUsage(ctx context.Context, user *webauthnUser, credential *webauthn.Credential) error {
+	id, ok := user.ids[string(credential.ID)]
+	if !ok {
+		return webauthnError(errors.New("credential tidak ditemukan"))
+	}
+
+	data, err := json.Marshal(credential)
+	if err != nil {
+		return apperror.New(apperror.CodeInternalError, "encode credential gagal", err)
+	}
+	if err := s.waRepo.UpdateCredentialUsage(ctx, id, credential.Authenticator.SignCount, credential.Authenticator.CloneWarning, data); err != nil {
+		return err
+	}
+
+	if credential.Authenticator.CloneWarning {
+		return apperror.New("[WEBAUTHN_CLONE_WARNING]", "passkey terindikasi digandakan, hubungi admin", errors.New("sign count tidak bertambah"), http.StatusUnauthorized)
+	}
+
+	return nil
+}
+
+func (s *webAuthnService) saveSession(ctx context.Context, userID *string, ceremony string, session *webauthn.SessionData, options any) (*response.PasskeyBeginResponse, error) {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return nil, apperror.New(apperror.CodeInternalError, "encode session passkey gagal", err)
+	}
+
+	id := s.utility.ULIDGenerate()
+	if err := s.waRepo.CreateSession(ctx, &model.WebAuthnSessionModel{
+		ID:          id,
+		UserID:      userID,
+		Ceremony:    ceremony,
+		SessionData: data,
+		ExpiresAt:   s.utility.Now().Add(s.cfg.WebAuthn.SessionTTL),
+	}); err != nil {
+		return nil, err
+	}
+
+	return &response.PasskeyBeginResponse{SessionID: id, Options: options}, nil
+}
+
+// takeSession mengambil session ceremony sekali pakai, userID nil untuk ceremony login tanpa username
+func (s *webAuthnService) takeSession(ctx context.Context, id, ceremony string, userID *string) (*webauthn.SessionData, error) {
+	stored, err := s.waRepo.TakeSession(ctx, id, ceremony)
+	if err != nil {
+		return nil, err
+	}
+
+	// session milik user lain tidak boleh dipakai
+	if userID != nil && (stored.UserID == nil || *stored.UserID != *userID) {
+		return nil, apperror.New("[WEBAUTHN_SESSION_INVALID]", "session passkey tidak valid atau sudah kedaluwarsa", nil, http.StatusBadRequest)
+	}
+
+	var session webauthn.SessionData
+	if err := json.Unmarshal(stored.SessionData, &session); err != nil {
+		return nil, apperror.New(apperror.CodeInternalError, "decode session passkey gagal", err)
+	}
+
+	return &session, nil
+}
+
+func webauthnError(err error) error {
+	return apperror.New("[WEBAUTHN_INVALID]", "verifikasi passkey gagal", err, http.StatusUnauthorized)
+}
+
+func toPasskeyResponse(credential model.WebAuthnCredentialModel) *response.PasskeyResponse {
+	return &response.PasskeyResponse{
+		ID:           credential.ID,
+		Name:         credential.Name,
+		SignCount:    credential.SignCount,
+		CloneWarning: credential.CloneWarning,
+		LastUsedAt:   credential.LastUsedAt,
+		CreatedAt:    credential.CreatedAt,
+	}
+}
@@ -2,27 +2,52 @@ package module
 
 import (
 	"database/sql"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
 	"github.com/irawankilmer/auth-service/internal/configs"
 	"github.com/irawankilmer/auth-service/internal/middleware"
 	"github.com/irawankilmer/auth-service/internal/repository"
 	"github.com/irawankilmer/auth-service/internal/service"
+	"github.com/irawankilmer/auth-service/pkg/challenge"
+	"github.com/irawankilmer/auth-service/pkg/identity"
 	"github.com/irawankilmer/auth-service/pkg/mailer"
+	"github.com/irawankilmer/auth-service/pkg/password"
+	"github.com/irawankilmer/auth-service/pkg/ratelimit"
+	"github.com/irawankilmer/auth-service/pkg/storage"
+	"github.com/irawankilmer/auth-service/pkg/tokencache"
 	"github.com/irawankilmer/auth-service/pkg/utils"
+	"log"
 )
 
 type BootstrapApp struct {
-	AuthService service.AuthService
-	Middleware  middleware.Middleware
-	UserService service.UserService
-	EVService   service.EmailVerificationService
-	USService   service.UserSessionService
-	CFG         *configs.AppConfig
+	AuthService     service.AuthService
+	Middleware      middleware.Middleware
+	UserService     service.UserService
+	EVService       service.EmailVerificationService
+	USService       service.UserSessionService
+	MFAService      service.MFAService
+	WAService       service.WebAuthnService
+	IdentityService service.IdentityService
+	ProfileService  service.ProfileService
+	NotifyService   service.LoginNotificationService
+	DeviceService   service.DeviceService
+	Challenger      challenge.Challenger
+	CFG             *configs.AppConfig
 }
 
 func BootstrapInit(db *sql.DB, cfg *configs.AppConfig) *BootstrapApp {
-	utilities := utils.NewUtility(cfg)
+	hasher, err := password.NewHasher(cfg.Hash)
+	if err != nil {
+		log.Fatalf("konfigurasi hash password tidak valid: %v", err)
+	}
+	utilities, err := utils.NewUtility(cfg, hasher)
+	if err != nil {
+		log.Fatalf("inisialisasi utility gagal: %v", err)
+	}
+	tokenCache := tokencache.New(cfg.JWT.VersionCacheTTL, cfg.JWT.VersionCacheSize)
 
 	mail := mailer.NewMailer(cfg.Mail)
+	mailQueue := mailer.NewQueue(cfg.Mail.QueueWorkers, cfg.Mail.QueueSize)
 	authRepo := repository.NewAuthRepository(db)
 	usernameRepo := repository.NewUsernameHistoryRepository(db)
 	emailRepo := repository.NewEmailHistoryRepository(db)
@@ -30,18 +55,82 @@ func BootstrapInit(db *sql.DB, cfg *configs.AppConfig) *BootstrapApp {
 	userRepo := repository.NewUserRepository(db)
 	evRepo := repository.NewEmailVerificationRepository(db)
 	usRepo := repository.NewUserSessionRepository(db)
+	laRepo := repository.NewLoginAttemptRepository(db)
+	mfaRepo := repository.NewMFARepository(db)
+	rcRepo := repository.NewMFARecoveryCodeRepository(db)
+	waRepo := repository.NewWebAuthnRepository(db)
+	identityRepo := repository.NewUserIdentityRepository(db)
+	profileRepo := repository.NewProfileRepository(db)
+	prefRepo := repository.NewNotificationPreferenceRepository(db)
+	deviceRepo := repository.NewUserDeviceRepository(db)
+	eventRepo := repository.NewSecurityEventRepository(db)
+
+	wa, err := webauthn.New(&webauthn.Config{
+		RPID:                  cfg.WebAuthn.RPID,
+		RPDisplayName:         cfg.WebAuthn.RPDisplayName,
+		RPOrigins:             cfg.WebAuthn.RPOrigins,
+		AttestationPreference: protocol.PreferNoAttestation,
+	})
+	if err != nil {
+		log.Fatalf("konfigurasi WebAuthn tidak valid: %v", err)
+	}
+
+	providers := make(map[string]identity.Provider, len(cfg.Identity.Providers))
+	for _, p := range cfg.Identity.Providers {
+		provider, err := identity.NewProvider(p)
+		if err != nil {
+			log.Fatalf("konfigurasi provider login tidak valid: %v", err)
+		}
+		providers[provider.Name()] = provider
+	}
+
+	store, err := storage.New(cfg.Storage)
+	if err != nil {
+		log.Fatalf("konfigurasi storage tidak valid: %v", err)
+	}
+
+	pwPolicy, err := password.NewPolicy(cfg.Password)
+	if err != nil {
+		log.Fatalf("konfigurasi password policy tidak valid: %v", err)
+	}
+
+	laService := service.NewLoginAttemptService(authRepo, laRepo, cfg.Lockout)
+	mfaService := service.NewMFAService(mfaRepo, rcRepo, authRepo, laService, utilities, cfg, mail, mailQueue)
+	waService := service.NewWebAuthnService(waRepo, authRepo, wa, utilities, cfg)
+	identityService := service.NewIdentityService(authRepo, userRepo, roleRepo, emailRepo, identityRepo, providers, utilities, cfg)
+	profileService := service.NewProfileService(profileRepo, store, utilities, cfg.Avatar)
+	evService := service.NewEmailVerificationService(evRepo, mail, utilities, cfg.Mail, userRepo, usernameRepo, pwPolicy)
+	userService := service.NewUserService(userRepo, roleRepo, usernameRepo, emailRepo, utilities, cfg, evService, laService, profileService, tokenCache)
+	notifyService := service.NewLoginNotificationService(usRepo, prefRepo, mail, utilities, cfg)
+	deviceService := service.NewDeviceService(deviceRepo, utilities, cfg.Device)
+	authService := service.NewAuthService(authRepo, utilities, cfg, userRepo, roleRepo, usernameRepo, emailRepo, evService, usRepo, laService, mfaService, waService, profileService, pwPolicy, notifyService, deviceService, tokenCache, mailQueue)
+	securityService := service.NewSecurityEventService(eventRepo, authRepo, mail, utilities, cfg)
+	usService := service.NewUserSessionService(usRepo, authRepo, utilities, cfg, deviceService, securityService, tokenCache)
 
-	evService := service.NewEmailVerificationService(evRepo, mail, utilities, cfg.Mail, userRepo, usernameRepo)
-	userService := service.NewUserService(userRepo, roleRepo, usernameRepo, emailRepo, utilities, cfg, evService)
-	authService := service.NewAuthService(authRepo, utilities, cfg, userRepo, roleRepo, usernameRepo, emailRepo, evService, usRepo)
-	usService := service.NewUserSessionService(usRepo, utilities, cfg)
+	limiter, err := ratelimit.NewStore(cfg.RateLimit)
+	if err != nil {
+		log.Fatalf("konfigurasi rate limit tidak valid: %v", err)
+	}
+
+	challenger, err := challenge.New(cfg.Challenge)
+	if err != nil {
+		log.Fatalf("konfigurasi challenge tidak valid: %v", err)
+	}
 
-	middlewares := middleware.NewMiddleware(cfg, userRepo)
+	middlewares := middleware.NewMiddleware(cfg, userRepo, limiter, challenger, tokenCache)
 	return &BootstrapApp{
-		AuthService: authService,
-		Middleware:  middlewares,
-		UserService: userService,
-		EVService:   evService,
-		USService:   usService,
+		AuthService:     authService,
+		Middleware:      middlewares,
+		UserService:     userService,
+		EVService:       evService,
+		USService:       usService,
+		MFAService:      mfaService,
+		WAService:       waService,
+		IdentityService: identityService,
+		ProfileService:  profileService,
+		NotifyService:   notifyService,
+		DeviceService:   deviceService,
+		Challenger:      challenger,
+		CFG:             cfg,
 	}
 }
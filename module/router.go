@@ -12,42 +12,144 @@ func AuthRouteRegister(r *gin.Engine, app *BootstrapApp) {
 
 	authHandler := handler.NewAuthHandler(app.AuthService, v, app.UserService, app.CFG)
 	userHandler := handler.NewUserHandler(app.UserService, v)
-	emailVerifyHandler := handler.NewEmailVerificationHandler(app.EVService, v)
-	uSessionHandler := handler.NewUserSessionHandler(app.USService)
+	emailVerifyHandler := handler.NewEmailVerificationHandler(app.EVService, v, app.CFG)
+	uSessionHandler := handler.NewUserSessionHandler(app.USService, app.AuthService, v, app.CFG)
+	mfaHandler := handler.NewMFAHandler(app.MFAService, v)
+	passkeyHandler := handler.NewPasskeyHandler(app.WAService, v)
+	identityHandler := handler.NewIdentityHandler(app.IdentityService, app.AuthService, app.CFG)
+	profileHandler := handler.NewProfileHandler(app.ProfileService, app.UserService, v, app.CFG)
+	challengeHandler := handler.NewChallengeHandler(app.Challenger)
+	notificationHandler := handler.NewNotificationHandler(app.NotifyService, v)
+	deviceHandler := handler.NewDeviceHandler(app.DeviceService, v)
 
 	r.Use(app.Middleware.CORSMiddleware())
 
+	// file upload untuk storage lokal, driver lain disajikan langsung oleh storage-nya
+	if app.CFG.Storage.Driver == "local" {
+		r.Static(app.CFG.Storage.LocalRoute, app.CFG.Storage.LocalDir)
+	}
+
 	// role middleware
 	saa := app.Middleware.RoleMiddleware(middleware.MatchAny, "super admin", "admin")
 
+	// rate limit
+	loginLimit := app.Middleware.RateLimitMiddleware("login", middleware.KeyByIP)
+	identifierLimit := app.Middleware.RateLimitMiddleware("login_identifier", middleware.KeyByJSONField("identifier"))
+	registerLimit := app.Middleware.RateLimitMiddleware("register", middleware.KeyByIP)
+	resendLimit := app.Middleware.RateLimitMiddleware("verify_resend", middleware.KeyByIP)
+	refreshLimit := app.Middleware.RateLimitMiddleware("refresh", middleware.KeyByIP)
+	magicLinkLimit := app.Middleware.RateLimitMiddleware("magic_link", middleware.KeyByIP)
+	magicLinkEmailLimit := app.Middleware.RateLimitMiddleware("magic_link_identifier", middleware.KeyByJSONField("email"))
+	forgotLimit := app.Middleware.RateLimitMiddleware("forgot_password", middleware.KeyByIP)
+	forgotEmailLimit := app.Middleware.RateLimitMiddleware("forgot_password_identifier", middleware.KeyByJSONField("email"))
+	mfaLimit := app.Middleware.RateLimitMiddleware("mfa", middleware.KeyByUserID)
+	revokeLinkLimit := app.Middleware.RateLimitMiddleware("session_revoke_link", middleware.KeyByIP)
+	challengeGate := app.Middleware.ChallengeMiddleware()
+
 	// ===> auth routes
 	auth := r.Group("/api/auth")
-	auth.POST("/login", authHandler.Login)
+	auth.Use(app.Middleware.DeviceMiddleware())
+	auth.GET("/challenge", challengeHandler.Issue)
+	auth.POST("/login", loginLimit, identifierLimit, challengeGate, authHandler.Login)
+	auth.POST("/login/mfa", loginLimit, authHandler.LoginMFA)
+	auth.POST("/login/recovery", loginLimit, authHandler.LoginRecovery)
+	auth.POST("/login/passkey/begin", loginLimit, authHandler.LoginMFAPasskeyBegin)
+	auth.POST("/login/passkey/finish", loginLimit, authHandler.LoginMFAPasskey)
+	auth.POST("/passkeys/login/begin", loginLimit, authHandler.LoginPasskeyBegin)
+	auth.POST("/passkeys/login/finish", loginLimit, authHandler.LoginPasskey)
+	auth.POST("/magic-link", magicLinkLimit, magicLinkEmailLimit, authHandler.MagicLink)
+	auth.POST("/magic-link/login", loginLimit, authHandler.LoginMagicLink)
+	auth.POST("/forgot-password", forgotLimit, forgotEmailLimit, authHandler.ForgotPassword)
+	auth.POST("/reset-password", authHandler.ResetPassword)
+	auth.POST("/email-change/confirm", authHandler.EmailChangeConfirm)
+	auth.POST("/email-change/revert", authHandler.EmailChangeRevert)
+	auth.GET("/oauth/:provider", identityHandler.Redirect)
+	auth.GET("/oauth/:provider/callback", identityHandler.Callback)
 	auth.POST("/logout", authHandler.Logout)
-	auth.POST("/logout-all-devices", authHandler.LogoutAll)
-	auth.POST("/register", authHandler.Register)
+	auth.POST("/register", registerLimit, challengeGate, authHandler.Register)
 	auth.POST("/verify-email", emailVerifyHandler.VerifyEmail)
-	auth.POST("/verify-register-resend", emailVerifyHandler.VerifyRegisterResend)
+	auth.POST("/verify-register-resend", resendLimit, emailVerifyHandler.VerifyRegisterResend)
 	auth.POST("/verify-register-by-admin", emailVerifyHandler.VerifyRegisterByAdmin)
-	auth.POST("/verify-register-by-admin-resend", emailVerifyHandler.VerifyRegisterByAdminResend)
+	auth.POST("/verify-register-by-admin-resend", resendLimit, emailVerifyHandler.VerifyRegisterByAdminResend)
+	auth.POST("/sessions/revoke-link", revokeLinkLimit, uSessionHandler.RevokeByLink)
+
+	// route user login memakai cache token version
+	me := auth.Group("", app.Middleware.AuthMiddleware(middleware.TokenVersionCached))
+	me.GET("/me", authHandler.Me)
+	me.PATCH("/me/username", authHandler.UsernameChange)
+	me.GET("/me/profile", profileHandler.Me)
+	me.PATCH("/me/profile", profileHandler.UpdateMe)
+	me.POST("/me/avatar", profileHandler.UploadAvatar)
+	me.DELETE("/me/avatar", profileHandler.DeleteAvatar)
+	me.GET("/me/notifications", notificationHandler.Preferences)
+	me.PATCH("/me/notifications", notificationHandler.UpdatePreferences)
+
+	// route yang mengubah kredensial atau session selalu mencocokkan token version ke database,
+	// token yang dicabut di instance lain langsung ditolak
+	secure := auth.Group("", app.Middleware.AuthMiddleware(middleware.TokenVersionStrict))
+	secure.POST("/logout-all-devices", authHandler.LogoutAll)
+	secure.PATCH("/me/password", authHandler.ChangePassword)
+	secure.POST("/me/email", authHandler.EmailChange)
+
+	// MFA
+	mfa := secure.Group("/mfa")
+	mfa.Use(mfaLimit)
+	mfa.POST("/enroll", mfaHandler.Enroll)
+	mfa.POST("/confirm", mfaHandler.Confirm)
+	mfa.POST("/disable", mfaHandler.Disable)
+	mfa.POST("/recovery-codes", mfaHandler.RegenerateRecoveryCodes)
 
-	// auth middleware
-	auth.Use(app.Middleware.AuthMiddleware())
-	auth.GET("/me", authHandler.Me)
+	// passkey
+	passkey := secure.Group("/passkeys")
+	passkey.POST("/register/begin", passkeyHandler.RegisterBegin)
+	passkey.POST("/register/finish", passkeyHandler.RegisterFinish)
+	passkey.GET("", passkeyHandler.List)
+	passkey.PATCH("/:id", passkeyHandler.Rename)
+	passkey.DELETE("/:id", passkeyHandler.Delete)
+
+	// session
+	sessions := secure.Group("/sessions")
+	sessions.GET("", uSessionHandler.List)
+	sessions.DELETE("/:id", uSessionHandler.Revoke)
+
+	// perangkat
+	devices := secure.Group("/devices")
+	devices.GET("", deviceHandler.List)
+	devices.PATCH("/:id", deviceHandler.Rename)
+	devices.DELETE("/:id/trust", deviceHandler.Untrust)
+
+	// akun eksternal
+	identity := secure.Group("/identities")
+	identity.GET("", identityHandler.List)
+	identity.POST("/:provider", identityHandler.Link)
+	identity.DELETE("/:provider", identityHandler.Unlink)
 	// ===> end auth routes
 
 	// refresh token
 	refresh := r.Group("/api/refresh-token")
-	refresh.POST("", uSessionHandler.RefreshToken)
+	refresh.Use(app.Middleware.DeviceMiddleware())
+	refresh.POST("", refreshLimit, uSessionHandler.RefreshToken)
 
 	// ===> users routes
 	user := r.Group("/api/users")
-	user.Use(app.Middleware.AuthMiddleware())
+	user.Use(app.Middleware.AuthMiddleware(middleware.TokenVersionStrict))
 	user.GET("", saa, userHandler.GetAll)
 	user.POST("", saa, userHandler.Create)
 	user.GET("/:id", saa, userHandler.FindByID)
 	user.PATCH("/:id/email", saa, userHandler.EmailUpdate)
+	user.PATCH("/:id/username", saa, userHandler.UsernameUpdate)
+	user.PATCH("/:id/profile", saa, profileHandler.Update)
 	user.PATCH("/:id/roles-update", saa, userHandler.RoleUpdate)
 	user.DELETE("/:id", saa, userHandler.Delete)
+	user.POST("/:id/unlock", saa, userHandler.Unlock)
+	user.GET("/:id/sessions", saa, uSessionHandler.UserSessions)
+	user.DELETE("/:id/sessions/:sid", saa, uSessionHandler.UserSessionRevoke)
+	user.POST("/:id/force-logout", saa, uSessionHandler.ForceLogout)
 	// ===> end users routes
+
+	// ===> sessions routes
+	session := r.Group("/api/sessions")
+	session.Use(app.Middleware.AuthMiddleware(middleware.TokenVersionStrict))
+	session.GET("", saa, uSessionHandler.Search)
+	// ===> end sessions routes
 }
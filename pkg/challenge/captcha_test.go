@@ -0,0 +1,107 @@
+package challenge
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// fakeVerifier pengganti provider captcha, mencatat jawaban dan IP yang diteruskan
+type fakeVerifier struct {
+	ok       bool
+	err      error
+	response string
+	clientIP string
+	calls    int
+}
+
+func (f *fakeVerifier) Verify(_ context.Context, response, clientIP string) (bool, error) {
+	f.calls++
+	f.response, f.clientIP = response, clientIP
+	return f.ok, f.err
+}
+
+func TestCaptchaIssue(t *testing.T) {
+	c, err := NewCaptcha(TypeTurnstile, "site-key", &fakeVerifier{}).Issue(context.Background(), "10.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Type != TypeTurnstile || c.SiteKey != "site-key" || c.Token != "" {
+		t.Errorf("challenge = %+v, ingin turnstile dengan site key tanpa token", c)
+	}
+}
+
+func TestCaptchaVerify(t *testing.T) {
+	providerDown := errors.New("provider tidak bisa dihubungi")
+
+	tests := []struct {
+		name      string
+		verifier  *fakeVerifier
+		response  string
+		want      error
+		wantCalls int
+	}{
+		{name: "berhasil", verifier: &fakeVerifier{ok: true}, response: "jawaban", wantCalls: 1},
+		{name: "ditolak provider", verifier: &fakeVerifier{ok: false}, response: "jawaban", want: ErrInvalid, wantCalls: 1},
+		{name: "provider error", verifier: &fakeVerifier{err: providerDown}, response: "jawaban", want: providerDown, wantCalls: 1},
+		{name: "tanpa jawaban tidak ke provider", verifier: &fakeVerifier{ok: true}, response: "", want: ErrInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCaptcha(TypeHCaptcha, "site-key", tt.verifier)
+
+			err := c.Verify(context.Background(), "", tt.response, "10.0.0.1")
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Verify() = %v, ingin %v", err, tt.want)
+			}
+			if tt.verifier.calls != tt.wantCalls {
+				t.Errorf("provider dipanggil %d kali, ingin %d", tt.verifier.calls, tt.wantCalls)
+			}
+			if tt.wantCalls > 0 && (tt.verifier.response != tt.response || tt.verifier.clientIP != "10.0.0.1") {
+				t.Errorf("provider menerima response=%q ip=%q", tt.verifier.response, tt.verifier.clientIP)
+			}
+		})
+	}
+}
+
+func TestSiteVerifier(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		want    bool
+		wantErr bool
+	}{
+		{name: "berhasil", status: http.StatusOK, body: `{"success":true}`, want: true},
+		{name: "gagal", status: http.StatusOK, body: `{"success":false,"error-codes":["invalid-input-response"]}`},
+		{name: "status bukan 200", status: http.StatusInternalServerError, body: `{}`, wantErr: true},
+		{name: "respon rusak", status: http.StatusOK, body: `bukan json`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := r.ParseForm(); err != nil {
+					t.Fatal(err)
+				}
+				if r.PostForm.Get("secret") != "secret" || r.PostForm.Get("response") != "jawaban" || r.PostForm.Get("remoteip") != "10.0.0.1" {
+					t.Errorf("form = %v", r.PostForm)
+				}
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			ok, err := NewSiteVerifier(server.URL, "secret", server.Client()).Verify(context.Background(), "jawaban", "10.0.0.1")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, ingin error %v", err, tt.wantErr)
+			}
+			if ok != tt.want {
+				t.Errorf("Verify() = %v, ingin %v", ok, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package challenge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/irawankilmer/auth-service/internal/configs"
+	"net/http"
+	"time"
+)
+
+const (
+	TypeProofOfWork = "pow"
+	TypeHCaptcha    = "hcaptcha"
+	TypeTurnstile   = "turnstile"
+)
+
+var (
+	ErrInvalid = errors.New("jawaban challenge tidak valid")
+	ErrExpired = errors.New("challenge sudah kedaluwarsa")
+)
+
+// Challenge dikirim ke client. Untuk pow client mencari Response sehingga
+// SHA-256(Token + ":" + Response) diawali Difficulty bit nol, untuk captcha client memakai SiteKey di widget
+type Challenge struct {
+	Type       string     `json:"type"`
+	Token      string     `json:"token,omitempty"`
+	Difficulty int        `json:"difficulty,omitempty"`
+	SiteKey    string     `json:"site_key,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+}
+
+// Challenger membuat dan memverifikasi challenge, jawaban dikirim client bersama token yang diterimanya
+type Challenger interface {
+	Issue(ctx context.Context, clientIP string) (*Challenge, error)
+	Verify(ctx context.Context, token, response, clientIP string) error
+}
+
+func New(cfg configs.ChallengeConfig) (Challenger, error) {
+	switch cfg.Driver {
+	case TypeProofOfWork:
+		if cfg.Difficulty < 1 || cfg.Difficulty > 32 {
+			return nil, fmt.Errorf("difficulty pow harus 1-32, bukan %d", cfg.Difficulty)
+		}
+		return NewProofOfWork(cfg.Secret, cfg.Difficulty, cfg.TTL), nil
+	case TypeHCaptcha, TypeTurnstile:
+		if cfg.SiteKey == "" || cfg.CaptchaSecret == "" {
+			return nil, fmt.Errorf("site key dan secret %s wajib diisi", cfg.Driver)
+		}
+		verifyURL := cfg.VerifyURL
+		if verifyURL == "" {
+			verifyURL = defaultVerifyURLs[cfg.Driver]
+		}
+		verifier := NewSiteVerifier(verifyURL, cfg.CaptchaSecret, &http.Client{Timeout: 10 * time.Second})
+		return NewCaptcha(cfg.Driver, cfg.SiteKey, verifier), nil
+	default:
+		return nil, fmt.Errorf("driver challenge %q tidak dikenal", cfg.Driver)
+	}
+}
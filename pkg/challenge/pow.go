@@ -0,0 +1,106 @@
+package challenge
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// proofOfWork challenge hashcash tanpa state: token berisi waktu kedaluwarsa, difficulty dan nonce acak,
+// ditandatangani HMAC yang juga mengikat IP client agar token tidak bisa dipakai dari IP lain
+type proofOfWork struct {
+	secret     []byte
+	difficulty int
+	ttl        time.Duration
+	now        func() time.Time
+}
+
+func NewProofOfWork(secret string, difficulty int, ttl time.Duration) Challenger {
+	return &proofOfWork{secret: []byte(secret), difficulty: difficulty, ttl: ttl, now: time.Now}
+}
+
+func (p *proofOfWork) Issue(_ context.Context, clientIP string) (*Challenge, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	expiresAt := p.now().Add(p.ttl).Truncate(time.Second)
+	payload := fmt.Sprintf("%d.%d.%s", expiresAt.Unix(), p.difficulty, hex.EncodeToString(nonce))
+
+	return &Challenge{
+		Type:       TypeProofOfWork,
+		Token:      payload + "." + p.sign(payload, clientIP),
+		Difficulty: p.difficulty,
+		ExpiresAt:  &expiresAt,
+	}, nil
+}
+
+func (p *proofOfWork) Verify(_ context.Context, token, response, clientIP string) error {
+	// format token: exp.difficulty.nonce.signature
+	payload, signature, ok := cutLast(token, ".")
+	if !ok || response == "" || len(response) > 64 {
+		return ErrInvalid
+	}
+	if !hmac.Equal([]byte(signature), []byte(p.sign(payload, clientIP))) {
+		return ErrInvalid
+	}
+
+	parts := strings.Split(payload, ".")
+	if len(parts) != 3 {
+		return ErrInvalid
+	}
+	exp, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return ErrInvalid
+	}
+	difficulty, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return ErrInvalid
+	}
+	if p.now().Unix() > exp {
+		return ErrExpired
+	}
+
+	sum := sha256.Sum256([]byte(token + ":" + response))
+	if leadingZeroBits(sum[:]) < difficulty {
+		return ErrInvalid
+	}
+
+	return nil
+}
+
+func (p *proofOfWork) sign(payload, clientIP string) string {
+	mac := hmac.New(sha256.New, p.secret)
+	mac.Write([]byte(payload + "|" + clientIP))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func leadingZeroBits(sum []byte) int {
+	count := 0
+	for _, b := range sum {
+		if b != 0 {
+			return count + bits.LeadingZeros8(b)
+		}
+		count += 8
+	}
+
+	return count
+}
+
+func cutLast(s, sep string) (string, string, bool) {
+	i := strings.LastIndex(s, sep)
+	if i < 0 {
+		return "", "", false
+	}
+
+	return s[:i], s[i+len(sep):], true
+}
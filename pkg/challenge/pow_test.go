@@ -0,0 +1,127 @@
+package challenge
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// solve mencari jawaban seperti yang dilakukan client
+func solve(t *testing.T, token string, difficulty int) string {
+	t.Helper()
+
+	for i := 0; i < 1<<24; i++ {
+		response := strconv.Itoa(i)
+		sum := sha256.Sum256([]byte(token + ":" + response))
+		if leadingZeroBits(sum[:]) >= difficulty {
+			return response
+		}
+	}
+
+	t.Fatal("jawaban tidak ditemukan")
+	return ""
+}
+
+// wrong mencari jawaban yang tidak memenuhi difficulty
+func wrong(token string, difficulty int) string {
+	for i := 0; ; i++ {
+		response := strconv.Itoa(i)
+		sum := sha256.Sum256([]byte(token + ":" + response))
+		if leadingZeroBits(sum[:]) < difficulty {
+			return response
+		}
+	}
+}
+
+func newTestProofOfWork(difficulty int, now *time.Time) *proofOfWork {
+	p := NewProofOfWork("secret", difficulty, time.Minute).(*proofOfWork)
+	p.now = func() time.Time { return *now }
+	return p
+}
+
+func TestProofOfWorkDifficulty(t *testing.T) {
+	for _, difficulty := range []int{1, 4, 8, 12} {
+		t.Run(strconv.Itoa(difficulty), func(t *testing.T) {
+			now := time.Unix(1_700_000_000, 0)
+			p := newTestProofOfWork(difficulty, &now)
+
+			c, err := p.Issue(context.Background(), "10.0.0.1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.Type != TypeProofOfWork || c.Difficulty != difficulty {
+				t.Fatalf("challenge = %+v, ingin pow difficulty %d", c, difficulty)
+			}
+
+			if err := p.Verify(context.Background(), c.Token, solve(t, c.Token, difficulty), "10.0.0.1"); err != nil {
+				t.Errorf("jawaban benar ditolak: %v", err)
+			}
+			if err := p.Verify(context.Background(), c.Token, wrong(c.Token, difficulty), "10.0.0.1"); !errors.Is(err, ErrInvalid) {
+				t.Errorf("jawaban kurang bit nol: err = %v, ingin ErrInvalid", err)
+			}
+		})
+	}
+}
+
+func TestProofOfWorkVerify(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	p := newTestProofOfWork(8, &now)
+	c, err := p.Issue(context.Background(), "10.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	answer := solve(t, c.Token, 8)
+
+	// token dengan difficulty diturunkan, tanda tangan tidak cocok lagi
+	parts := strings.Split(c.Token, ".")
+	parts[1] = "1"
+	lowered := strings.Join(parts, ".")
+
+	tests := []struct {
+		name     string
+		token    string
+		response string
+		ip       string
+		after    time.Duration
+		want     error
+	}{
+		{name: "valid", token: c.Token, response: answer, ip: "10.0.0.1"},
+		{name: "valid sampai detik kedaluwarsa", token: c.Token, response: answer, ip: "10.0.0.1", after: time.Minute},
+		{name: "kedaluwarsa", token: c.Token, response: answer, ip: "10.0.0.1", after: time.Minute + time.Second, want: ErrExpired},
+		{name: "IP lain", token: c.Token, response: answer, ip: "10.0.0.2", want: ErrInvalid},
+		{name: "difficulty diubah", token: lowered, response: answer, ip: "10.0.0.1", want: ErrInvalid},
+		{name: "tanpa jawaban", token: c.Token, response: "", ip: "10.0.0.1", want: ErrInvalid},
+		{name: "jawaban terlalu panjang", token: c.Token, response: strings.Repeat("0", 65), ip: "10.0.0.1", want: ErrInvalid},
+		{name: "token rusak", token: "bukan-token", response: answer, ip: "10.0.0.1", want: ErrInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now = time.Unix(1_700_000_000, 0).Add(tt.after)
+
+			err := p.Verify(context.Background(), tt.token, tt.response, tt.ip)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Verify() = %v, ingin %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestProofOfWorkOtherSecret(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	p := newTestProofOfWork(4, &now)
+	other := NewProofOfWork("secret-lain", 4, time.Minute).(*proofOfWork)
+	other.now = p.now
+
+	c, err := p.Issue(context.Background(), "10.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := other.Verify(context.Background(), c.Token, solve(t, c.Token, 4), "10.0.0.1"); !errors.Is(err, ErrInvalid) {
+		t.Errorf("token dari secret lain: err = %v, ingin ErrInvalid", err)
+	}
+}
@@ -0,0 +1,53 @@
+package device
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+// NewID membuat device ID acak, dikirim ke client dalam bentuk yang sudah ditandatangani (lihat Sign)
+func NewID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// Sign menghasilkan "id.signature" untuk cookie device_id atau header X-Device-ID
+func Sign(secret, id string) string {
+	return id + "." + signature(secret, id)
+}
+
+// Verify mengembalikan device ID jika tanda tangan valid, ID buatan client sendiri ditolak
+func Verify(secret, token string) (string, bool) {
+	id, sig, ok := strings.Cut(token, ".")
+	if !ok || !IsID(id) {
+		return "", false
+	}
+	if !hmac.Equal([]byte(sig), []byte(signature(secret, id))) {
+		return "", false
+	}
+
+	return id, true
+}
+
+// IsID membedakan device ID dari nilai lama yang bukan buatan NewID
+func IsID(id string) bool {
+	if len(id) != 32 {
+		return false
+	}
+	_, err := hex.DecodeString(id)
+	return err == nil
+}
+
+func signature(secret, id string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("device:" + id))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,168 @@
+package device
+
+import (
+	"regexp"
+	"strings"
+)
+
+const (
+	TypeDesktop = "desktop"
+	TypeMobile  = "mobile"
+	TypeTablet  = "tablet"
+	TypeBot     = "bot"
+	TypeOther   = "other"
+)
+
+// UserAgent hasil parsing header User-Agent, field yang tidak dikenali dibiarkan kosong
+type UserAgent struct {
+	Browser        string
+	BrowserVersion string
+	OS             string
+	OSVersion      string
+	DeviceType     string
+	DeviceModel    string
+}
+
+// urutan penting: Edge dan Opera juga menulis Chrome/ dan Safari/, Chrome juga menulis Safari/
+var browserPatterns = []struct {
+	name    string
+	pattern *regexp.Regexp
+}{
+	{"Edge", regexp.MustCompile(`Edg(?:e|A|iOS)?/([\d.]+)`)},
+	{"Opera", regexp.MustCompile(`(?:OPR|OPiOS)/([\d.]+)`)},
+	{"Samsung Internet", regexp.MustCompile(`SamsungBrowser/([\d.]+)`)},
+	{"Firefox", regexp.MustCompile(`(?:Firefox|FxiOS)/([\d.]+)`)},
+	{"Chrome", regexp.MustCompile(`(?:Chrome|CriOS)/([\d.]+)`)},
+	{"Safari", regexp.MustCompile(`Version/([\d.]+).*Safari/`)},
+	{"Internet Explorer", regexp.MustCompile(`(?:MSIE |Trident/.*rv:)([\d.]+)`)},
+}
+
+// client non-browser yang umum memanggil API
+var clientPatterns = []struct {
+	name    string
+	pattern *regexp.Regexp
+}{
+	{"curl", regexp.MustCompile(`^curl/([\d.]+)`)},
+	{"Wget", regexp.MustCompile(`^Wget/([\d.]+)`)},
+	{"Postman", regexp.MustCompile(`^PostmanRuntime/([\d.]+)`)},
+	{"Insomnia", regexp.MustCompile(`^insomnia/([\d.]+)`)},
+	{"okhttp", regexp.MustCompile(`^okhttp/([\d.]+)`)},
+	{"Dart", regexp.MustCompile(`^Dart/([\d.]+)`)},
+	{"Python Requests", regexp.MustCompile(`^python-requests/([\d.]+)`)},
+	{"Go HTTP Client", regexp.MustCompile(`^Go-http-client/([\d.]+)`)},
+}
+
+var (
+	botPattern     = regexp.MustCompile(`(?i)bot|crawler|spider|slurp|headless`)
+	windowsPattern = regexp.MustCompile(`Windows NT ([\d.]+)`)
+	androidPattern = regexp.MustCompile(`Android ([\d.]+)(?:; ([^;)]+))?`)
+	iosPattern     = regexp.MustCompile(`(?:iPhone|CPU) OS ([\d_]+)`)
+	macPattern     = regexp.MustCompile(`Mac OS X ([\d_.]+)`)
+	buildSuffix    = regexp.MustCompile(`\s+Build/.*$`)
+)
+
+var windowsVersions = map[string]string{
+	"10.0": "10",
+	"6.3":  "8.1",
+	"6.2":  "8",
+	"6.1":  "7",
+	"6.0":  "Vista",
+	"5.1":  "XP",
+}
+
+// ParseUserAgent mengenali browser, OS dan jenis perangkat dari User-Agent yang umum.
+// Windows 11 tidak bisa dibedakan dari Windows 10 lewat User-Agent
+func ParseUserAgent(ua string) UserAgent {
+	var result UserAgent
+	ua = strings.TrimSpace(ua)
+	if ua == "" {
+		result.DeviceType = TypeOther
+		return result
+	}
+
+	for _, c := range clientPatterns {
+		if m := c.pattern.FindStringSubmatch(ua); m != nil {
+			result.Browser, result.BrowserVersion = c.name, m[1]
+			result.DeviceType = TypeOther
+			return result
+		}
+	}
+
+	for _, b := range browserPatterns {
+		if m := b.pattern.FindStringSubmatch(ua); m != nil {
+			result.Browser, result.BrowserVersion = b.name, m[1]
+			break
+		}
+	}
+
+	switch {
+	case strings.Contains(ua, "iPad"):
+		result.OS, result.DeviceModel, result.DeviceType = "iPadOS", "iPad", TypeTablet
+		if m := iosPattern.FindStringSubmatch(ua); m != nil {
+			result.OSVersion = strings.ReplaceAll(m[1], "_", ".")
+		}
+	case strings.Contains(ua, "iPhone"):
+		result.OS, result.DeviceModel, result.DeviceType = "iOS", "iPhone", TypeMobile
+		if m := iosPattern.FindStringSubmatch(ua); m != nil {
+			result.OSVersion = strings.ReplaceAll(m[1], "_", ".")
+		}
+	case strings.Contains(ua, "Android"):
+		result.OS, result.DeviceType = "Android", TypeTablet
+		if strings.Contains(ua, "Mobile") {
+			result.DeviceType = TypeMobile
+		}
+		if m := androidPattern.FindStringSubmatch(ua); m != nil {
+			result.OSVersion = m[1]
+			if model := strings.TrimSpace(buildSuffix.ReplaceAllString(m[2], "")); model != "" && model != "K" {
+				result.DeviceModel = model
+			}
+		}
+	case strings.Contains(ua, "Windows"):
+		result.OS, result.DeviceType = "Windows", TypeDesktop
+		if m := windowsPattern.FindStringSubmatch(ua); m != nil {
+			result.OSVersion = windowsVersions[m[1]]
+		}
+	case strings.Contains(ua, "CrOS"):
+		result.OS, result.DeviceType = "ChromeOS", TypeDesktop
+	case strings.Contains(ua, "Macintosh"):
+		result.OS, result.DeviceType = "macOS", TypeDesktop
+		if m := macPattern.FindStringSubmatch(ua); m != nil {
+			result.OSVersion = strings.ReplaceAll(m[1], "_", ".")
+		}
+	case strings.Contains(ua, "Linux"):
+		result.OS, result.DeviceType = "Linux", TypeDesktop
+	default:
+		result.DeviceType = TypeOther
+	}
+
+	if botPattern.MatchString(ua) {
+		result.DeviceType = TypeBot
+	}
+
+	return result
+}
+
+// String ringkasan untuk ditampilkan ke user, misal "Chrome 120 di Windows 10"
+func (u UserAgent) String() string {
+	browser := strings.TrimSpace(u.Browser + " " + majorVersion(u.BrowserVersion))
+	os := strings.TrimSpace(u.OS + " " + u.OSVersion)
+	if u.DeviceModel != "" && u.DeviceModel != u.OS {
+		os = strings.TrimSpace(u.DeviceModel + ", " + os)
+	}
+
+	switch {
+	case browser != "" && os != "":
+		return browser + " di " + os
+	case browser != "":
+		return browser
+	case os != "":
+		return os
+	default:
+		return "Perangkat tidak dikenal"
+	}
+}
+
+func majorVersion(version string) string {
+	major, _, _ := strings.Cut(version, ".")
+	return major
+}
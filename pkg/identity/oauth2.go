@@ -0,0 +1,124 @@
+package identity
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/irawankilmer/auth-service/internal/configs"
+	"golang.org/x/oauth2"
+	"net/http"
+)
+
+type oauth2Provider struct {
+	name         string
+	oauth        *oauth2.Config
+	userInfoURL  string
+	emailsURL    string
+	subjectField string
+}
+
+// NewOAuth2Provider membuat provider OAuth2 biasa (tanpa ID token), data user diambil dari endpoint user info
+func NewOAuth2Provider(cfg configs.IdentityProviderConfig) Provider {
+	subjectField := cfg.SubjectField
+	if subjectField == "" {
+		subjectField = "sub"
+	}
+
+	return &oauth2Provider{
+		name: cfg.Name,
+		oauth: &oauth2.Config{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			RedirectURL:  cfg.RedirectURL,
+			Endpoint:     oauth2.Endpoint{AuthURL: cfg.AuthURL, TokenURL: cfg.TokenURL},
+			Scopes:       cfg.Scopes,
+		},
+		userInfoURL:  cfg.UserInfoURL,
+		emailsURL:    cfg.EmailsURL,
+		subjectField: subjectField,
+	}
+}
+
+func (p *oauth2Provider) Name() string {
+	return p.name
+}
+
+// AuthCodeURL mengabaikan nonce karena OAuth2 biasa tidak punya ID token
+func (p *oauth2Provider) AuthCodeURL(state, nonce, verifier string) string {
+	return p.oauth.AuthCodeURL(state, oauth2.S256ChallengeOption(verifier))
+}
+
+func (p *oauth2Provider) Exchange(ctx context.Context, code, nonce, verifier string) (*Identity, error) {
+	// tukar authorization code dengan token, PKCE verifier wajib dikirim
+	token, err := p.oauth.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, err
+	}
+	client := p.oauth.Client(ctx, token)
+
+	// ambil data user
+	var info map[string]any
+	if err := getJSON(client, p.userInfoURL, &info); err != nil {
+		return nil, err
+	}
+
+	subject, ok := info[p.subjectField]
+	if !ok || subject == nil {
+		return nil, fmt.Errorf("field %s tidak ada di user info", p.subjectField)
+	}
+
+	identity := &Identity{Subject: fmt.Sprint(subject)}
+	identity.Email, _ = info["email"].(string)
+	identity.EmailVerified, _ = info["email_verified"].(bool)
+	identity.Name, _ = info["name"].(string)
+
+	// email terverifikasi diambil dari endpoint terpisah jika ada (misal GitHub)
+	if p.emailsURL != "" {
+		var emails []struct {
+			Email    string `json:"email"`
+			Primary  bool   `json:"primary"`
+			Verified bool   `json:"verified"`
+		}
+		if err := getJSON(client, p.emailsURL, &emails); err != nil {
+			return nil, err
+		}
+
+		identity.EmailVerified = false
+		for _, e := range emails {
+			if e.Primary && e.Verified {
+				identity.Email = e.Email
+				identity.EmailVerified = true
+				break
+			}
+		}
+	}
+
+	return identity, nil
+}
+
+func getJSON(client *http.Client, url string, out any) error {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("request %s gagal dengan status %d", url, resp.StatusCode)
+	}
+	// UseNumber agar ID numerik besar tidak berubah menjadi float
+	decoder := json.NewDecoder(resp.Body)
+	decoder.UseNumber()
+	if err := decoder.Decode(out); err != nil {
+		return errors.New("respon " + url + " bukan JSON yang valid")
+	}
+
+	return nil
+}
@@ -0,0 +1,285 @@
+package identity
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/go-jose/go-jose/v4"
+	"github.com/irawankilmer/auth-service/internal/configs"
+	"golang.org/x/oauth2"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeOIDC server OIDC palsu: JWKS, token endpoint dengan PKCE dan ID token yang isinya ditentukan test
+type fakeOIDC struct {
+	t      *testing.T
+	server *httptest.Server
+	key    *rsa.PrivateKey
+	mu     sync.Mutex
+	grants map[string]fakeGrant
+}
+
+// fakeGrant authorization code yang sudah diterbitkan, claims menjadi isi ID token
+type fakeGrant struct {
+	challenge string
+	claims    map[string]any
+	signer    *rsa.PrivateKey
+	noIDToken bool
+}
+
+func newFakeOIDC(t *testing.T) *fakeOIDC {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fakeOIDC{t: t, key: key, grants: map[string]fakeGrant{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+			{Key: &key.PublicKey, KeyID: "k1", Algorithm: string(jose.RS256), Use: "sig"},
+		}})
+	})
+	mux.HandleFunc("/token", f.token)
+	f.server = httptest.NewServer(mux)
+	t.Cleanup(f.server.Close)
+
+	return f
+}
+
+func (f *fakeOIDC) config() configs.IdentityProviderConfig {
+	return configs.IdentityProviderConfig{
+		Name:         "fake",
+		Type:         "oidc",
+		ClientID:     "client-id",
+		ClientSecret: "client-secret",
+		RedirectURL:  "http://localhost/callback",
+		IssuerURL:    f.server.URL,
+		AuthURL:      f.server.URL + "/auth",
+		TokenURL:     f.server.URL + "/token",
+		JWKSURL:      f.server.URL + "/jwks",
+		Scopes:       []string{"openid", "email"},
+	}
+}
+
+// authorize meniru halaman login provider: membaca URL otorisasi lalu menerbitkan code.
+// modify mengubah claims ID token default sebelum disimpan
+func (f *fakeOIDC) authorize(authURL string, modify func(g *fakeGrant)) string {
+	f.t.Helper()
+
+	u, err := url.Parse(authURL)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	query := u.Query()
+	if query.Get("code_challenge_method") != "S256" || query.Get("client_id") != "client-id" {
+		f.t.Fatalf("URL otorisasi tidak lengkap: %s", authURL)
+	}
+
+	now := time.Now()
+	grant := fakeGrant{
+		challenge: query.Get("code_challenge"),
+		signer:    f.key,
+		claims: map[string]any{
+			"iss":            f.server.URL,
+			"aud":            "client-id",
+			"sub":            "subject-1",
+			"iat":            now.Unix(),
+			"exp":            now.Add(time.Hour).Unix(),
+			"nonce":          query.Get("nonce"),
+			"email":          "user@example.com",
+			"email_verified": true,
+			"name":           "User Satu",
+		},
+	}
+	if modify != nil {
+		modify(&grant)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	code := "code-" + query.Get("state")
+	f.grants[code] = grant
+
+	return code
+}
+
+func (f *fakeOIDC) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		f.t.Fatal(err)
+	}
+
+	clientID, secret, ok := r.BasicAuth()
+	if !ok {
+		clientID, secret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if clientID != "client-id" || secret != "client-secret" {
+		http.Error(w, `{"error":"invalid_client"}`, http.StatusUnauthorized)
+		return
+	}
+
+	f.mu.Lock()
+	grant, ok := f.grants[r.PostForm.Get("code")]
+	delete(f.grants, r.PostForm.Get("code"))
+	f.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok || base64.RawURLEncoding.EncodeToString(sum[:]) != grant.challenge {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":"invalid_grant"}`))
+		return
+	}
+
+	body := map[string]any{"access_token": "access-token", "token_type": "Bearer", "expires_in": 3600}
+	if !grant.noIDToken {
+		body["id_token"] = f.sign(grant.signer, grant.claims)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func (f *fakeOIDC) sign(key *rsa.PrivateKey, claims map[string]any) string {
+	f.t.Helper()
+
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: key},
+		(&jose.SignerOptions{}).WithType("JWT").WithHeader("kid", "k1"))
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	signed, err := signer.Sign(payload)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	token, err := signed.CompactSerialize()
+	if err != nil {
+		f.t.Fatal(err)
+	}
+
+	return token
+}
+
+func TestOIDCProviderExchange(t *testing.T) {
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		modify       func(g *fakeGrant)
+		nonce        string
+		verifier     string
+		wantErr      string
+		wantIdentity *Identity
+	}{
+		{
+			name:         "valid",
+			wantIdentity: &Identity{Subject: "subject-1", Email: "user@example.com", EmailVerified: true, Name: "User Satu"},
+		},
+		{
+			name:    "nonce tidak cocok",
+			nonce:   "nonce-lain",
+			wantErr: "nonce",
+		},
+		{
+			name:    "nonce id token diganti",
+			modify:  func(g *fakeGrant) { g.claims["nonce"] = "nonce-penyerang" },
+			wantErr: "nonce",
+		},
+		{
+			name:    "audience client lain",
+			modify:  func(g *fakeGrant) { g.claims["aud"] = "client-lain" },
+			wantErr: "audience",
+		},
+		{
+			name: "id token kedaluwarsa",
+			modify: func(g *fakeGrant) {
+				g.claims["iat"] = time.Now().Add(-2 * time.Hour).Unix()
+				g.claims["exp"] = time.Now().Add(-time.Hour).Unix()
+			},
+			wantErr: "expired",
+		},
+		{
+			name:    "issuer lain",
+			modify:  func(g *fakeGrant) { g.claims["iss"] = "https://issuer-lain.example.com" },
+			wantErr: "issuer",
+		},
+		{
+			name:    "ditandatangani key lain",
+			modify:  func(g *fakeGrant) { g.signer = otherKey },
+			wantErr: "signature",
+		},
+		{
+			name:     "PKCE verifier salah",
+			verifier: oauth2.GenerateVerifier(),
+			wantErr:  "invalid_grant",
+		},
+		{
+			name:    "tanpa id token",
+			modify:  func(g *fakeGrant) { g.noIDToken = true },
+			wantErr: "id_token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeOIDC(t)
+			p := NewOIDCProvider(fake.config())
+
+			nonce, verifier := "nonce-1", oauth2.GenerateVerifier()
+			code := fake.authorize(p.AuthCodeURL("state-1", nonce, verifier), tt.modify)
+			if tt.nonce != "" {
+				nonce = tt.nonce
+			}
+			if tt.verifier != "" {
+				verifier = tt.verifier
+			}
+
+			got, err := p.Exchange(context.Background(), code, nonce, verifier)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Exchange() err = %v, ingin error berisi %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *got != *tt.wantIdentity {
+				t.Errorf("Exchange() = %+v, ingin %+v", got, tt.wantIdentity)
+			}
+		})
+	}
+}
+
+// TestOIDCProviderCodeSingleUse code yang sudah ditukar tidak bisa ditukar lagi
+func TestOIDCProviderCodeSingleUse(t *testing.T) {
+	fake := newFakeOIDC(t)
+	p := NewOIDCProvider(fake.config())
+
+	verifier := oauth2.GenerateVerifier()
+	code := fake.authorize(p.AuthCodeURL("state-1", "nonce-1", verifier), nil)
+
+	if _, err := p.Exchange(context.Background(), code, "nonce-1", verifier); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Exchange(context.Background(), code, "nonce-1", verifier); err == nil {
+		t.Error("code yang sama berhasil ditukar dua kali")
+	}
+}
@@ -0,0 +1,33 @@
+package identity
+
+import (
+	"context"
+	"fmt"
+	"github.com/irawankilmer/auth-service/internal/configs"
+)
+
+// Identity data akun dari provider login eksternal
+type Identity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// Provider login eksternal, implementasi tersedia untuk OIDC dan OAuth2 biasa
+type Provider interface {
+	Name() string
+	AuthCodeURL(state, nonce, verifier string) string
+	Exchange(ctx context.Context, code, nonce, verifier string) (*Identity, error)
+}
+
+func NewProvider(cfg configs.IdentityProviderConfig) (Provider, error) {
+	switch cfg.Type {
+	case "oidc":
+		return NewOIDCProvider(cfg), nil
+	case "oauth2":
+		return NewOAuth2Provider(cfg), nil
+	default:
+		return nil, fmt.Errorf("tipe provider %q tidak dikenal untuk %s", cfg.Type, cfg.Name)
+	}
+}
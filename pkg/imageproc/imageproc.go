@@ -0,0 +1,216 @@
+package imageproc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+)
+
+var (
+	ErrUnsupportedType = errors.New("format gambar tidak didukung")
+	ErrTooLarge        = errors.New("dimensi gambar terlalu besar")
+)
+
+// decoders format yang diterima, dipilih berdasarkan isi file bukan nama file atau header dari client
+var decoders = map[string]func([]byte) (image.Image, error){
+	"image/jpeg": func(b []byte) (image.Image, error) { return jpeg.Decode(bytes.NewReader(b)) },
+	"image/png":  func(b []byte) (image.Image, error) { return png.Decode(bytes.NewReader(b)) },
+	"image/gif":  func(b []byte) (image.Image, error) { return gif.Decode(bytes.NewReader(b)) },
+}
+
+// Decode mendeteksi tipe asli dari isi file, membatasi jumlah piksel sebelum decode penuh,
+// lalu menerapkan orientasi EXIF (JPEG). Metadata tidak ikut terbawa karena hasil selalu di-encode ulang
+func Decode(data []byte, maxPixels int) (image.Image, string, error) {
+	contentType := http.DetectContentType(data)
+	decode, ok := decoders[contentType]
+	if !ok {
+		return nil, contentType, ErrUnsupportedType
+	}
+
+	// cek dimensi dari header saja, agar file kecil berdimensi raksasa tidak menghabiskan memori
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, contentType, fmt.Errorf("%w: %v", ErrUnsupportedType, err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > maxPixels {
+		return nil, contentType, ErrTooLarge
+	}
+
+	img, err := decode(data)
+	if err != nil {
+		return nil, contentType, fmt.Errorf("%w: %v", ErrUnsupportedType, err)
+	}
+	if contentType == "image/jpeg" {
+		img = orient(img, jpegOrientation(data))
+	}
+
+	return img, contentType, nil
+}
+
+// SquareJPEG memotong bagian tengah gambar menjadi persegi lalu mengecilkan ke size x size piksel.
+// Area transparan diisi putih karena JPEG tidak punya alpha
+func SquareJPEG(img image.Image, size, quality int) ([]byte, error) {
+	b := img.Bounds()
+	side := min(b.Dx(), b.Dy())
+	crop := image.Rect(0, 0, side, side).Add(image.Pt(b.Min.X+(b.Dx()-side)/2, b.Min.Y+(b.Dy()-side)/2))
+
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	resize(dst, img, crop)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, fmt.Errorf("encode jpeg gagal: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// resize area-average (box filter) dari src[crop] ke seluruh dst, dikomposisi di atas isi dst.
+// Jika gambar lebih kecil dari target, hasilnya nearest-neighbor
+func resize(dst *image.RGBA, src image.Image, crop image.Rectangle) {
+	dw, dh := dst.Bounds().Dx(), dst.Bounds().Dy()
+	sw, sh := crop.Dx(), crop.Dy()
+
+	for dy := 0; dy < dh; dy++ {
+		y0 := crop.Min.Y + dy*sh/dh
+		y1 := max(crop.Min.Y+(dy+1)*sh/dh, y0+1)
+		for dx := 0; dx < dw; dx++ {
+			x0 := crop.Min.X + dx*sw/dw
+			x1 := max(crop.Min.X+(dx+1)*sw/dw, x0+1)
+
+			var r, g, bl, a, n uint64
+			for y := y0; y < y1; y++ {
+				for x := x0; x < x1; x++ {
+					cr, cg, cb, ca := src.At(x, y).RGBA()
+					r, g, bl, a, n = r+uint64(cr), g+uint64(cg), bl+uint64(cb), a+uint64(ca), n+1
+				}
+			}
+
+			// warna premultiplied, komposisi "over" di atas latar
+			bg := dst.RGBAAt(dx, dy)
+			inv := 0xffff - a/n
+			dst.SetRGBA(dx, dy, color.RGBA{
+				R: uint8((r/n + uint64(bg.R)*0x101*inv/0xffff) >> 8),
+				G: uint8((g/n + uint64(bg.G)*0x101*inv/0xffff) >> 8),
+				B: uint8((bl/n + uint64(bg.B)*0x101*inv/0xffff) >> 8),
+				A: 0xff,
+			})
+		}
+	}
+}
+
+// orient memutar/membalik gambar sesuai nilai tag Orientation EXIF (1-8)
+func orient(img image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for dy := 0; dy < dh; dy++ {
+		for dx := 0; dx < dw; dx++ {
+			sx, sy := dx, dy
+			switch orientation {
+			case 2:
+				sx = w - 1 - dx
+			case 3:
+				sx, sy = w-1-dx, h-1-dy
+			case 4:
+				sy = h - 1 - dy
+			case 5:
+				sx, sy = dy, dx
+			case 6:
+				sx, sy = dy, h-1-dx
+			case 7:
+				sx, sy = w-1-dy, h-1-dx
+			case 8:
+				sx, sy = w-1-dy, dx
+			}
+			dst.Set(dx, dy, img.At(b.Min.X+sx, b.Min.Y+sy))
+		}
+	}
+
+	return dst
+}
+
+// jpegOrientation membaca tag Orientation (0x0112) dari segmen APP1 Exif, 1 jika tidak ada atau tidak valid
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+		marker := data[i+1]
+		// start of scan, tidak ada metadata lagi setelahnya
+		if marker == 0xDA || marker == 0xD9 {
+			return 1
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2 : i+4]))
+		if length < 2 || i+2+length > len(data) {
+			return 1
+		}
+		segment := data[i+4 : i+2+length]
+		if marker == 0xE1 && len(segment) > 6 && string(segment[:6]) == "Exif\x00\x00" {
+			return tiffOrientation(segment[6:])
+		}
+		i += 2 + length
+	}
+
+	return 1
+}
+
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	ifd := int(order.Uint32(tiff[4:8]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+	count := int(order.Uint16(tiff[ifd : ifd+2]))
+	for n := 0; n < count; n++ {
+		entry := ifd + 2 + n*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:entry+2]) == 0x0112 {
+			value := int(order.Uint16(tiff[entry+8 : entry+10]))
+			if value < 1 || value > 8 {
+				return 1
+			}
+
+			return value
+		}
+	}
+
+	return 1
+}
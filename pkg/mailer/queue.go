@@ -0,0 +1,42 @@
+package mailer
+
+import "log"
+
+// Queue menjalankan pengiriman email di background dengan jumlah worker dan antrean terbatas,
+// sehingga banjir request tidak membuat goroutine tanpa batas
+type Queue struct {
+	jobs chan func()
+}
+
+func NewQueue(workers, size int) *Queue {
+	if workers <= 0 {
+		workers = 1
+	}
+	if size < 0 {
+		size = 0
+	}
+
+	q := &Queue{jobs: make(chan func(), size)}
+	for i := 0; i < workers; i++ {
+		go q.work()
+	}
+
+	return q
+}
+
+// Enqueue tidak pernah menunggu, job dibuang jika antrean penuh
+func (q *Queue) Enqueue(name string, job func()) bool {
+	select {
+	case q.jobs <- job:
+		return true
+	default:
+		log.Printf("[WARN] antrean email penuh, %s dibuang", name)
+		return false
+	}
+}
+
+func (q *Queue) work() {
+	for job := range q.jobs {
+		job()
+	}
+}
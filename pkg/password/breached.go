@@ -0,0 +1,72 @@
+package password
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	_ "embed"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// defaultBreached daftar SHA-1 password umum yang dibawa bersama aplikasi
+//
+//go:embed breached.txt
+var defaultBreached []byte
+
+const prefixLength = 5
+
+// breachedList daftar hash SHA-1 password yang pernah bocor, dikelompokkan per 5 karakter pertama hash
+// seperti range API k-anonymity Have I Been Pwned. Pencarian hanya membandingkan suffix di prefix yang sama
+type breachedList map[string]map[string]struct{}
+
+// loadBreached membaca file format "HASH" atau "HASH:COUNT" per baris (format unduhan HIBP),
+// path kosong memakai daftar bawaan
+func loadBreached(path string) (breachedList, error) {
+	var r io.Reader = bytes.NewReader(defaultBreached)
+	if path != "" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("buka file breached password gagal: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	list := breachedList{}
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		hash, _, _ := strings.Cut(line, ":")
+		hash = strings.ToUpper(hash)
+		if _, err := hex.DecodeString(hash); err != nil || len(hash) != sha1.Size*2 {
+			return nil, fmt.Errorf("baris %d file breached password tidak valid", n)
+		}
+
+		prefix, suffix := hash[:prefixLength], hash[prefixLength:]
+		if list[prefix] == nil {
+			list[prefix] = map[string]struct{}{}
+		}
+		list[prefix][suffix] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("baca file breached password gagal: %w", err)
+	}
+
+	return list, nil
+}
+
+func (l breachedList) contains(password string) bool {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	_, ok := l[hash[:prefixLength]][hash[prefixLength:]]
+	return ok
+}
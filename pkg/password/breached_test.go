@@ -0,0 +1,98 @@
+package password
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeBreachedFile(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "breached.txt")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestLoadBreachedDefault(t *testing.T) {
+	list, err := loadBreached("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		password string
+		want     bool
+	}{
+		{password: "password", want: true},
+		{password: "123456", want: true},
+		{password: "Qwerty123!", want: true},
+		{password: "password-unik-untuk-test", want: false},
+		{password: "Kuda-Lari-42", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := list.contains(tt.password); got != tt.want {
+			t.Errorf("contains(%q) = %v, ingin %v", tt.password, got, tt.want)
+		}
+	}
+}
+
+func TestLoadBreachedFile(t *testing.T) {
+	// SHA-1 "password" huruf kecil dengan count format HIBP, SHA-1 "123456" tanpa count
+	path := writeBreachedFile(t, strings.Join([]string{
+		"# komentar",
+		"",
+		"5baa61e4c9b93f3f0682250b6cf8331b7ee68fd8:3861493",
+		"  7C4A8D09CA3762AF61E59520943DC26494F8941B  ",
+	}, "\n"))
+
+	list, err := loadBreached(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		password string
+		want     bool
+	}{
+		{password: "password", want: true},
+		{password: "123456", want: true},
+		{password: "Qwerty123!", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := list.contains(tt.password); got != tt.want {
+			t.Errorf("contains(%q) = %v, ingin %v", tt.password, got, tt.want)
+		}
+	}
+}
+
+func TestLoadBreachedInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "bukan hex", content: "ZZZA61E4C9B93F3F0682250B6CF8331B7EE68FD8", wantErr: "baris 1"},
+		{name: "panjang hash salah", content: "5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8\n5BAA61E4", wantErr: "baris 2"},
+		{name: "hash SHA-256", content: strings.Repeat("A", 64), wantErr: "baris 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadBreached(writeBreachedFile(t, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, ingin error %s", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := loadBreached(filepath.Join(t.TempDir(), "tidak-ada.txt")); err == nil {
+		t.Error("file tidak ada tidak mengembalikan error")
+	}
+}
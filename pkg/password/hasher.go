@@ -0,0 +1,160 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/irawankilmer/auth-service/internal/configs"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"strings"
+)
+
+var ErrUnknownHash = errors.New("format hash password tidak dikenal")
+
+// Hasher membuat hash dengan algoritma yang dikonfigurasi, tetapi tetap bisa memverifikasi
+// hash lama dari algoritma lain. NeedsRehash menandai hash yang perlu dibuat ulang saat login
+type Hasher interface {
+	Hash(password string) (string, error)
+	Verify(hash, password string) (bool, error)
+	NeedsRehash(hash string) bool
+}
+
+// Argon2Params parameter argon2id, Memory dalam KiB
+type Argon2Params struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+type hasher struct {
+	algorithm  string
+	bcryptCost int
+	argon2     Argon2Params
+}
+
+func NewHasher(cfg configs.PasswordHashConfig) (Hasher, error) {
+	h := &hasher{
+		algorithm:  cfg.Algorithm,
+		bcryptCost: cfg.BcryptCost,
+		argon2: Argon2Params{
+			Memory:      uint32(cfg.Argon2Memory),
+			Iterations:  uint32(cfg.Argon2Iterations),
+			Parallelism: uint8(cfg.Argon2Parallelism),
+			SaltLength:  16,
+			KeyLength:   32,
+		},
+	}
+
+	switch cfg.Algorithm {
+	case "argon2id":
+		if cfg.Argon2Memory < 8*cfg.Argon2Parallelism || cfg.Argon2Iterations < 1 || cfg.Argon2Parallelism < 1 || cfg.Argon2Parallelism > 255 {
+			return nil, fmt.Errorf("parameter argon2id tidak valid: memory=%d iterations=%d parallelism=%d",
+				cfg.Argon2Memory, cfg.Argon2Iterations, cfg.Argon2Parallelism)
+		}
+	case "bcrypt":
+		if cfg.BcryptCost < bcrypt.MinCost || cfg.BcryptCost > bcrypt.MaxCost {
+			return nil, fmt.Errorf("bcrypt cost %d tidak valid", cfg.BcryptCost)
+		}
+	default:
+		return nil, fmt.Errorf("algoritma hash password %q tidak dikenal", cfg.Algorithm)
+	}
+
+	return h, nil
+}
+
+func (h *hasher) Hash(password string) (string, error) {
+	if h.algorithm == "bcrypt" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), h.bcryptCost)
+		return string(hash), err
+	}
+
+	return HashArgon2id(password, h.argon2)
+}
+
+func (h *hasher) Verify(hash, password string) (bool, error) {
+	switch {
+	case strings.HasPrefix(hash, "$argon2id$"):
+		params, salt, key, err := decodeArgon2id(hash)
+		if err != nil {
+			return false, err
+		}
+		other := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, uint32(len(key)))
+		return subtle.ConstantTimeCompare(key, other) == 1, nil
+	case isBcrypt(hash):
+		err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, nil
+		}
+		return err == nil, err
+	default:
+		return false, ErrUnknownHash
+	}
+}
+
+// NeedsRehash true jika hash memakai algoritma lain atau parameter lebih lemah dari konfigurasi saat ini
+func (h *hasher) NeedsRehash(hash string) bool {
+	if h.algorithm == "bcrypt" {
+		cost, err := bcrypt.Cost([]byte(hash))
+		return err != nil || !isBcrypt(hash) || cost < h.bcryptCost
+	}
+
+	params, salt, key, err := decodeArgon2id(hash)
+	if err != nil {
+		return true
+	}
+
+	return params.Memory < h.argon2.Memory || params.Iterations < h.argon2.Iterations ||
+		params.Parallelism < h.argon2.Parallelism || uint32(len(salt)) < h.argon2.SaltLength ||
+		uint32(len(key)) < h.argon2.KeyLength
+}
+
+// HashArgon2id membuat hash argon2id dalam format PHC: $argon2id$v=19$m=65536,t=3,p=4$<salt>$<hash>
+func HashArgon2id(password string, p Argon2Params) (string, error) {
+	salt := make([]byte, p.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("generate salt gagal: %w", err)
+	}
+
+	key := argon2.IDKey([]byte(password), salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, p.Memory, p.Iterations, p.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func decodeArgon2id(hash string) (Argon2Params, []byte, []byte, error) {
+	var p Argon2Params
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return p, nil, nil, ErrUnknownHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return p, nil, nil, fmt.Errorf("versi argon2id tidak didukung: %s", parts[2])
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.Memory, &p.Iterations, &p.Parallelism); err != nil {
+		return p, nil, nil, fmt.Errorf("parameter argon2id tidak valid: %w", err)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return p, nil, nil, fmt.Errorf("salt argon2id tidak valid: %w", err)
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return p, nil, nil, fmt.Errorf("hash argon2id tidak valid: %w", err)
+	}
+	p.SaltLength, p.KeyLength = uint32(len(salt)), uint32(len(key))
+
+	return p, salt, key, nil
+}
+
+func isBcrypt(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
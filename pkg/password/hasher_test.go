@@ -0,0 +1,242 @@
+package password
+
+import (
+	"errors"
+	"fmt"
+	"github.com/irawankilmer/auth-service/internal/configs"
+	"golang.org/x/crypto/bcrypt"
+	"strings"
+	"testing"
+)
+
+// parameter kecil agar test cepat, benchmark memakai parameter sebenarnya
+var (
+	testArgon2 = configs.PasswordHashConfig{Algorithm: "argon2id", Argon2Memory: 64, Argon2Iterations: 1, Argon2Parallelism: 1}
+	testBcrypt = configs.PasswordHashConfig{Algorithm: "bcrypt", BcryptCost: bcrypt.MinCost}
+)
+
+func newTestHasher(t testing.TB, cfg configs.PasswordHashConfig) Hasher {
+	t.Helper()
+
+	h, err := NewHasher(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return h
+}
+
+func TestHasherRoundTrip(t *testing.T) {
+	tests := []struct {
+		name       string
+		cfg        configs.PasswordHashConfig
+		wantPrefix string
+	}{
+		{name: "argon2id", cfg: testArgon2, wantPrefix: "$argon2id$v=19$m=64,t=1,p=1$"},
+		{name: "bcrypt", cfg: testBcrypt, wantPrefix: "$2a$04$"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHasher(t, tt.cfg)
+
+			hash, err := h.Hash("correct horse battery staple")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(hash, tt.wantPrefix) {
+				t.Errorf("hash = %q, ingin awalan %q", hash, tt.wantPrefix)
+			}
+
+			if ok, err := h.Verify(hash, "correct horse battery staple"); err != nil || !ok {
+				t.Errorf("password benar: ok=%v err=%v", ok, err)
+			}
+			if ok, err := h.Verify(hash, "correct horse battery stapler"); err != nil || ok {
+				t.Errorf("password salah: ok=%v err=%v", ok, err)
+			}
+
+			// salt acak, hash password yang sama selalu berbeda
+			again, err := h.Hash("correct horse battery staple")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if again == hash {
+				t.Error("dua hash password yang sama identik")
+			}
+		})
+	}
+}
+
+// TestHasherVerifyOtherAlgorithm hash lama tetap bisa diverifikasi setelah algoritma diganti
+func TestHasherVerifyOtherAlgorithm(t *testing.T) {
+	bcryptHash, err := newTestHasher(t, testBcrypt).Hash("rahasia")
+	if err != nil {
+		t.Fatal(err)
+	}
+	argonHash, err := newTestHasher(t, testArgon2).Hash("rahasia")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		cfg  configs.PasswordHashConfig
+		hash string
+	}{
+		{name: "hash bcrypt di konfigurasi argon2id", cfg: testArgon2, hash: bcryptHash},
+		{name: "hash argon2id di konfigurasi bcrypt", cfg: testBcrypt, hash: argonHash},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if ok, err := newTestHasher(t, tt.cfg).Verify(tt.hash, "rahasia"); err != nil || !ok {
+				t.Errorf("ok=%v err=%v", ok, err)
+			}
+		})
+	}
+}
+
+func TestHasherVerifyInvalidHash(t *testing.T) {
+	h := newTestHasher(t, testArgon2)
+
+	tests := []struct {
+		name    string
+		hash    string
+		wantErr error
+	}{
+		{name: "format tidak dikenal", hash: "plaintext", wantErr: ErrUnknownHash},
+		{name: "md5 crypt", hash: "$1$salt$hash", wantErr: ErrUnknownHash},
+		{name: "argon2id terpotong", hash: "$argon2id$v=19$m=64,t=1,p=1$c2FsdA"},
+		{name: "argon2id versi lain", hash: "$argon2id$v=16$m=64,t=1,p=1$c2FsdHNhbHQ$aGFzaA"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, err := h.Verify(tt.hash, "rahasia")
+			if ok || err == nil {
+				t.Fatalf("ok=%v err=%v, ingin error", ok, err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, ingin %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHasherNeedsRehash(t *testing.T) {
+	hashWith := func(cfg configs.PasswordHashConfig) string {
+		hash, err := newTestHasher(t, cfg).Hash("rahasia")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return hash
+	}
+	argon := func(memory, iterations, parallelism int) configs.PasswordHashConfig {
+		return configs.PasswordHashConfig{Algorithm: "argon2id", Argon2Memory: memory, Argon2Iterations: iterations, Argon2Parallelism: parallelism}
+	}
+
+	tests := []struct {
+		name    string
+		current configs.PasswordHashConfig
+		hash    string
+		want    bool
+	}{
+		{name: "argon2id parameter sama", current: argon(64, 1, 1), hash: hashWith(argon(64, 1, 1))},
+		{name: "argon2id parameter lebih kuat", current: argon(64, 1, 1), hash: hashWith(argon(128, 2, 2))},
+		{name: "memory naik", current: argon(128, 1, 1), hash: hashWith(argon(64, 1, 1)), want: true},
+		{name: "iterasi naik", current: argon(64, 2, 1), hash: hashWith(argon(64, 1, 1)), want: true},
+		{name: "parallelism naik", current: argon(64, 1, 2), hash: hashWith(argon(64, 1, 1)), want: true},
+		{name: "bcrypt ke argon2id", current: testArgon2, hash: hashWith(testBcrypt), want: true},
+		{name: "argon2id ke bcrypt", current: testBcrypt, hash: hashWith(testArgon2), want: true},
+		{name: "bcrypt cost sama", current: testBcrypt, hash: hashWith(testBcrypt)},
+		{name: "bcrypt cost naik", current: configs.PasswordHashConfig{Algorithm: "bcrypt", BcryptCost: 5}, hash: hashWith(testBcrypt), want: true},
+		{name: "hash rusak", current: testArgon2, hash: "plaintext", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHasher(t, tt.current)
+			if got := h.NeedsRehash(tt.hash); got != tt.want {
+				t.Errorf("NeedsRehash() = %v, ingin %v", got, tt.want)
+			}
+
+			// hash baru dari konfigurasi saat ini tidak perlu dibuat ulang lagi
+			if tt.want {
+				fresh, err := h.Hash("rahasia")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if h.NeedsRehash(fresh) {
+					t.Error("hash baru masih ditandai perlu rehash")
+				}
+			}
+		})
+	}
+}
+
+func TestNewHasherInvalidConfig(t *testing.T) {
+	tests := []configs.PasswordHashConfig{
+		{Algorithm: "md5"},
+		{Algorithm: "bcrypt", BcryptCost: 3},
+		{Algorithm: "bcrypt", BcryptCost: 32},
+		{Algorithm: "argon2id", Argon2Memory: 64, Argon2Iterations: 0, Argon2Parallelism: 1},
+		{Algorithm: "argon2id", Argon2Memory: 8, Argon2Iterations: 1, Argon2Parallelism: 2},
+		{Algorithm: "argon2id", Argon2Memory: 64 * 1024, Argon2Iterations: 1, Argon2Parallelism: 256},
+	}
+
+	for _, cfg := range tests {
+		if _, err := NewHasher(cfg); err == nil {
+			t.Errorf("NewHasher(%+v) seharusnya gagal", cfg)
+		}
+	}
+}
+
+// BenchmarkArgon2id dan BenchmarkBcrypt dipakai untuk memilih PASSWORD_HASH_* sesuai CPU server
+// (target umum 100-500ms per login). Memori per hash argon2id sama dengan parameter memory:
+//
+//	go test -run '^$' -bench . -benchtime 5x ./pkg/password
+func BenchmarkArgon2id(b *testing.B) {
+	candidates := []struct {
+		name                            string
+		memory, iterations, parallelism int
+	}{
+		{"OWASP minimum", 19 * 1024, 2, 1},
+		{"default", 64 * 1024, 3, 2},
+		{"RFC 9106", 64 * 1024, 3, 4},
+		{"128MiB", 128 * 1024, 3, 4},
+	}
+
+	for _, c := range candidates {
+		cfg := configs.PasswordHashConfig{Algorithm: "argon2id", Argon2Memory: c.memory, Argon2Iterations: c.iterations, Argon2Parallelism: c.parallelism}
+		benchmarkHasher(b, fmt.Sprintf("%s m=%dMiB t=%d p=%d", c.name, c.memory/1024, c.iterations, c.parallelism), cfg)
+	}
+}
+
+func BenchmarkBcrypt(b *testing.B) {
+	for _, cost := range []int{10, 12} {
+		benchmarkHasher(b, fmt.Sprintf("cost=%d", cost), configs.PasswordHashConfig{Algorithm: "bcrypt", BcryptCost: cost})
+	}
+}
+
+func benchmarkHasher(b *testing.B, name string, cfg configs.PasswordHashConfig) {
+	h := newTestHasher(b, cfg)
+	hash, err := h.Hash("correct horse battery staple")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run(name+"/hash", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := h.Hash("correct horse battery staple"); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run(name+"/verify", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if ok, err := h.Verify(hash, "correct horse battery staple"); err != nil || !ok {
+				b.Fatalf("verifikasi gagal: %v", err)
+			}
+		}
+	})
+}
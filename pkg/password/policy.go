@@ -0,0 +1,124 @@
+package password
+
+import (
+	"fmt"
+	"github.com/irawankilmer/auth-service/internal/configs"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxBytes batas panjang password yang diproses bcrypt, sisanya diabaikan tanpa error
+const MaxBytes = 72
+
+// Policy aturan password untuk register, aktivasi undangan admin, reset dan ganti password
+type Policy interface {
+	// Validate mengembalikan *Violation jika password melanggar aturan.
+	// identities berisi username/email pemilik password yang tidak boleh terkandung di password
+	Validate(password string, identities ...string) error
+}
+
+// Violation semua aturan yang dilanggar, dipakai sebagai pesan error field password
+type Violation struct {
+	Messages []string
+}
+
+func (v *Violation) Error() string {
+	return strings.Join(v.Messages, ", ")
+}
+
+type policy struct {
+	cfg      configs.PasswordPolicyConfig
+	breached breachedList
+}
+
+func NewPolicy(cfg configs.PasswordPolicyConfig) (Policy, error) {
+	p := &policy{cfg: cfg}
+	if cfg.BreachedCheck {
+		list, err := loadBreached(cfg.BreachedFile)
+		if err != nil {
+			return nil, err
+		}
+		p.breached = list
+	}
+
+	return p, nil
+}
+
+func (p *policy) Validate(password string, identities ...string) error {
+	var messages []string
+
+	// panjang
+	if utf8.RuneCountInString(password) < p.cfg.MinLength {
+		messages = append(messages, fmt.Sprintf("password minimal %d karakter", p.cfg.MinLength))
+	}
+	if len(password) > MaxBytes {
+		messages = append(messages, fmt.Sprintf("password maksimal %d byte", MaxBytes))
+	}
+
+	// jenis karakter
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+	for _, rule := range []struct {
+		required bool
+		ok       bool
+		name     string
+	}{
+		{p.cfg.RequireUpper, hasUpper, "huruf besar"},
+		{p.cfg.RequireLower, hasLower, "huruf kecil"},
+		{p.cfg.RequireDigit, hasDigit, "angka"},
+		{p.cfg.RequireSymbol, hasSymbol, "simbol"},
+	} {
+		if rule.required && !rule.ok {
+			messages = append(messages, "password harus mengandung "+rule.name)
+		}
+	}
+
+	// username dan email
+	if containsIdentity(password, identities) {
+		messages = append(messages, "password tidak boleh mengandung username atau email")
+	}
+
+	// password yang pernah bocor
+	if p.breached != nil && p.breached.contains(password) {
+		messages = append(messages, "password terlalu umum atau pernah bocor, gunakan password lain")
+	}
+
+	if len(messages) > 0 {
+		return &Violation{Messages: messages}
+	}
+
+	return nil
+}
+
+// containsIdentity cek username, email dan bagian lokal email (sebelum @) tanpa membedakan huruf besar kecil,
+// nilai kurang dari 3 karakter diabaikan agar tidak menolak terlalu banyak password
+func containsIdentity(password string, identities []string) bool {
+	lower := strings.ToLower(password)
+	for _, identity := range identities {
+		identity = strings.ToLower(strings.TrimSpace(identity))
+		candidates := []string{identity}
+		if local, _, ok := strings.Cut(identity, "@"); ok {
+			candidates = append(candidates, local)
+		}
+
+		for _, c := range candidates {
+			if utf8.RuneCountInString(c) >= 3 && strings.Contains(lower, c) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,99 @@
+package password
+
+import (
+	"errors"
+	"github.com/irawankilmer/auth-service/internal/configs"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var testPolicy = configs.PasswordPolicyConfig{
+	MinLength:     8,
+	RequireUpper:  true,
+	RequireLower:  true,
+	RequireDigit:  true,
+	RequireSymbol: true,
+	BreachedCheck: true,
+}
+
+func TestPolicyValidate(t *testing.T) {
+	p, err := NewPolicy(testPolicy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		password   string
+		identities []string
+		want       []string
+	}{
+		{name: "memenuhi semua aturan", password: "Kuda-Lari-42"},
+		{name: "terlalu pendek", password: "Ab1!", want: []string{"password minimal 8 karakter"}},
+		{name: "panjang dihitung per karakter", password: "Ääää1!ää"},
+		{name: "melebihi batas byte bcrypt", password: "Aa1!" + strings.Repeat("x", MaxBytes), want: []string{"password maksimal 72 byte"}},
+		{name: "tanpa huruf besar", password: "kuda-lari-42", want: []string{"password harus mengandung huruf besar"}},
+		{name: "tanpa huruf kecil", password: "KUDA-LARI-42", want: []string{"password harus mengandung huruf kecil"}},
+		{name: "tanpa angka", password: "Kuda-Lari-Cepat", want: []string{"password harus mengandung angka"}},
+		{name: "tanpa simbol", password: "KudaLari42", want: []string{"password harus mengandung simbol"}},
+		{name: "spasi dihitung simbol", password: "Kuda Lari 42"},
+		{
+			name:     "beberapa aturan sekaligus",
+			password: "kuda",
+			want: []string{
+				"password minimal 8 karakter",
+				"password harus mengandung huruf besar",
+				"password harus mengandung angka",
+				"password harus mengandung simbol",
+			},
+		},
+		{
+			name:       "mengandung username",
+			password:   "Alice-Rahasia-1",
+			identities: []string{"alice", "bob@example.com"},
+			want:       []string{"password tidak boleh mengandung username atau email"},
+		},
+		{
+			name:       "mengandung bagian lokal email",
+			password:   "Rahasia-BOB-1",
+			identities: []string{"bob@example.com"},
+			want:       []string{"password tidak boleh mengandung username atau email"},
+		},
+		{name: "identity pendek diabaikan", password: "Rahasia-ab-1", identities: []string{" ab ", "ab@example.com"}},
+		{name: "pernah bocor", password: "Qwerty123!", want: []string{"password terlalu umum atau pernah bocor, gunakan password lain"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := p.Validate(tt.password, tt.identities...)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("err = %v, ingin lolos", err)
+				}
+				return
+			}
+
+			var violation *Violation
+			if !errors.As(err, &violation) {
+				t.Fatalf("err = %v, ingin *Violation", err)
+			}
+			if !reflect.DeepEqual(violation.Messages, tt.want) {
+				t.Errorf("pesan = %q, ingin %q", violation.Messages, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolicyWithoutBreachedCheck(t *testing.T) {
+	cfg := testPolicy
+	cfg.BreachedCheck, cfg.BreachedFile = false, "file-tidak-ada.txt"
+
+	p, err := NewPolicy(cfg)
+	if err != nil {
+		t.Fatalf("file breached tidak dibaca saat BreachedCheck mati, err = %v", err)
+	}
+	if err := p.Validate("Qwerty123!"); err != nil {
+		t.Errorf("err = %v, ingin lolos tanpa cek breached", err)
+	}
+}
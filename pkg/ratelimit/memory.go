@@ -0,0 +1,99 @@
+package ratelimit
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type memoryEntry struct {
+	bucket    bucketState
+	count     int64
+	expiresAt time.Time
+}
+
+// memoryStore state limit di memori proses, hanya akurat jika aplikasi berjalan satu instance
+type memoryStore struct {
+	mu        sync.Mutex
+	entries   map[string]*memoryEntry
+	lastSweep time.Time
+}
+
+func NewMemoryStore() Store {
+	return &memoryStore{entries: map[string]*memoryEntry{}}
+}
+
+func (s *memoryStore) Allow(_ context.Context, key string, limit Limit, now time.Time) (Result, error) {
+	return s.check(key, limit, now, true), nil
+}
+
+func (s *memoryStore) Peek(_ context.Context, key string, limit Limit, now time.Time) (Result, error) {
+	return s.check(key, limit, now, false), nil
+}
+
+func (s *memoryStore) check(key string, limit Limit, now time.Time, consume bool) Result {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+	ms := now.UnixMilli()
+
+	if limit.Algorithm == SlidingWindow {
+		window := limit.Window.Milliseconds()
+		index := ms / window
+		curKey := key + ":" + strconv.FormatInt(index, 10)
+		var prev, cur int64
+		if e, ok := s.entries[key+":"+strconv.FormatInt(index-1, 10)]; ok {
+			prev = e.count
+		}
+		if e, ok := s.entries[curKey]; ok {
+			cur = e.count
+		}
+
+		result := windowCount(prev, cur, limit, ms-index*window)
+		if result.Allowed && consume {
+			s.entry(curKey, now.Add(2*limit.Window)).count++
+		}
+		return result
+	}
+
+	// peek memakai salinan state agar bucket tidak berubah
+	if !consume {
+		var state bucketState
+		if e, ok := s.entries[key]; ok {
+			state = e.bucket
+		}
+		return takeToken(&state, limit, ms, false)
+	}
+
+	e := s.entry(key, now.Add(limit.Window))
+	result := takeToken(&e.bucket, limit, ms, true)
+	e.expiresAt = now.Add(limit.Window)
+
+	return result
+}
+
+func (s *memoryStore) entry(key string, expiresAt time.Time) *memoryEntry {
+	e, ok := s.entries[key]
+	if !ok {
+		e = &memoryEntry{expiresAt: expiresAt}
+		s.entries[key] = e
+	}
+
+	return e
+}
+
+// sweep menghapus entry kedaluwarsa paling sering sekali per menit
+func (s *memoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+
+	s.lastSweep = now
+	for key, e := range s.entries {
+		if now.After(e.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"github.com/irawankilmer/auth-service/internal/configs"
+	"github.com/redis/go-redis/v9"
+	"math"
+	"time"
+)
+
+const (
+	TokenBucket   = "token_bucket"
+	SlidingWindow = "sliding_window"
+)
+
+// Limit jumlah request per Window. Token bucket mengizinkan burst sampai Limit lalu terisi rata sepanjang Window,
+// sliding window membatasi Limit request di Window terakhir (estimasi dari window sebelumnya dan saat ini)
+type Limit struct {
+	Algorithm string
+	Limit     int
+	Window    time.Duration
+}
+
+// Result hasil pengecekan, dipakai untuk header RateLimit-* dan Retry-After
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// Store penyimpanan state limit, implementasi tersedia in-memory (satu instance) dan Redis (banyak instance).
+// Allow menghitung request jika diizinkan, Peek hanya melihat apakah request berikutnya akan diizinkan
+type Store interface {
+	Allow(ctx context.Context, key string, limit Limit, now time.Time) (Result, error)
+	Peek(ctx context.Context, key string, limit Limit, now time.Time) (Result, error)
+}
+
+func NewStore(cfg configs.RateLimitConfig) (Store, error) {
+	switch cfg.Store {
+	case "memory":
+		return NewMemoryStore(), nil
+	case "redis":
+		client := redis.NewClient(&redis.Options{Addr: cfg.RedisAddr, Password: cfg.RedisPassword, DB: cfg.RedisDB})
+		return NewRedisStore(client, cfg.RedisPrefix), nil
+	default:
+		return nil, fmt.Errorf("store rate limit %q tidak dikenal", cfg.Store)
+	}
+}
+
+// bucketState state token bucket: sisa token dan waktu terakhir diisi (ms)
+type bucketState struct {
+	tokens float64
+	last   int64
+}
+
+// takeToken mengisi ulang bucket lalu mengambil satu token jika consume dan token tersedia
+func takeToken(state *bucketState, limit Limit, now int64, consume bool) Result {
+	capacity := float64(limit.Limit)
+	rate := capacity / float64(limit.Window.Milliseconds())
+	if state.last == 0 {
+		state.tokens = capacity
+	} else if elapsed := now - state.last; elapsed > 0 {
+		state.tokens = math.Min(capacity, state.tokens+float64(elapsed)*rate)
+	}
+	state.last = max(state.last, now)
+
+	result := Result{Limit: limit.Limit}
+	if state.tokens >= 1 {
+		if consume {
+			state.tokens--
+		}
+		result.Allowed = true
+	} else {
+		result.RetryAfter = msDuration((1 - state.tokens) / rate)
+	}
+	result.Remaining = int(state.tokens)
+	result.Reset = msDuration((capacity - state.tokens) / rate)
+
+	return result
+}
+
+// windowCount memperkirakan jumlah request di window berjalan: sisa bobot window sebelumnya ditambah window saat ini.
+// prev dan cur adalah hitungan sebelum request ini, elapsed adalah waktu sejak window saat ini dimulai (ms)
+func windowCount(prev, cur int64, limit Limit, elapsed int64) Result {
+	window := float64(limit.Window.Milliseconds())
+	n := float64(limit.Limit)
+	weight := (window - float64(elapsed)) / window
+	estimate := float64(prev)*weight + float64(cur)
+
+	result := Result{Limit: limit.Limit, Reset: msDuration(window - float64(elapsed))}
+	if estimate+1 <= n {
+		result.Allowed = true
+		result.Remaining = int(n - estimate - 1)
+		return result
+	}
+
+	// tunggu sampai bobot window sebelumnya cukup turun, atau sampai window berikutnya jika window ini sudah penuh
+	if float64(cur)+1 <= n {
+		at := window * (1 - (n-1-float64(cur))/float64(prev))
+		result.RetryAfter = msDuration(at - float64(elapsed))
+	} else {
+		at := window * (1 - (n-1)/float64(cur))
+		result.RetryAfter = msDuration(window - float64(elapsed) + at)
+	}
+
+	return result
+}
+
+func msDuration(ms float64) time.Duration {
+	return time.Duration(math.Ceil(ms)) * time.Millisecond
+}
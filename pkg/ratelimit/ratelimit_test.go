@@ -0,0 +1,287 @@
+package ratelimit
+
+import (
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"testing"
+	"time"
+)
+
+func TestTakeToken(t *testing.T) {
+	limit := Limit{Algorithm: TokenBucket, Limit: 4, Window: 4 * time.Second}
+
+	tests := []struct {
+		name          string
+		state         bucketState
+		now           int64
+		consume       bool
+		wantAllowed   bool
+		wantRemaining int
+		wantReset     time.Duration
+		wantRetry     time.Duration
+		wantTokens    float64
+	}{
+		{
+			name:          "bucket baru penuh",
+			now:           1000,
+			consume:       true,
+			wantAllowed:   true,
+			wantRemaining: 3,
+			wantReset:     time.Second,
+			wantTokens:    3,
+		},
+		{
+			name:          "token terakhir",
+			state:         bucketState{tokens: 1, last: 1000},
+			now:           1000,
+			consume:       true,
+			wantAllowed:   true,
+			wantRemaining: 0,
+			wantReset:     4 * time.Second,
+			wantTokens:    0,
+		},
+		{
+			name:        "bucket kosong ditolak",
+			state:       bucketState{tokens: 0, last: 1000},
+			now:         1000,
+			consume:     true,
+			wantReset:   4 * time.Second,
+			wantRetry:   time.Second,
+			wantTokens:  0,
+			wantAllowed: false,
+		},
+		{
+			name:        "terisi sebagian belum cukup satu token",
+			state:       bucketState{tokens: 0, last: 1000},
+			now:         1500,
+			consume:     true,
+			wantReset:   3500 * time.Millisecond,
+			wantRetry:   500 * time.Millisecond,
+			wantTokens:  0.5,
+			wantAllowed: false,
+		},
+		{
+			name:          "terisi satu token setelah satu detik",
+			state:         bucketState{tokens: 0, last: 1000},
+			now:           2000,
+			consume:       true,
+			wantAllowed:   true,
+			wantRemaining: 0,
+			wantReset:     4 * time.Second,
+			wantTokens:    0,
+		},
+		{
+			name:          "pengisian dibatasi kapasitas",
+			state:         bucketState{tokens: 1, last: 1000},
+			now:           60000,
+			consume:       true,
+			wantAllowed:   true,
+			wantRemaining: 3,
+			wantReset:     time.Second,
+			wantTokens:    3,
+		},
+		{
+			name:          "peek tidak mengambil token",
+			state:         bucketState{tokens: 2, last: 1000},
+			now:           1000,
+			consume:       false,
+			wantAllowed:   true,
+			wantRemaining: 2,
+			wantReset:     2 * time.Second,
+			wantTokens:    2,
+		},
+		{
+			name:        "waktu mundur tidak mengisi bucket",
+			state:       bucketState{tokens: 0, last: 5000},
+			now:         4000,
+			consume:     true,
+			wantReset:   4 * time.Second,
+			wantRetry:   time.Second,
+			wantTokens:  0,
+			wantAllowed: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := tt.state
+			got := takeToken(&state, limit, tt.now, tt.consume)
+
+			if got.Allowed != tt.wantAllowed || got.Remaining != tt.wantRemaining ||
+				got.Reset != tt.wantReset || got.RetryAfter != tt.wantRetry {
+				t.Errorf("takeToken() = %+v, ingin allowed=%v remaining=%d reset=%v retry=%v",
+					got, tt.wantAllowed, tt.wantRemaining, tt.wantReset, tt.wantRetry)
+			}
+			if state.tokens != tt.wantTokens {
+				t.Errorf("sisa token = %v, ingin %v", state.tokens, tt.wantTokens)
+			}
+		})
+	}
+}
+
+func TestWindowCount(t *testing.T) {
+	limit := Limit{Algorithm: SlidingWindow, Limit: 10, Window: 10 * time.Second}
+
+	tests := []struct {
+		name          string
+		prev, cur     int64
+		elapsed       int64
+		wantAllowed   bool
+		wantRemaining int
+		wantReset     time.Duration
+		wantRetry     time.Duration
+	}{
+		{
+			name:          "window kosong",
+			elapsed:       0,
+			wantAllowed:   true,
+			wantRemaining: 9,
+			wantReset:     10 * time.Second,
+		},
+		{
+			name:          "bobot window sebelumnya separuh",
+			prev:          10,
+			cur:           2,
+			elapsed:       5000,
+			wantAllowed:   true,
+			wantRemaining: 2,
+			wantReset:     5 * time.Second,
+		},
+		{
+			name:      "penuh karena window sebelumnya",
+			prev:      10,
+			cur:       5,
+			elapsed:   5000,
+			wantReset: 5 * time.Second,
+			// request diizinkan saat 10*(1-t/10000)+5+1 <= 10, yaitu t = 6000
+			wantRetry: time.Second,
+		},
+		{
+			name:      "window saat ini penuh menunggu window berikutnya",
+			cur:       10,
+			elapsed:   2000,
+			wantReset: 8 * time.Second,
+			// window berikutnya mulai 8 detik lagi, lalu bobot 10 request harus turun ke 9: 1 detik
+			wantRetry: 9 * time.Second,
+		},
+		{
+			name:          "request terakhir yang muat",
+			cur:           9,
+			elapsed:       9999,
+			wantAllowed:   true,
+			wantRemaining: 0,
+			wantReset:     time.Millisecond,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := windowCount(tt.prev, tt.cur, limit, tt.elapsed)
+
+			if got.Allowed != tt.wantAllowed || got.Remaining != tt.wantRemaining ||
+				got.Reset != tt.wantReset || got.RetryAfter != tt.wantRetry {
+				t.Errorf("windowCount() = %+v, ingin allowed=%v remaining=%d reset=%v retry=%v",
+					got, tt.wantAllowed, tt.wantRemaining, tt.wantReset, tt.wantRetry)
+			}
+		})
+	}
+}
+
+func TestMemoryStoreSlidingWindow(t *testing.T) {
+	store := NewMemoryStore()
+	limit := Limit{Algorithm: SlidingWindow, Limit: 3, Window: time.Minute}
+	start := time.UnixMilli(60_000 * 100)
+
+	for i := 0; i < 3; i++ {
+		if r, _ := store.Allow(context.Background(), "ip", limit, start); !r.Allowed {
+			t.Fatalf("request %d ditolak", i+1)
+		}
+	}
+	if r, _ := store.Allow(context.Background(), "ip", limit, start); r.Allowed {
+		t.Fatal("request ke-4 seharusnya ditolak")
+	}
+
+	// key lain tidak terpengaruh
+	if r, _ := store.Allow(context.Background(), "ip-lain", limit, start); !r.Allowed {
+		t.Fatal("key lain seharusnya diizinkan")
+	}
+
+	// dua window kemudian hitungan lama tidak berbobot lagi
+	if r, _ := store.Allow(context.Background(), "ip", limit, start.Add(2*time.Minute)); !r.Allowed {
+		t.Fatal("request setelah dua window seharusnya diizinkan")
+	}
+}
+
+func TestMemoryStorePeek(t *testing.T) {
+	store := NewMemoryStore()
+	limit := Limit{Algorithm: TokenBucket, Limit: 1, Window: time.Minute}
+	now := time.UnixMilli(1_000_000)
+
+	for i := 0; i < 3; i++ {
+		if r, _ := store.Peek(context.Background(), "ip", limit, now); !r.Allowed {
+			t.Fatal("peek tidak boleh mengambil token")
+		}
+	}
+	if r, _ := store.Allow(context.Background(), "ip", limit, now); !r.Allowed {
+		t.Fatal("request pertama seharusnya diizinkan")
+	}
+	if r, _ := store.Peek(context.Background(), "ip", limit, now); r.Allowed {
+		t.Fatal("peek setelah bucket habis seharusnya ditolak")
+	}
+}
+
+// TestStoresAgree menjalankan urutan request yang sama ke store memori dan store Redis (miniredis),
+// hasil keduanya harus sama agar pilihan RATE_LIMIT_STORE tidak mengubah perilaku limit
+func TestStoresAgree(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	// jeda antar request dalam ms, termasuk burst, jeda pendek dan jeda melewati window
+	gaps := []int64{0, 0, 0, 0, 0, 10, 250, 250, 1, 999, 3000, 0, 0, 0, 0, 0, 125, 40, 8000, 0, 1}
+
+	for _, algorithm := range []string{TokenBucket, SlidingWindow} {
+		t.Run(algorithm, func(t *testing.T) {
+			memory := NewMemoryStore()
+			remote := NewRedisStore(client, "test:"+algorithm+":")
+			limit := Limit{Algorithm: algorithm, Limit: 4, Window: 2 * time.Second}
+			now := time.UnixMilli(1_700_000_000_000)
+
+			for i, gap := range gaps {
+				now = now.Add(time.Duration(gap) * time.Millisecond)
+				for _, consume := range []bool{false, true} {
+					check := func(s Store) (Result, error) {
+						if consume {
+							return s.Allow(context.Background(), "key", limit, now)
+						}
+						return s.Peek(context.Background(), "key", limit, now)
+					}
+
+					want, err := check(memory)
+					if err != nil {
+						t.Fatal(err)
+					}
+					got, err := check(remote)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if !sameResult(want, got) {
+						t.Fatalf("request %d (consume=%v): memory %+v, redis %+v", i, consume, want, got)
+					}
+				}
+			}
+		})
+	}
+}
+
+// sameResult toleransi 1ms untuk durasi karena Redis menyimpan sisa token sebagai string
+func sameResult(a, b Result) bool {
+	near := func(x, y time.Duration) bool {
+		d := x - y
+		return d >= -time.Millisecond && d <= time.Millisecond
+	}
+
+	return a.Allowed == b.Allowed && a.Limit == b.Limit && a.Remaining == b.Remaining &&
+		near(a.Reset, b.Reset) && near(a.RetryAfter, b.RetryAfter)
+}
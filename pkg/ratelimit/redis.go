@@ -0,0 +1,133 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"strconv"
+	"time"
+)
+
+// tokenBucketScript: KEYS[1] hash {tokens, last}; ARGV: capacity, window (ms), now (ms), consume (1/0).
+// Mengembalikan {allowed, remaining, reset_ms, retry_after_ms}
+var tokenBucketScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local consume = ARGV[4] == '1'
+local rate = capacity / window
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'last')
+local tokens = tonumber(state[1])
+local last = tonumber(state[2])
+if tokens == nil or last == nil then
+  tokens = capacity
+  last = now
+elseif now > last then
+  tokens = math.min(capacity, tokens + (now - last) * rate)
+  last = now
+end
+
+local allowed = 0
+local retry = 0
+if tokens >= 1 then
+  if consume then
+    tokens = tokens - 1
+  end
+  allowed = 1
+else
+  retry = math.ceil((1 - tokens) / rate)
+end
+
+if consume then
+  redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'last', last)
+  redis.call('PEXPIRE', KEYS[1], window)
+end
+
+return {allowed, math.floor(tokens), math.ceil((capacity - tokens) / rate), retry}
+`)
+
+// slidingWindowScript: KEYS[1] counter window saat ini, KEYS[2] counter window sebelumnya;
+// ARGV: limit, window (ms), waktu sejak window saat ini dimulai (ms), consume (1/0).
+// Mengembalikan {allowed, prev, cur} sebelum request ini
+var slidingWindowScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local elapsed = tonumber(ARGV[3])
+local consume = ARGV[4] == '1'
+local cur = tonumber(redis.call('GET', KEYS[1]) or '0')
+local prev = tonumber(redis.call('GET', KEYS[2]) or '0')
+
+if prev * (window - elapsed) / window + cur + 1 <= limit then
+  if consume then
+    redis.call('INCR', KEYS[1])
+    redis.call('PEXPIRE', KEYS[1], window * 2)
+  end
+  return {1, prev, cur}
+end
+
+return {0, prev, cur}
+`)
+
+// redisStore state limit di Redis (atau server lain yang kompatibel dengan protokol Redis), dibagi antar instance
+type redisStore struct {
+	client redis.Scripter
+	prefix string
+}
+
+func NewRedisStore(client redis.Scripter, prefix string) Store {
+	return &redisStore{client: client, prefix: prefix}
+}
+
+func (s *redisStore) Allow(ctx context.Context, key string, limit Limit, now time.Time) (Result, error) {
+	return s.check(ctx, key, limit, now, 1)
+}
+
+func (s *redisStore) Peek(ctx context.Context, key string, limit Limit, now time.Time) (Result, error) {
+	return s.check(ctx, key, limit, now, 0)
+}
+
+func (s *redisStore) check(ctx context.Context, key string, limit Limit, now time.Time, consume int) (Result, error) {
+	ms := now.UnixMilli()
+	window := limit.Window.Milliseconds()
+
+	if limit.Algorithm == SlidingWindow {
+		return s.slidingWindow(ctx, key, limit, ms, window, consume)
+	}
+
+	values, err := tokenBucketScript.Run(ctx, s.client, []string{s.prefix + key}, limit.Limit, window, ms, consume).Int64Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("rate limit redis gagal: %w", err)
+	}
+	if len(values) != 4 {
+		return Result{}, fmt.Errorf("rate limit redis: respon tidak valid %v", values)
+	}
+
+	return Result{
+		Allowed:    values[0] == 1,
+		Limit:      limit.Limit,
+		Remaining:  int(values[1]),
+		Reset:      time.Duration(values[2]) * time.Millisecond,
+		RetryAfter: time.Duration(values[3]) * time.Millisecond,
+	}, nil
+}
+
+func (s *redisStore) slidingWindow(ctx context.Context, key string, limit Limit, ms, window int64, consume int) (Result, error) {
+	// hash tag {key} agar kedua counter berada di slot yang sama pada Redis Cluster
+	index := ms / window
+	base := s.prefix + "{" + key + "}:"
+	keys := []string{base + strconv.FormatInt(index, 10), base + strconv.FormatInt(index-1, 10)}
+	elapsed := ms - index*window
+
+	values, err := slidingWindowScript.Run(ctx, s.client, keys, limit.Limit, window, elapsed, consume).Int64Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("rate limit redis gagal: %w", err)
+	}
+	if len(values) != 3 {
+		return Result{}, fmt.Errorf("rate limit redis: respon tidak valid %v", values)
+	}
+
+	result := windowCount(values[1], values[2], limit, elapsed)
+	result.Allowed = values[0] == 1
+	return result, nil
+}
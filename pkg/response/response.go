@@ -13,6 +13,8 @@ type Responder interface {
 	Unauthorized(message string)
 	Forbidden(message string)
 	NotFound(message string)
+	TooManyRequests(message string)
+	PreconditionRequired(data interface{}, message string)
 	ServerError(message string)
 }
 
@@ -81,6 +83,23 @@ func (r *responder) NotFound(message string) {
 	})
 }
 
+func (r *responder) TooManyRequests(message string) {
+	r.c.AbortWithStatusJSON(http.StatusTooManyRequests, APIResponse{
+		Code:    http.StatusTooManyRequests,
+		Status:  "error",
+		Message: message,
+	})
+}
+
+func (r *responder) PreconditionRequired(data interface{}, message string) {
+	r.c.AbortWithStatusJSON(http.StatusPreconditionRequired, APIResponse{
+		Code:    http.StatusPreconditionRequired,
+		Status:  "error",
+		Message: message,
+		Data:    data,
+	})
+}
+
 func (r *responder) ServerError(message string) {
 	r.c.AbortWithStatusJSON(http.StatusInternalServerError, APIResponse{
 		Code:    http.StatusInternalServerError,
@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/irawankilmer/auth-service/internal/configs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type localStorage struct {
+	dir       string
+	publicURL string
+}
+
+func NewLocalStorage(cfg configs.StorageConfig) Storage {
+	// tanpa PublicURL, URL file relatif terhadap route yang disajikan aplikasi
+	publicURL := cfg.PublicURL
+	if publicURL == "" {
+		publicURL = cfg.LocalRoute
+	}
+
+	return &localStorage{dir: cfg.LocalDir, publicURL: strings.TrimRight(publicURL, "/")}
+}
+
+func (s *localStorage) Put(_ context.Context, key string, body []byte, _ string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("buat direktori storage gagal: %w", err)
+	}
+
+	// tulis ke file sementara lalu rename, agar file tidak pernah terbaca setengah jadi
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, body, 0o644); err != nil {
+		return fmt.Errorf("tulis file storage gagal: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("simpan file storage gagal: %w", err)
+	}
+
+	return nil
+}
+
+func (s *localStorage) Delete(_ context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("hapus file storage gagal: %w", err)
+	}
+
+	return nil
+}
+
+func (s *localStorage) URL(key string) string {
+	return s.publicURL + "/" + strings.TrimLeft(key, "/")
+}
+
+// path memastikan key tidak keluar dari direktori storage
+func (s *localStorage) path(key string) (string, error) {
+	clean := filepath.Clean("/" + key)
+	if clean == "/" {
+		return "", fmt.Errorf("key storage %q tidak valid", key)
+	}
+
+	return filepath.Join(s.dir, filepath.FromSlash(clean)), nil
+}
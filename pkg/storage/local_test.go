@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"context"
+	"github.com/irawankilmer/auth-service/internal/configs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocalStoragePutDelete(t *testing.T) {
+	dir := t.TempDir()
+	s := NewLocalStorage(configs.StorageConfig{LocalDir: dir, LocalRoute: "/uploads"})
+	ctx := context.Background()
+
+	if err := s.Put(ctx, "avatars/u1/256.jpg", []byte("gambar"), "image/jpeg"); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(filepath.Join(dir, "avatars", "u1", "256.jpg"))
+	if err != nil || string(got) != "gambar" {
+		t.Fatalf("isi file = %q, %v", got, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "avatars", "u1", "256.jpg.tmp")); !os.IsNotExist(err) {
+		t.Errorf("file sementara masih ada: %v", err)
+	}
+
+	// tulis ulang menimpa isi lama
+	if err := s.Put(ctx, "avatars/u1/256.jpg", []byte("baru"), "image/jpeg"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dir, "avatars", "u1", "256.jpg")); string(got) != "baru" {
+		t.Errorf("isi file setelah ditimpa = %q", got)
+	}
+
+	if err := s.Delete(ctx, "avatars/u1/256.jpg"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "avatars", "u1", "256.jpg")); !os.IsNotExist(err) {
+		t.Errorf("file masih ada setelah dihapus: %v", err)
+	}
+
+	// hapus file yang tidak ada tidak dianggap error
+	if err := s.Delete(ctx, "avatars/u1/256.jpg"); err != nil {
+		t.Errorf("hapus file yang tidak ada: %v", err)
+	}
+}
+
+func TestLocalStoragePath(t *testing.T) {
+	dir := t.TempDir()
+	s := NewLocalStorage(configs.StorageConfig{LocalDir: dir, LocalRoute: "/uploads"}).(*localStorage)
+
+	tests := []struct {
+		key     string
+		want    string
+		wantErr bool
+	}{
+		{key: "avatars/a.jpg", want: filepath.Join(dir, "avatars", "a.jpg")},
+		{key: "/avatars/a.jpg", want: filepath.Join(dir, "avatars", "a.jpg")},
+		{key: "../../etc/passwd", want: filepath.Join(dir, "etc", "passwd")},
+		{key: "avatars/../../a.jpg", want: filepath.Join(dir, "a.jpg")},
+		{key: "", wantErr: true},
+		{key: "..", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			got, err := s.path(tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("path(%q) err = %v, ingin error %v", tt.key, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("path(%q) = %q, ingin %q", tt.key, got, tt.want)
+			}
+		})
+	}
+
+	if err := s.Put(context.Background(), "", []byte("x"), ""); err == nil {
+		t.Error("Put dengan key kosong seharusnya gagal")
+	}
+}
+
+func TestLocalStorageURL(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  configs.StorageConfig
+		key  string
+		want string
+	}{
+		{name: "route aplikasi", cfg: configs.StorageConfig{LocalRoute: "/uploads"}, key: "avatars/a.jpg", want: "/uploads/avatars/a.jpg"},
+		{name: "public url", cfg: configs.StorageConfig{LocalRoute: "/uploads", PublicURL: "https://cdn.example.com/"}, key: "/avatars/a.jpg", want: "https://cdn.example.com/avatars/a.jpg"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.LocalDir = t.TempDir()
+			if got := NewLocalStorage(tt.cfg).URL(tt.key); got != tt.want {
+				t.Errorf("URL() = %q, ingin %q", got, tt.want)
+			}
+		})
+	}
+}
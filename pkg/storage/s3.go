@@ -0,0 +1,188 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/irawankilmer/auth-service/internal/configs"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// s3Storage klien S3-compatible (AWS S3, MinIO, R2, dll) dengan signature V4,
+// cukup untuk PUT dan DELETE object sehingga tidak perlu SDK
+type s3Storage struct {
+	endpoint  *url.URL
+	region    string
+	bucket    string
+	accessKey string
+	secretKey string
+	publicURL string
+	pathStyle bool
+	client    *http.Client
+	now       func() time.Time
+}
+
+func NewS3Storage(cfg configs.StorageConfig) (Storage, error) {
+	if cfg.S3Endpoint == "" || cfg.S3Bucket == "" || cfg.S3AccessKey == "" || cfg.S3SecretKey == "" {
+		return nil, errors.New("S3_ENDPOINT, S3_BUCKET, S3_ACCESS_KEY dan S3_SECRET_KEY wajib diisi")
+	}
+	endpoint, err := url.Parse(strings.TrimRight(cfg.S3Endpoint, "/"))
+	if err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, fmt.Errorf("S3_ENDPOINT %q tidak valid", cfg.S3Endpoint)
+	}
+
+	s := &s3Storage{
+		endpoint:  endpoint,
+		region:    cfg.S3Region,
+		bucket:    cfg.S3Bucket,
+		accessKey: cfg.S3AccessKey,
+		secretKey: cfg.S3SecretKey,
+		publicURL: strings.TrimRight(cfg.PublicURL, "/"),
+		pathStyle: cfg.S3UsePathStyle,
+		client:    &http.Client{Timeout: 30 * time.Second},
+		now:       time.Now,
+	}
+	if s.publicURL == "" {
+		s.publicURL = strings.TrimRight(s.objectURL("").String(), "/")
+	}
+
+	return s, nil
+}
+
+func (s *s3Storage) Put(ctx context.Context, key string, body []byte, contentType string) error {
+	return s.do(ctx, http.MethodPut, key, body, contentType)
+}
+
+func (s *s3Storage) Delete(ctx context.Context, key string) error {
+	return s.do(ctx, http.MethodDelete, key, nil, "")
+}
+
+func (s *s3Storage) URL(key string) string {
+	return s.publicURL + "/" + escapePath(strings.TrimLeft(key, "/"))
+}
+
+func (s *s3Storage) do(ctx context.Context, method, key string, body []byte, contentType string) error {
+	target := s.objectURL(key)
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("buat request S3 gagal: %w", err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	s.sign(req, body)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request S3 gagal: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("S3 %s %s gagal: %s %s", method, key, resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	return nil
+}
+
+// objectURL path-style: endpoint/bucket/key, virtual-host: bucket.endpoint/key
+func (s *s3Storage) objectURL(key string) *url.URL {
+	u := *s.endpoint
+	path := "/" + strings.TrimLeft(key, "/")
+	if s.pathStyle {
+		path = "/" + s.bucket + path
+	} else {
+		u.Host = s.bucket + "." + u.Host
+	}
+	u.Path = strings.TrimRight(u.Path, "/") + path
+	u.RawPath = escapePath(u.Path)
+
+	return &u
+}
+
+// sign menambahkan header Authorization AWS Signature Version 4
+func (s *s3Storage) sign(req *http.Request, body []byte) {
+	now := s.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("Host", req.URL.Host)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signedHeaders := []string{"host", "x-amz-content-sha256", "x-amz-date"}
+	if req.Header.Get("Content-Type") != "" {
+		signedHeaders = append([]string{"content-type"}, signedHeaders...)
+	}
+	var canonicalHeaders strings.Builder
+	for _, h := range signedHeaders {
+		value := req.Header.Get(h)
+		if h == "host" {
+			value = req.URL.Host
+		}
+		canonicalHeaders.WriteString(h + ":" + strings.TrimSpace(value) + "\n")
+	}
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		strings.Join(signedHeaders, ";"),
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.secretKey), date)
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.accessKey, scope, strings.Join(signedHeaders, ";"), signature,
+	))
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// escapePath encode tiap segmen path sesuai aturan URI encoding S3, "/" tetap dipertahankan
+func escapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, seg := range segments {
+		var b strings.Builder
+		for _, c := range []byte(seg) {
+			if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') ||
+				c == '-' || c == '_' || c == '.' || c == '~' {
+				b.WriteByte(c)
+				continue
+			}
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+		segments[i] = b.String()
+	}
+
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,240 @@
+package storage
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"github.com/irawankilmer/auth-service/internal/configs"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeS3 pengganti server S3: memeriksa signature V4 dari request yang diterima lalu menyimpan object di memori
+type fakeS3 struct {
+	t         *testing.T
+	secretKey string
+	mu        sync.Mutex
+	objects   map[string]fakeObject
+}
+
+type fakeObject struct {
+	body        string
+	contentType string
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	if err := f.verify(r, body); err != nil {
+		http.Error(w, "<Error><Code>SignatureDoesNotMatch</Code></Error>", http.StatusForbidden)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := r.Host + r.URL.EscapedPath()
+	switch r.Method {
+	case http.MethodPut:
+		f.objects[key] = fakeObject{body: string(body), contentType: r.Header.Get("Content-Type")}
+		w.WriteHeader(http.StatusOK)
+	case http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// verify menghitung ulang signature dari request seperti yang diterima server
+func (f *fakeS3) verify(r *http.Request, body []byte) error {
+	auth := r.Header.Get("Authorization")
+	var credential, signedHeaders, signature string
+	if _, err := fmt.Sscanf(strings.ReplaceAll(auth, ",", ""), "AWS4-HMAC-SHA256 Credential=%s SignedHeaders=%s Signature=%s",
+		&credential, &signedHeaders, &signature); err != nil {
+		return fmt.Errorf("authorization %q: %w", auth, err)
+	}
+
+	parts := strings.SplitN(credential, "/", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("credential %q", credential)
+	}
+	scope := parts[1]
+	scopeParts := strings.Split(scope, "/")
+	if len(scopeParts) != 4 {
+		return fmt.Errorf("scope %q", scope)
+	}
+
+	payloadHash := sha256Hex(body)
+	if r.Header.Get("X-Amz-Content-Sha256") != payloadHash {
+		return fmt.Errorf("payload hash tidak cocok")
+	}
+
+	var canonicalHeaders strings.Builder
+	for _, h := range strings.Split(signedHeaders, ";") {
+		value := r.Header.Get(h)
+		if h == "host" {
+			value = r.Host
+		}
+		canonicalHeaders.WriteString(h + ":" + strings.TrimSpace(value) + "\n")
+	}
+	canonicalRequest := strings.Join([]string{
+		r.Method, r.URL.EscapedPath(), r.URL.RawQuery, canonicalHeaders.String(), signedHeaders, payloadHash,
+	}, "\n")
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256", r.Header.Get("X-Amz-Date"), scope, sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+f.secretKey), scopeParts[0])
+	key = hmacSHA256(key, scopeParts[1])
+	key = hmacSHA256(key, scopeParts[2])
+	key = hmacSHA256(key, scopeParts[3])
+	if want := hex.EncodeToString(hmacSHA256(key, stringToSign)); signature != want {
+		return fmt.Errorf("signature %s, ingin %s", signature, want)
+	}
+
+	return nil
+}
+
+// newTestS3 membuat s3Storage yang semua koneksinya diarahkan ke server palsu, termasuk host virtual-host
+func newTestS3(t *testing.T, server *httptest.Server, cfg configs.StorageConfig) *s3Storage {
+	t.Helper()
+
+	s, err := NewS3Storage(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	storage := s.(*s3Storage)
+	storage.now = func() time.Time { return time.Date(2024, 5, 17, 8, 30, 0, 0, time.UTC) }
+	storage.client = &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, server.Listener.Addr().String())
+		},
+	}}
+
+	return storage
+}
+
+func TestS3Storage(t *testing.T) {
+	tests := []struct {
+		name      string
+		pathStyle bool
+		publicURL string
+		key       string
+		wantKey   string
+		wantURL   string
+	}{
+		{
+			name:      "path style",
+			pathStyle: true,
+			key:       "avatars/u1/256.jpg",
+			wantKey:   "s3.test/media/avatars/u1/256.jpg",
+			wantURL:   "http://s3.test/media/avatars/u1/256.jpg",
+		},
+		{
+			name:    "virtual host",
+			key:     "avatars/u1/256.jpg",
+			wantKey: "media.s3.test/avatars/u1/256.jpg",
+			wantURL: "http://media.s3.test/avatars/u1/256.jpg",
+		},
+		{
+			name:      "key dengan karakter khusus",
+			pathStyle: true,
+			key:       "avatars/nama file+1.jpg",
+			wantKey:   "s3.test/media/avatars/nama%20file%2B1.jpg",
+			wantURL:   "http://s3.test/media/avatars/nama%20file%2B1.jpg",
+		},
+		{
+			name:      "public url CDN",
+			pathStyle: true,
+			publicURL: "https://cdn.example.com/",
+			key:       "/avatars/u1/256.jpg",
+			wantKey:   "s3.test/media/avatars/u1/256.jpg",
+			wantURL:   "https://cdn.example.com/avatars/u1/256.jpg",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeS3{t: t, secretKey: "rahasia", objects: map[string]fakeObject{}}
+			server := httptest.NewServer(fake)
+			defer server.Close()
+
+			s := newTestS3(t, server, configs.StorageConfig{
+				S3Endpoint: "http://s3.test", S3Region: "ap-southeast-1", S3Bucket: "media",
+				S3AccessKey: "akses", S3SecretKey: "rahasia", S3UsePathStyle: tt.pathStyle, PublicURL: tt.publicURL,
+			})
+			ctx := context.Background()
+
+			if got := s.URL(tt.key); got != tt.wantURL {
+				t.Errorf("URL() = %q, ingin %q", got, tt.wantURL)
+			}
+
+			if err := s.Put(ctx, tt.key, []byte("gambar"), "image/jpeg"); err != nil {
+				t.Fatal(err)
+			}
+			if got := fake.objects[tt.wantKey]; got.body != "gambar" || got.contentType != "image/jpeg" {
+				t.Fatalf("object %q = %+v, semua object %v", tt.wantKey, got, fake.objects)
+			}
+
+			if err := s.Delete(ctx, tt.key); err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := fake.objects[tt.wantKey]; ok {
+				t.Errorf("object %q masih ada setelah dihapus", tt.wantKey)
+			}
+		})
+	}
+}
+
+func TestS3StorageError(t *testing.T) {
+	fake := &fakeS3{t: t, secretKey: "rahasia-lain", objects: map[string]fakeObject{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	s := newTestS3(t, server, configs.StorageConfig{
+		S3Endpoint: "http://s3.test", S3Region: "us-east-1", S3Bucket: "media",
+		S3AccessKey: "akses", S3SecretKey: "rahasia", S3UsePathStyle: true,
+	})
+
+	err := s.Put(context.Background(), "a.jpg", []byte("gambar"), "image/jpeg")
+	if err == nil || !strings.Contains(err.Error(), "403") || !strings.Contains(err.Error(), "SignatureDoesNotMatch") {
+		t.Errorf("Put() = %v, ingin error 403 dari server", err)
+	}
+	if len(fake.objects) != 0 {
+		t.Errorf("object tersimpan walau signature salah: %v", fake.objects)
+	}
+}
+
+func TestNewS3StorageConfig(t *testing.T) {
+	valid := configs.StorageConfig{S3Endpoint: "https://s3.test", S3Bucket: "media", S3AccessKey: "a", S3SecretKey: "b"}
+
+	tests := []struct {
+		name    string
+		modify  func(c *configs.StorageConfig)
+		wantErr bool
+	}{
+		{name: "valid", modify: func(c *configs.StorageConfig) {}},
+		{name: "tanpa bucket", modify: func(c *configs.StorageConfig) { c.S3Bucket = "" }, wantErr: true},
+		{name: "tanpa secret", modify: func(c *configs.StorageConfig) { c.S3SecretKey = "" }, wantErr: true},
+		{name: "endpoint tanpa scheme", modify: func(c *configs.StorageConfig) { c.S3Endpoint = "s3.test" }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid
+			tt.modify(&cfg)
+			if _, err := NewS3Storage(cfg); (err != nil) != tt.wantErr {
+				t.Errorf("NewS3Storage() err = %v, ingin error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
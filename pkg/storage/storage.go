@@ -0,0 +1,25 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"github.com/irawankilmer/auth-service/internal/configs"
+)
+
+// Storage penyimpanan file berdasarkan key, implementasi tersedia untuk filesystem lokal dan S3-compatible
+type Storage interface {
+	Put(ctx context.Context, key string, body []byte, contentType string) error
+	Delete(ctx context.Context, key string) error
+	URL(key string) string
+}
+
+func New(cfg configs.StorageConfig) (Storage, error) {
+	switch cfg.Driver {
+	case "local":
+		return NewLocalStorage(cfg), nil
+	case "s3":
+		return NewS3Storage(cfg)
+	default:
+		return nil, fmt.Errorf("driver storage %q tidak dikenal", cfg.Driver)
+	}
+}
@@ -0,0 +1,125 @@
+package tokencache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Cache menyimpan token_version per user di memori agar AuthMiddleware tidak query database di setiap request.
+// Invalidate hanya berlaku di instance ini, instance lain tertinggal paling lama selama TTL.
+//
+// Generation diambil sebelum query database lalu diteruskan ke Set. Set diabaikan jika ada Invalidate
+// di antaranya, agar token version lama yang dibaca sebelum dicabut tidak masuk cache lagi
+type Cache interface {
+	Get(userID string) (string, bool)
+	Generation() uint64
+	Set(userID, tokenVersion string, generation uint64)
+	Invalidate(userIDs ...string)
+}
+
+type entry struct {
+	userID       string
+	tokenVersion string
+	expiresAt    time.Time
+}
+
+// memoryCache LRU dengan TTL, entry paling lama tidak dipakai dibuang jika jumlah entry mencapai maxEntries
+type memoryCache struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	entries    map[string]*list.Element
+	order      *list.List
+	generation uint64
+	now        func() time.Time
+}
+
+// New TTL 0 atau kurang menonaktifkan cache, semua pengecekan langsung ke database
+func New(ttl time.Duration, maxEntries int) Cache {
+	if ttl <= 0 || maxEntries <= 0 {
+		return noCache{}
+	}
+
+	return &memoryCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    map[string]*list.Element{},
+		order:      list.New(),
+		now:        time.Now,
+	}
+}
+
+func (c *memoryCache) Get(userID string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[userID]
+	if !ok {
+		return "", false
+	}
+
+	e := el.Value.(*entry)
+	if !c.now().Before(e.expiresAt) {
+		c.remove(el)
+		return "", false
+	}
+	c.order.MoveToFront(el)
+
+	return e.tokenVersion, true
+}
+
+func (c *memoryCache) Generation() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.generation
+}
+
+func (c *memoryCache) Set(userID, tokenVersion string, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// ada Invalidate setelah token version dibaca, nilainya mungkin sudah dicabut
+	if generation != c.generation {
+		return
+	}
+
+	expiresAt := c.now().Add(c.ttl)
+	if el, ok := c.entries[userID]; ok {
+		e := el.Value.(*entry)
+		e.tokenVersion, e.expiresAt = tokenVersion, expiresAt
+		c.order.MoveToFront(el)
+		return
+	}
+
+	for c.order.Len() >= c.maxEntries {
+		c.remove(c.order.Back())
+	}
+	c.entries[userID] = c.order.PushFront(&entry{userID: userID, tokenVersion: tokenVersion, expiresAt: expiresAt})
+}
+
+func (c *memoryCache) Invalidate(userIDs ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+
+	for _, id := range userIDs {
+		if el, ok := c.entries[id]; ok {
+			c.remove(el)
+		}
+	}
+}
+
+func (c *memoryCache) remove(el *list.Element) {
+	c.order.Remove(el)
+	delete(c.entries, el.Value.(*entry).userID)
+}
+
+type noCache struct{}
+
+func (noCache) Get(string) (string, bool)  { return "", false }
+func (noCache) Generation() uint64         { return 0 }
+func (noCache) Set(string, string, uint64) {}
+func (noCache) Invalidate(...string)       {}
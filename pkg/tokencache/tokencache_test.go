@@ -0,0 +1,148 @@
+package tokencache
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestCache(ttl time.Duration, maxEntries int) (*memoryCache, *time.Time) {
+	now := time.Unix(1_700_000_000, 0)
+	c := New(ttl, maxEntries).(*memoryCache)
+	c.now = func() time.Time { return now }
+
+	return c, &now
+}
+
+func TestCacheGetSet(t *testing.T) {
+	c, now := newTestCache(time.Minute, 10)
+
+	if _, ok := c.Get("u1"); ok {
+		t.Fatal("cache kosong mengembalikan nilai")
+	}
+
+	c.Set("u1", "v1", c.Generation())
+	c.Set("u1", "v2", c.Generation())
+
+	tests := []struct {
+		name    string
+		after   time.Duration
+		want    string
+		wantHit bool
+	}{
+		{name: "nilai terbaru", want: "v2", wantHit: true},
+		{name: "sebelum TTL habis", after: time.Minute - time.Second, want: "v2", wantHit: true},
+		{name: "TTL habis", after: time.Minute},
+	}
+
+	start := *now
+	for _, tt := range tests {
+		*now = start.Add(tt.after)
+		got, ok := c.Get("u1")
+		if ok != tt.wantHit || got != tt.want {
+			t.Errorf("%s: Get() = %q, %v, ingin %q, %v", tt.name, got, ok, tt.want, tt.wantHit)
+		}
+	}
+	if len(c.entries) != 0 || c.order.Len() != 0 {
+		t.Error("entry kedaluwarsa tidak dibuang")
+	}
+}
+
+func TestCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c, _ := newTestCache(time.Minute, 2)
+
+	c.Set("u1", "v1", c.Generation())
+	c.Set("u2", "v2", c.Generation())
+	c.Get("u1")
+	c.Set("u3", "v3", c.Generation())
+
+	tests := []struct {
+		userID  string
+		wantHit bool
+	}{
+		{userID: "u1", wantHit: true},
+		{userID: "u2"},
+		{userID: "u3", wantHit: true},
+	}
+
+	for _, tt := range tests {
+		if _, ok := c.Get(tt.userID); ok != tt.wantHit {
+			t.Errorf("%s: ada di cache = %v, ingin %v", tt.userID, ok, tt.wantHit)
+		}
+	}
+}
+
+func TestCacheInvalidate(t *testing.T) {
+	c, _ := newTestCache(time.Minute, 10)
+
+	c.Set("u1", "v1", c.Generation())
+	c.Set("u2", "v2", c.Generation())
+	c.Invalidate("u1", "tidak-ada")
+
+	if _, ok := c.Get("u1"); ok {
+		t.Error("u1 masih ada setelah Invalidate")
+	}
+	if got, ok := c.Get("u2"); !ok || got != "v2" {
+		t.Errorf("u2 = %q, %v, ingin tetap di cache", got, ok)
+	}
+}
+
+// TestCacheSetAfterInvalidate urutan yang membuat token dicabut tetap diterima:
+// request membaca version lama, version dicabut dan Invalidate, lalu request menyimpan version lama
+func TestCacheSetAfterInvalidate(t *testing.T) {
+	c, _ := newTestCache(time.Minute, 10)
+
+	// request A mulai membaca database
+	generation := c.Generation()
+
+	// ganti password di request lain
+	c.Invalidate("u1")
+
+	// request A selesai membaca version lama
+	c.Set("u1", "lama", generation)
+	if got, ok := c.Get("u1"); ok {
+		t.Fatalf("version lama masuk cache: %q", got)
+	}
+
+	// request berikutnya membaca version baru dan boleh menyimpannya
+	c.Set("u1", "baru", c.Generation())
+	if got, ok := c.Get("u1"); !ok || got != "baru" {
+		t.Errorf("Get() = %q, %v, ingin version baru", got, ok)
+	}
+}
+
+func TestCacheConcurrent(t *testing.T) {
+	c := New(time.Minute, 16)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				id := strconv.Itoa(j % 32)
+				c.Set(id, strconv.Itoa(i), c.Generation())
+				c.Get(id)
+				if j%100 == 0 {
+					c.Invalidate(id)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	mc := c.(*memoryCache)
+	if len(mc.entries) > 16 || len(mc.entries) != mc.order.Len() {
+		t.Errorf("entry = %d, order = %d, ingin maksimal 16 dan sama", len(mc.entries), mc.order.Len())
+	}
+}
+
+func TestNoCache(t *testing.T) {
+	for _, c := range []Cache{New(0, 10), New(time.Minute, 0)} {
+		c.Set("u1", "v1", c.Generation())
+		if _, ok := c.Get("u1"); ok {
+			t.Errorf("%T menyimpan nilai padahal cache nonaktif", c)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"github.com/gogaruda/apperror"
+)
+
+// Encrypt mengenkripsi data sensitif (misal secret MFA) dengan AES-256-GCM
+func (u *utility) Encrypt(plaintext string) (string, error) {
+	gcm, err := u.aead()
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", apperror.New(apperror.CodeInternalError, "gagal membuat nonce enkripsi", err)
+	}
+
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (u *utility) Decrypt(ciphertext string) (string, error) {
+	gcm, err := u.aead()
+	if err != nil {
+		return "", err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return "", apperror.New(apperror.CodeDecodingError, "data terenkripsi tidak valid", err)
+	}
+	if len(data) < gcm.NonceSize() {
+		return "", apperror.New(apperror.CodeDecodingError, "data terenkripsi tidak valid", errors.New("ciphertext terlalu pendek"))
+	}
+
+	plain, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", apperror.New(apperror.CodeInternalError, "dekripsi data gagal", err)
+	}
+
+	return string(plain), nil
+}
+
+func (u *utility) aead() (cipher.AEAD, error) {
+	key := sha256.Sum256([]byte(u.config.MFA.EncryptionKey))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, apperror.New(apperror.CodeInternalError, "inisialisasi enkripsi gagal", err)
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, apperror.New(apperror.CodeInternalError, "inisialisasi enkripsi gagal", err)
+	}
+
+	return gcm, nil
+}
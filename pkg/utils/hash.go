@@ -1,15 +1,41 @@
 package utils
 
 import (
-	"golang.org/x/crypto/bcrypt"
+	"context"
+	"github.com/gogaruda/apperror"
 )
 
-func (u *utility) HashGenerate(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	return string(bytes), err
+func (u *utility) HashGenerate(ctx context.Context, password string) (string, error) {
+	var hash string
+	var err error
+	if poolErr := u.hashPool.do(ctx, func() { hash, err = u.hasher.Hash(password) }); poolErr != nil {
+		return "", poolErr
+	}
+	if err != nil {
+		return "", apperror.New(apperror.CodeInternalError, "generate hash password gagal", err)
+	}
+
+	return hash, nil
+}
+
+// HashCompare mencocokkan password dengan hash, error hanya jika antrean hash penuh atau request dibatalkan
+func (u *utility) HashCompare(ctx context.Context, hash, password string) (bool, error) {
+	var ok bool
+	var err error
+	if poolErr := u.hashPool.do(ctx, func() { ok, err = u.hasher.Verify(hash, password) }); poolErr != nil {
+		return false, poolErr
+	}
+
+	return err == nil && ok, nil
+}
+
+// HashDummyCompare menjalankan verifikasi dengan biaya yang sama seperti HashCompare untuk user yang tidak ada
+// atau belum punya password, agar waktu respon tidak membedakan akun yang terdaftar
+func (u *utility) HashDummyCompare(ctx context.Context, password string) error {
+	_, err := u.HashCompare(ctx, u.dummyHash, password)
+	return err
 }
 
-func (u *utility) HashCompare(hash, password string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
-	return err == nil
+func (u *utility) HashNeedsRehash(hash string) bool {
+	return u.hasher.NeedsRehash(hash)
 }
@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"context"
+	"github.com/gogaruda/apperror"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// hashPool membatasi jumlah hash password yang berjalan bersamaan. Permintaan di atas Workers menunggu di antrean,
+// antrean penuh atau terlalu lama ditolak cepat agar lonjakan login tidak menghabiskan CPU untuk endpoint lain
+type hashPool struct {
+	slots        chan struct{}
+	waiting      atomic.Int64
+	maxQueue     int64
+	queueTimeout time.Duration
+}
+
+func newHashPool(workers, queue int, queueTimeout time.Duration) *hashPool {
+	return &hashPool{slots: make(chan struct{}, max(workers, 1)), maxQueue: int64(queue), queueTimeout: queueTimeout}
+}
+
+func (p *hashPool) do(ctx context.Context, fn func()) error {
+	select {
+	case p.slots <- struct{}{}:
+	default:
+		if err := p.wait(ctx); err != nil {
+			return err
+		}
+	}
+	defer func() { <-p.slots }()
+
+	fn()
+	return nil
+}
+
+func (p *hashPool) wait(ctx context.Context) error {
+	busy := apperror.New("[SERVER_BUSY]", "server sedang sibuk, silakan coba lagi", nil, http.StatusServiceUnavailable)
+
+	// antrean penuh
+	if p.waiting.Add(1) > p.maxQueue {
+		p.waiting.Add(-1)
+		return busy
+	}
+	defer p.waiting.Add(-1)
+
+	timer := time.NewTimer(p.queueTimeout)
+	defer timer.Stop()
+
+	select {
+	case p.slots <- struct{}{}:
+		return nil
+	case <-timer.C:
+		return busy
+	case <-ctx.Done():
+		return apperror.New(apperror.CodeTimeout, "permintaan dibatalkan", ctx.Err())
+	}
+}
@@ -0,0 +1,129 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"github.com/gogaruda/apperror"
+	"github.com/irawankilmer/auth-service/internal/configs"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// holdSlots mengisi semua worker pool sampai release dipanggil
+func holdSlots(t *testing.T, p *hashPool, workers int) (release func()) {
+	t.Helper()
+
+	started, done := make(chan struct{}), make(chan struct{})
+	for i := 0; i < workers; i++ {
+		go func() {
+			_ = p.do(context.Background(), func() {
+				started <- struct{}{}
+				<-done
+			})
+		}()
+		<-started
+	}
+
+	return func() { close(done) }
+}
+
+func TestHashPoolBusy(t *testing.T) {
+	tests := []struct {
+		name         string
+		queue        int
+		queueTimeout time.Duration
+		wantMin      time.Duration
+	}{
+		{name: "antrean penuh", queue: 0, queueTimeout: time.Minute},
+		{name: "menunggu terlalu lama", queue: 1, queueTimeout: 50 * time.Millisecond, wantMin: 50 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newHashPool(1, tt.queue, tt.queueTimeout)
+			release := holdSlots(t, p, 1)
+			defer release()
+
+			start := time.Now()
+			called := false
+			err := p.do(context.Background(), func() { called = true })
+			if !apperror.Is(err, "[SERVER_BUSY]") {
+				t.Fatalf("err = %v, ingin [SERVER_BUSY]", err)
+			}
+			if called {
+				t.Error("hash dijalankan padahal ditolak")
+			}
+			if elapsed := time.Since(start); elapsed < tt.wantMin || elapsed > tt.wantMin+time.Second {
+				t.Errorf("ditolak setelah %v, ingin sekitar %v", elapsed, tt.wantMin)
+			}
+			if p.waiting.Load() != 0 {
+				t.Errorf("antrean = %d, ingin 0 setelah ditolak", p.waiting.Load())
+			}
+		})
+	}
+}
+
+func TestHashPoolCanceled(t *testing.T) {
+	p := newHashPool(1, 1, time.Minute)
+	release := holdSlots(t, p, 1)
+	defer release()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := p.do(ctx, func() {}); !apperror.Is(err, apperror.CodeTimeout) {
+		t.Errorf("err = %v, ingin %s", err, apperror.CodeTimeout)
+	}
+}
+
+// TestHashPoolConcurrency hash yang berjalan bersamaan tidak pernah melebihi jumlah worker
+func TestHashPoolConcurrency(t *testing.T) {
+	const workers, requests = 3, 20
+	p := newHashPool(workers, requests, time.Minute)
+
+	var running, peak atomic.Int64
+	var wg sync.WaitGroup
+	errs := make(chan error, requests)
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- p.do(context.Background(), func() {
+				n := running.Add(1)
+				for {
+					old := peak.Load()
+					if n <= old || peak.CompareAndSwap(old, n) {
+						break
+					}
+				}
+				time.Sleep(5 * time.Millisecond)
+				running.Add(-1)
+			})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("err = %v, ingin semua request dilayani", err)
+		}
+	}
+	if got := peak.Load(); got > workers {
+		t.Errorf("hash bersamaan = %d, ingin maksimal %d", got, workers)
+	}
+}
+
+type failingHasher struct{}
+
+func (failingHasher) Hash(string) (string, error)         { return "", errors.New("hasher rusak") }
+func (failingHasher) Verify(string, string) (bool, error) { return false, errors.New("hasher rusak") }
+func (failingHasher) NeedsRehash(string) bool             { return false }
+
+func TestNewUtilityDummyHashError(t *testing.T) {
+	if _, err := NewUtility(&configs.AppConfig{}, failingHasher{}); !apperror.Is(err, apperror.CodeInternalError) {
+		t.Errorf("err = %v, ingin %s", err, apperror.CodeInternalError)
+	}
+}
@@ -1,8 +1,10 @@
 package utils
 
 import (
+	"github.com/gogaruda/apperror"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/irawankilmer/auth-service/internal/configs"
+	"net/http"
 	"time"
 )
 
@@ -22,3 +24,104 @@ func (u *utility) JWTGenerate(userID, tokenVersion string, emailVerified bool, r
 
 	return token.SignedString([]byte(secret))
 }
+
+const mfaChallengePurpose = "mfa_challenge"
+
+// MFAChallengeGenerate membuat token singkat setelah password valid, ditukar dengan kode MFA.
+// Pilihan remember me ikut disimpan agar tidak perlu dikirim ulang saat verifikasi MFA
+func (u *utility) MFAChallengeGenerate(userID string, rememberMe bool) (string, error) {
+	now := u.Now()
+	claims := jwt.MapClaims{
+		"user_id":     userID,
+		"remember_me": rememberMe,
+		"purpose":     mfaChallengePurpose,
+		"exp":         now.Add(u.config.MFA.ChallengeTTL).Unix(),
+		"iat":         now.Unix(),
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	signed, err := token.SignedString([]byte(u.config.JWT.Secret))
+	if err != nil {
+		return "", apperror.New(apperror.CodeInternalError, "generate token MFA gagal", err)
+	}
+
+	return signed, nil
+}
+
+func (u *utility) MFAChallengeParse(tokenStr string) (string, bool, error) {
+	invalid := apperror.New("[MFA_TOKEN_INVALID]", "token MFA tidak valid atau sudah kadaluwarsa", nil, http.StatusUnauthorized)
+
+	token, err := jwt.Parse(tokenStr, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrSignatureInvalid
+		}
+		return []byte(u.config.JWT.Secret), nil
+	}, jwt.WithTimeFunc(u.Now), jwt.WithExpirationRequired())
+	if err != nil || !token.Valid {
+		return "", false, invalid
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return "", false, invalid
+	}
+
+	purpose, _ := claims["purpose"].(string)
+	userID, _ := claims["user_id"].(string)
+	if purpose != mfaChallengePurpose || userID == "" {
+		return "", false, invalid
+	}
+	rememberMe, _ := claims["remember_me"].(bool)
+
+	return userID, rememberMe, nil
+}
+
+const sessionRevokePurpose = "session_revoke"
+
+// SessionRevokeGenerate membuat token link "bukan saya" di email login baru, dipakai untuk revoke session tanpa login
+func (u *utility) SessionRevokeGenerate(userID, sessionID string) (string, error) {
+	now := u.Now()
+	claims := jwt.MapClaims{
+		"user_id":    userID,
+		"session_id": sessionID,
+		"purpose":    sessionRevokePurpose,
+		"exp":        now.Add(u.config.LoginNotify.RevokeLinkTTL).Unix(),
+		"iat":        now.Unix(),
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	signed, err := token.SignedString([]byte(u.config.JWT.Secret))
+	if err != nil {
+		return "", apperror.New(apperror.CodeInternalError, "generate token revoke session gagal", err)
+	}
+
+	return signed, nil
+}
+
+func (u *utility) SessionRevokeParse(tokenStr string) (string, string, error) {
+	invalid := apperror.New("[REVOKE_TOKEN_INVALID]", "link revoke session tidak valid atau sudah kadaluwarsa", nil, http.StatusUnauthorized)
+
+	token, err := jwt.Parse(tokenStr, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrSignatureInvalid
+		}
+		return []byte(u.config.JWT.Secret), nil
+	}, jwt.WithTimeFunc(u.Now), jwt.WithExpirationRequired())
+	if err != nil || !token.Valid {
+		return "", "", invalid
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return "", "", invalid
+	}
+
+	purpose, _ := claims["purpose"].(string)
+	userID, _ := claims["user_id"].(string)
+	sessionID, _ := claims["session_id"].(string)
+	if purpose != sessionRevokePurpose || userID == "" || sessionID == "" {
+		return "", "", invalid
+	}
+
+	return userID, sessionID, nil
+}
@@ -3,8 +3,10 @@ package utils
 import (
 	"crypto/rand"
 	"crypto/sha256"
+	"encoding/base32"
 	"encoding/base64"
 	"github.com/gogaruda/apperror"
+	"strings"
 )
 
 func (u *utility) HashToken(token string) string {
@@ -20,3 +22,16 @@ func (u *utility) RefreshTokenGenerate() (string, error) {
 
 	return base64.URLEncoding.EncodeToString(bytes), nil
 }
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// RecoveryCodeGenerate membuat kode pemulihan MFA sekali pakai, format: xxxxx-xxxxx
+func (u *utility) RecoveryCodeGenerate() (string, error) {
+	bytes := make([]byte, 7)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", apperror.New(apperror.CodeInternalError, "gagal membuat recovery code", err)
+	}
+
+	code := strings.ToLower(recoveryCodeEncoding.EncodeToString(bytes))[:10]
+	return code[:5] + "-" + code[5:], nil
+}
@@ -1,21 +1,75 @@
 package utils
 
-import "github.com/irawankilmer/auth-service/internal/configs"
+import (
+	"context"
+	"github.com/gogaruda/apperror"
+	"github.com/irawankilmer/auth-service/internal/configs"
+	"github.com/irawankilmer/auth-service/pkg/password"
+	"time"
+)
 
 type Utility interface {
 	ULIDGenerate() string
-	HashGenerate(password string) (string, error)
-	HashCompare(hash, password string) bool
+	HashGenerate(ctx context.Context, password string) (string, error)
+	HashCompare(ctx context.Context, hash, password string) (bool, error)
+	HashDummyCompare(ctx context.Context, password string) error
+	HashNeedsRehash(hash string) bool
 	UUIDGenerate() (string, error)
 	JWTGenerate(userID, tokenVersion string, isVerified bool, roles []string, cfg *configs.AppConfig) (string, error)
 	RefreshTokenGenerate() (string, error)
+	RecoveryCodeGenerate() (string, error)
 	HashToken(token string) string
+	Now() time.Time
+	TOTPSecretGenerate() (string, error)
+	TOTPCode(secret string, t time.Time) (string, error)
+	TOTPValidate(secret, code string, t time.Time) (int64, bool)
+	Encrypt(plaintext string) (string, error)
+	Decrypt(ciphertext string) (string, error)
+	MFAChallengeGenerate(userID string, rememberMe bool) (string, error)
+	MFAChallengeParse(token string) (string, bool, error)
+	SessionRevokeGenerate(userID, sessionID string) (string, error)
+	SessionRevokeParse(token string) (string, string, error)
+}
+
+// Clock sumber waktu utility, bisa diganti fake clock saat testing
+type Clock interface {
+	Now() time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
 }
 
 type utility struct {
-	config *configs.AppConfig
+	config    *configs.AppConfig
+	clock     Clock
+	hasher    password.Hasher
+	hashPool  *hashPool
+	dummyHash string
+}
+
+func NewUtility(cfg *configs.AppConfig, hasher password.Hasher) (Utility, error) {
+	return NewUtilityWithClock(cfg, hasher, realClock{})
+}
+
+// NewUtilityWithClock hash dummy dibuat saat konstruksi, hasher yang gagal membuat hash langsung terlihat saat start
+func NewUtilityWithClock(cfg *configs.AppConfig, hasher password.Hasher, clock Clock) (Utility, error) {
+	dummyHash, err := hasher.Hash("dummy-password-untuk-perbandingan-waktu")
+	if err != nil {
+		return nil, apperror.New(apperror.CodeInternalError, "generate hash dummy gagal", err)
+	}
+
+	return &utility{
+		config:    cfg,
+		clock:     clock,
+		hasher:    hasher,
+		hashPool:  newHashPool(cfg.Hash.Workers, cfg.Hash.QueueSize, cfg.Hash.QueueTimeout),
+		dummyHash: dummyHash,
+	}, nil
 }
 
-func NewUtility(cfg *configs.AppConfig) Utility {
-	return &utility{config: cfg}
+func (u *utility) Now() time.Time {
+	return u.clock.Now()
 }